@@ -170,7 +170,19 @@ func (h *BaseHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, refreshToken, refreshTokenCookie := auth.LoginUser(account, q, r.Context())
+	if required, err := requireTwoFactor(w, r.Context(), q, account.ID); err != nil {
+		log.Printf("could not check two factor authentication at acceptInvite.go: %v", err)
+		util.Response(w, "something went wrong", http.StatusInternalServerError)
+		return
+	} else if required {
+		return
+	}
+
+	accessToken, refreshToken, refreshTokenCookie, err := auth.LoginUser(r, q, account)
+	if err != nil {
+		ErrorStartingSession(w, err)
+		return
+	}
 
 	account, err = q.GetAccount(r.Context(), account.ID)
 	if err != nil {
@@ -1,20 +1,19 @@
 package api
 
 import (
-	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
 	"sync"
-	"time"
 
 	"github.com/go-ozzo/ozzo-validation/is"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/jackc/pgconn"
 	"github.com/kwandapchumba/go-bookmark-manager/auth"
 	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/oauth"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
@@ -25,7 +24,7 @@ type signUp struct {
 }
 
 type session struct {
-	Account      sqlc.Account
+	Account      accountResponse
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	// Expiry       time.Time `json:"expiry"`
@@ -33,7 +32,7 @@ type session struct {
 
 func newsession(account sqlc.Account, accessToken, refreshToken string) session {
 	return session{
-		Account:      account,
+		Account:      newAccountResponse(account),
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		// Expiry:       expiry,
@@ -140,50 +139,13 @@ func (h *BaseHandler) NewAccount(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	config, err := util.LoadConfig(".")
+	accessToken, refreshToken, refreshTokenCookie, err := auth.LoginUser(r, q, account)
 	if err != nil {
-		log.Printf("failed to load config file with err: %v", err)
-		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
-		return
-	}
-
-	accessToken, accessTokenPayload, err := auth.CreateToken(account.ID, time.Now().UTC(), config.Access_Token_Duration)
-	if err != nil {
-		log.Printf("failed to create access token with err: %v", err)
-		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
+		ErrorStartingSession(w, err)
 		return
 	}
 
-	refreshToken, refreshTokenPayload, err := auth.CreateToken(account.ID, accessTokenPayload.IssuedAt, config.Refresh_Token_Duration)
-	if err != nil {
-		log.Printf("failed to create refresh token with err: %v", err)
-		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
-		return
-	}
-
-	createAccountSessionParams := sqlc.CreateAccountSessionParams{
-		RefreshTokenID: refreshTokenPayload.ID,
-		AccountID:      account.ID,
-		IssuedAt:       refreshTokenPayload.IssuedAt,
-		Expiry:         refreshTokenPayload.Expiry,
-		UserAgent:      "",
-		ClientIp:       "",
-	}
-
-	_, err = q.CreateAccountSession(r.Context(), createAccountSessionParams)
-	if err != nil {
-		var pgErr *pgconn.PgError
-
-		if errors.As(err, &pgErr) {
-			log.Printf("failed co create session with err: %s", pgErr.Message)
-			util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
-			return
-		} else {
-			log.Printf("failed to create session with error: %s", err.Error())
-			util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
-			return
-		}
-	}
+	http.SetCookie(w, &refreshTokenCookie)
 
 	account, err = q.GetAccount(r.Context(), account.ID)
 	if err != nil {
@@ -212,16 +174,12 @@ func (h *BaseHandler) NewAccount(w http.ResponseWriter, r *http.Request) {
 // sign up with google
 
 type continueWithGoogle struct {
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Picture string `json:"picture"`
+	IDToken string `json:"id_token"`
 }
 
 func (c continueWithGoogle) Validate(errChan chan error) error {
 	err := validation.ValidateStruct(&c,
-		validation.Field(&c.Name, validation.Required.Error("name required")),
-		validation.Field(&c.Email, validation.Required.Error("email required"), is.Email),
-		validation.Field(&c.Picture, validation.Required.Error("profile picture required"), is.URL),
+		validation.Field(&c.IDToken, validation.Required.Error("id token required")),
 	)
 
 	errChan <- err
@@ -229,6 +187,8 @@ func (c continueWithGoogle) Validate(errChan chan error) error {
 	return err
 }
 
+// ContinueWithGoogle signs in, or signs up, whoever google vouches for in the id token. It is the google provider's
+// sign in for frontends that use google's own sign in button instead of the redirect flow.
 func (h *BaseHandler) ContinueWithGoogle(w http.ResponseWriter, r *http.Request) {
 	rBody := json.NewDecoder(r.Body)
 
@@ -255,121 +215,58 @@ func (h *BaseHandler) ContinueWithGoogle(w http.ResponseWriter, r *http.Request)
 
 	wg.Wait()
 
-	q := sqlc.New(h.db)
-
-	email := req.Email
-
-	config, err := util.LoadConfig(".")
-	if err != nil {
-		log.Println(err)
+	if err := <-errChan; err != nil {
+		ErrorInvalidRequest(w, err)
 		return
 	}
 
-	existingAccount, err := q.GetAccountByEmail(r.Context(), email)
+	claims, err := auth.VerifyGoogleIDToken(r.Context(), req.IDToken)
 	if err != nil {
-		var pgErr *pgconn.PgError
-
-		switch {
-		case errors.As(err, &pgErr):
-			ErrorInternalServerError(w, pgErr)
-			return
-		case errors.Is(err, sql.ErrNoRows):
-			createAccountParams := sqlc.NewAccountParams{
-				Fullname: req.Name,
-				Email:    req.Email,
-				// Picture:  req.Picture,
-			}
-
-			createAccount(createAccountParams, q, w, h, config, r.Context())
-
-			return
-		default:
-			ErrorInternalServerError(w, err)
-			return
-		}
+		log.Printf("could not verify google id token at account.go: %v", err)
+		util.Response(w, "invalid google id token", http.StatusUnauthorized)
+		return
 	}
 
-	if existingAccount != (sqlc.Account{}) {
-		loginUser(existingAccount, w, h, config, r.Context())
-	}
-}
-
-func createAccount(arg sqlc.NewAccountParams, q *sqlc.Queries, w http.ResponseWriter, h *BaseHandler, config util.Config, ctx context.Context) {
-	account, err := q.NewAccount(ctx, arg)
+	account, err := h.accountForIdentity(r.Context(), &oauth.Identity{
+		Provider:      "google",
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	})
 	if err != nil {
-		var pgErr *pgconn.PgError
-
-		switch {
-		case errors.As(err, &pgErr):
-			ErrorInternalServerError(w, pgErr)
-			return
-		default:
-			ErrorInternalServerError(w, err)
+		if errors.Is(err, errIdentityTaken) {
+			util.Response(w, "account is linked to another google account", http.StatusConflict)
 			return
 		}
-	}
 
-	if err := q.UpdateAccountEmailVerificationStatus(ctx, arg.Email); err != nil {
-		log.Println(err)
-		util.Response(w, "something went wrong", http.StatusInternalServerError)
+		ErrorInternalServerError(w, err)
 		return
 	}
 
-	loginUser(account, w, h, config, ctx)
-}
-
-func loginUser(account sqlc.Account, w http.ResponseWriter, h *BaseHandler, config util.Config, ctx context.Context) {
-	accessToken, accessTokenPayload, err := auth.CreateToken(account.ID, time.Now().UTC(), config.Access_Token_Duration)
-	if err != nil {
+	if required, err := requireTwoFactor(w, r.Context(), sqlc.New(h.db), account.ID); err != nil {
 		ErrorInternalServerError(w, err)
 		return
+	} else if required {
+		return
 	}
 
-	q := sqlc.New(h.db)
-
-	// updateLastLoginParams := sqlc.UpdateLastLoginParams{
-	// 	LastLogin: accessTokenPayload.IssuedAt,
-	// 	ID:        accessTokenPayload.AccountID,
-	// }
+	loginUser(account, w, h, r)
+}
 
-	// if _, err := q.UpdateLastLogin(r.Context(), updateLastLoginParams); err != nil {
-	// 	ErrorInternalServerError(w, err)
-	// 	return
-	// }
+func loginUser(account sqlc.Account, w http.ResponseWriter, h *BaseHandler, r *http.Request) {
+	q := sqlc.New(h.db)
 
-	refreshToken, refreshTokenPayload, err := auth.CreateToken(account.ID, accessTokenPayload.IssuedAt, config.Refresh_Token_Duration)
+	accessToken, refreshToken, refreshTokenCookie, err := auth.LoginUser(r, q, account)
 	if err != nil {
-		ErrorInternalServerError(w, err)
-		return
-	}
-
-	createAccountSessionParams := sqlc.CreateAccountSessionParams{
-		RefreshTokenID: refreshTokenPayload.ID,
-		AccountID:      account.ID,
-		IssuedAt:       refreshTokenPayload.IssuedAt,
-		Expiry:         refreshTokenPayload.Expiry,
-		UserAgent:      "",
-		ClientIp:       "",
-	}
-
-	if _, err := q.CreateAccountSession(ctx, createAccountSessionParams); err != nil {
-		ErrorInternalServerError(w, err)
+		ErrorStartingSession(w, err)
 		return
 	}
 
-	refreshTokenCookie := http.Cookie{
-		Name:     "refreshTokenCookie",
-		Value:    refreshToken,
-		Path:     "/",
-		Expires:  refreshTokenPayload.Expiry,
-		Secure:   true,
-		SameSite: http.SameSiteStrictMode,
-		HttpOnly: true,
-	}
-
 	http.SetCookie(w, &refreshTokenCookie)
 
-	newAccount, err := q.GetAccount(ctx, account.ID)
+	newAccount, err := q.GetAccount(r.Context(), account.ID)
 	if err != nil {
 		ErrorInternalServerError(w, err)
 		return
@@ -379,21 +276,3 @@ func loginUser(account sqlc.Account, w http.ResponseWriter, h *BaseHandler, conf
 
 	util.JsonResponse(w, res)
 }
-
-func (h *BaseHandler) GetAllAccounts(w http.ResponseWriter, r *http.Request) {
-	q := sqlc.New(h.db)
-
-	accounts, err := q.GetAllAccounts(r.Context())
-	if err != nil {
-		log.Println(err)
-		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
-		return
-	}
-
-	if len(accounts) == 0 {
-		util.Response(w, errors.New("no accounts found").Error(), http.StatusNotFound)
-		return
-	}
-
-	util.JsonResponse(w, accounts)
-}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/mailjet"
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
+	"github.com/kwandapchumba/go-bookmark-manager/tasks"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// a confirmed deletion can be cancelled for 30 days, after that the account is gone
+const (
+	accountDeletionGracePeriodDays = 30
+	accountDeletionGracePeriod     = accountDeletionGracePeriodDays * 24 * time.Hour
+	accountDeletionCodeDuration    = 30 * time.Minute
+)
+
+type accountDeletion struct {
+	Requested         bool       `json:"requested"`
+	SharedCollections string     `json:"shared_collections,omitempty"`
+	DeleteAfter       *time.Time `json:"delete_after"`
+}
+
+func newAccountDeletion(d sqlc.AccountDeletion) accountDeletion {
+	res := accountDeletion{
+		Requested:         true,
+		SharedCollections: d.SharedCollections,
+	}
+
+	if d.DeleteAfter.Valid {
+		res.DeleteAfter = &d.DeleteAfter.Time
+	}
+
+	return res
+}
+
+type requestAccountDeletionRequest struct {
+	Password          string `json:"password"`
+	SharedCollections string `json:"shared_collections"`
+}
+
+func (d requestAccountDeletionRequest) validate() error {
+	return validation.ValidateStruct(&d,
+		validation.Field(&d.SharedCollections, validation.In(tasks.SharedCollectionsTransfer, tasks.SharedCollectionsDelete).Error("shared_collections must be transfer or delete")),
+	)
+}
+
+// RequestAccountDeletion emails a code that confirms the deletion. shared_collections says what happens to
+// collections other accounts are members of, they are transferred to a member unless it is "delete".
+func (h *BaseHandler) RequestAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req requestAccountDeletionRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.SharedCollections == "" {
+		req.SharedCollections = tasks.SharedCollectionsTransfer
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	account, err := q.GetAccount(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if account.AccountPassword != "" && !util.CompareHash(req.Password, account.AccountPassword) {
+		util.Response(w, "invalid password", http.StatusUnauthorized)
+		return
+	}
+
+	deletion, err := q.GetAccountDeletion(r.Context(), account.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err == nil && deletion.DeleteAfter.Valid {
+		util.Response(w, "account deletion is already scheduled", http.StatusConflict)
+		return
+	}
+
+	code := util.OTPGenerator()
+
+	if _, err := q.CreateAccountDeletion(r.Context(), sqlc.CreateAccountDeletionParams{
+		AccountID:         account.ID,
+		Code:              code,
+		CodeExpiry:        time.Now().UTC().Add(accountDeletionCodeDuration),
+		SharedCollections: req.SharedCollections,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	mailjet.NewAccountDeletionMail(account.Email, account.Fullname, code, accountDeletionGracePeriodDays).SendAccountDeletionMail()
+
+	util.Response(w, "confirmation code has been sent", http.StatusOK)
+}
+
+type confirmAccountDeletionRequest struct {
+	Code string `json:"code"`
+}
+
+func (c confirmAccountDeletionRequest) validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Code, validation.Required.Error("code is required")),
+	)
+}
+
+// ConfirmAccountDeletion schedules the deletion and signs out every other device. The account keeps working until
+// the grace period is over so the deletion can still be cancelled.
+func (h *BaseHandler) ConfirmAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req confirmAccountDeletionRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	key := ratelimit.AccountKey(payload.AccountID)
+
+	if h.lockedOut(w, r.Context(), key) {
+		return
+	}
+
+	q := sqlc.New(h.db)
+
+	deletion, err := q.GetAccountDeletion(r.Context(), payload.AccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "no account deletion was requested", http.StatusNotFound)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if deletion.DeleteAfter.Valid {
+		util.Response(w, "account deletion is already scheduled", http.StatusConflict)
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(deletion.Code), []byte(strings.TrimSpace(req.Code))) != 1 {
+		h.signInFailed(r.Context(), key)
+		util.Response(w, "invalid code", http.StatusUnauthorized)
+		return
+	}
+
+	// an expired code leaves the failures counted so far in place
+	if time.Now().UTC().After(deletion.CodeExpiry) {
+		util.Response(w, "code has expired", http.StatusUnauthorized)
+		return
+	}
+
+	h.signInSucceeded(r.Context(), key)
+
+	deletion, err = q.ScheduleAccountDeletion(r.Context(), sqlc.ScheduleAccountDeletionParams{
+		DeleteAfter: sql.NullTime{Time: time.Now().UTC().Add(accountDeletionGracePeriod), Valid: true},
+		AccountID:   payload.AccountID,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if _, err := q.DeleteOtherAccountSessions(r.Context(), sqlc.DeleteOtherAccountSessionsParams{
+		AccountID: payload.AccountID,
+		SessionID: payload.SessionID,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newAccountDeletion(deletion))
+}
+
+func (h *BaseHandler) GetAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	deletion, err := sqlc.New(h.db).GetAccountDeletion(r.Context(), payload.AccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.JsonResponse(w, accountDeletion{})
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newAccountDeletion(deletion))
+}
+
+// CancelAccountDeletion keeps the account, a requested or scheduled deletion is dropped
+func (h *BaseHandler) CancelAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	n, err := sqlc.New(h.db).CancelAccountDeletion(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if n == 0 {
+		util.Response(w, "no account deletion was requested", http.StatusNotFound)
+		return
+	}
+
+	util.Response(w, "account deletion cancelled", http.StatusOK)
+}
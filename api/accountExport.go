@@ -0,0 +1,222 @@
+package api
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+type exportedNote struct {
+	LinkID    string    `json:"link_id"`
+	LinkTitle string    `json:"link_title"`
+	LinkURL   string    `json:"link_url"`
+	Notes     string    `json:"notes"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type exportedInvite struct {
+	InviteID     int64     `json:"invite_id"`
+	CollectionID string    `json:"collection_id"`
+	Email        string    `json:"email"`
+	AccessLevel  string    `json:"access_level"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type exportedSupportMessage struct {
+	ID         int64      `json:"id"`
+	Message    string     `json:"message"`
+	SentAt     time.Time  `json:"sent_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// accountExportFile is one file of the zip ExportAccountData answers with
+type accountExportFile struct {
+	name string
+	data interface{}
+}
+
+// ExportAccountData answers with a zip of everything stored about the account: its profile, folders, links, notes,
+// tags, memberships of collections shared with it, invites it sent and its support messages. Trashed folders and links
+// are included, bookmarks.html can be imported into a browser.
+func (h *BaseHandler) ExportAccountData(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	account, err := q.GetAccount(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	folders, err := q.GetAccountFoldersForExport(r.Context(), account.ID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	links, err := q.GetAccountLinksForExport(r.Context(), account.ID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	tags, err := q.GetTagsByAccountID(r.Context(), account.ID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	smartFolders, err := q.GetAccountSmartFolders(r.Context(), account.ID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	memberships, err := q.GetCollectionsSharedWithUser(r.Context(), account.ID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	invites, err := q.GetInvitesSentByEmail(r.Context(), account.Email)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	messages, err := q.GetAccountSupportMessages(r.Context(), account.ID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	notes := []exportedNote{}
+
+	for _, link := range links {
+		if link.LinkNotes == "" {
+			continue
+		}
+
+		notes = append(notes, exportedNote{
+			LinkID:    link.LinkID,
+			LinkTitle: link.LinkTitle,
+			LinkURL:   link.LinkUrl,
+			Notes:     link.LinkNotes,
+			UpdatedAt: link.UpdatedAt,
+		})
+	}
+
+	exportedMemberships := []membershipResponse{}
+
+	for _, membership := range memberships {
+		exportedMemberships = append(exportedMemberships, newMembershipResponse(membership))
+	}
+
+	exportedInvites := []exportedInvite{}
+
+	for _, invite := range invites {
+		exportedInvites = append(exportedInvites, exportedInvite{
+			InviteID:     invite.InviteID,
+			CollectionID: invite.SharedCollectionID,
+			Email:        invite.CollectionSharedWith,
+			AccessLevel:  string(invite.MemberAccessLevel),
+			ExpiresAt:    invite.InviteExpiry,
+		})
+	}
+
+	exportedMessages := []exportedSupportMessage{}
+
+	for _, message := range messages {
+		m := exportedSupportMessage{
+			ID:      message.ID,
+			Message: message.MessageBody,
+			SentAt:  message.SentAt,
+		}
+
+		if message.ResolvedAt.Valid {
+			m.ResolvedAt = &message.ResolvedAt.Time
+		}
+
+		exportedMessages = append(exportedMessages, m)
+	}
+
+	exportedFolders := newFolderResponses(folders)
+	if exportedFolders == nil {
+		exportedFolders = []folderResponse{}
+	}
+
+	exportedLinks := newLinkResponses(links)
+	if exportedLinks == nil {
+		exportedLinks = []linkResponse{}
+	}
+
+	exportedSmartFolders := []folderResponse{}
+
+	for _, smartFolder := range smartFolders {
+		exportedSmartFolders = append(exportedSmartFolders, newSmartFolderResponse(smartFolder))
+	}
+
+	if tags == nil {
+		tags = []sqlc.GetTagsByAccountIDRow{}
+	}
+
+	files := []accountExportFile{
+		{name: "account.json", data: newAccountResponse(account)},
+		{name: "folders.json", data: exportedFolders},
+		{name: "smart_folders.json", data: exportedSmartFolders},
+		{name: "links.json", data: exportedLinks},
+		{name: "notes.json", data: notes},
+		{name: "tags.json", data: tags},
+		{name: "memberships.json", data: exportedMemberships},
+		{name: "invites_sent.json", data: exportedInvites},
+		{name: "support_messages.json", data: exportedMessages},
+	}
+
+	filename := fmt.Sprintf("linkspace-data-%s.zip", time.Now().UTC().Format("2006-01-02"))
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// the headers are gone once the zip is being written, errors from here on can only be logged
+	zw := zip.NewWriter(w)
+
+	for _, file := range files {
+		f, err := zw.Create(file.name)
+		if err != nil {
+			log.Printf("could not add %s to account export at accountExport.go: %v", file.name, err)
+			return
+		}
+
+		encoder := json.NewEncoder(f)
+
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(file.data); err != nil {
+			log.Printf("could not write %s to account export at accountExport.go: %v", file.name, err)
+			return
+		}
+	}
+
+	f, err := zw.Create("bookmarks.html")
+	if err != nil {
+		log.Printf("could not add bookmarks.html to account export at accountExport.go: %v", err)
+		return
+	}
+
+	if err := util.WriteNetscapeBookmarks(f, exportTreeToNetscape(buildExportTree(folders, links, true))); err != nil {
+		log.Printf("could not write bookmarks.html to account export at accountExport.go: %v", err)
+		return
+	}
+
+	if err := zw.Close(); err != nil {
+		log.Printf("could not finish account export at accountExport.go: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// accountSession leaves out the refresh token id, it is what makes a refresh token valid
+type accountSession struct {
+	SessionID  string    `json:"session_id"`
+	UserAgent  string    `json:"user_agent"`
+	ClientIP   string    `json:"client_ip"`
+	SignedInAt time.Time `json:"signed_in_at"`
+	LastActive time.Time `json:"last_active"`
+	Expiry     time.Time `json:"expiry"`
+	Current    bool      `json:"current"`
+}
+
+func newAccountSession(s sqlc.AccountSession, currentSessionID string) accountSession {
+	return accountSession{
+		SessionID:  s.SessionID,
+		UserAgent:  s.UserAgent,
+		ClientIP:   s.ClientIp,
+		SignedInAt: s.CreatedAt,
+		LastActive: s.IssuedAt,
+		Expiry:     s.Expiry,
+		Current:    s.SessionID == currentSessionID,
+	}
+}
+
+func (h *BaseHandler) GetAccountSessions(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	sessions, err := q.GetAccountSessions(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	res := []accountSession{}
+
+	for _, session := range sessions {
+		res = append(res, newAccountSession(session, payload.SessionID))
+	}
+
+	util.JsonResponse(w, res)
+}
+
+// RevokeAccountSession signs a device out, its access token stops working straight away
+func (h *BaseHandler) RevokeAccountSession(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	n, err := q.DeleteAccountSession(r.Context(), sqlc.DeleteAccountSessionParams{
+		SessionID: chi.URLParam(r, "sessionID"),
+		AccountID: payload.AccountID,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if n == 0 {
+		util.Response(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	util.Response(w, "session revoked", http.StatusOK)
+}
+
+// RevokeOtherAccountSessions signs out every device except the one making the request
+func (h *BaseHandler) RevokeOtherAccountSessions(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	n, err := q.DeleteOtherAccountSessions(r.Context(), sqlc.DeleteOtherAccountSessionsParams{
+		AccountID: payload.AccountID,
+		SessionID: payload.SessionID,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, map[string]int64{"revoked": n})
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// page reads the page and per_page query parameters, pages start at 1
+func page(r *http.Request) (int32, int32, error) {
+	number, size := int64(1), int64(defaultPageSize)
+
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("page must be a positive number")
+		}
+
+		number = n
+	}
+
+	if v := r.URL.Query().Get("per_page"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 1 || n > maxPageSize {
+			return 0, 0, errors.New("per_page must be between 1 and " + strconv.Itoa(maxPageSize))
+		}
+
+		size = n
+	}
+
+	return int32(number), int32(size), nil
+}
+
+// adminAccount adds whether the account is suspended, which only admins get to see
+type adminAccount struct {
+	accountResponse
+	SuspendedAt *time.Time `json:"suspended_at"`
+}
+
+func newAdminAccount(a sqlc.Account) adminAccount {
+	res := adminAccount{accountResponse: newAccountResponse(a)}
+
+	if a.SuspendedAt.Valid {
+		res.SuspendedAt = &a.SuspendedAt.Time
+	}
+
+	return res
+}
+
+type accountsPage struct {
+	Accounts []adminAccount `json:"accounts"`
+	Page     int32          `json:"page"`
+	PerPage  int32          `json:"per_page"`
+	Total    int64          `json:"total"`
+}
+
+// ListAccounts pages through every account, query narrows it down to emails and names containing it
+func (h *BaseHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	number, size, err := page(r)
+	if err != nil {
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	search := strings.TrimSpace(r.URL.Query().Get("query"))
+
+	q := sqlc.New(h.db)
+
+	total, err := q.CountAccounts(r.Context(), search)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	accounts, err := q.ListAccounts(r.Context(), sqlc.ListAccountsParams{
+		Search:     search,
+		PageSize:   size,
+		PageOffset: (number - 1) * size,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	res := accountsPage{
+		Accounts: []adminAccount{},
+		Page:     number,
+		PerPage:  size,
+		Total:    total,
+	}
+
+	for _, account := range accounts {
+		res.Accounts = append(res.Accounts, newAdminAccount(account))
+	}
+
+	util.JsonResponse(w, res)
+}
+
+// SuspendAccount stops an account from signing in and ends every session it has. Admins cannot be suspended, an
+// admin has to lose the role first.
+func (h *BaseHandler) SuspendAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "accountID"), 10, 64)
+	if err != nil {
+		util.Response(w, "invalid account id", http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	if accountID == payload.AccountID {
+		util.Response(w, "you cannot suspend your own account", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := h.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	defer tx.Rollback()
+
+	qtx := sqlc.New(h.db).WithTx(tx)
+
+	account, err := qtx.GetAccount(r.Context(), accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "account not found", http.StatusNotFound)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if account.IsAdmin {
+		util.Response(w, "admin accounts cannot be suspended", http.StatusBadRequest)
+		return
+	}
+
+	account, err = qtx.SuspendAccount(r.Context(), accountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err := qtx.DeleteAccountSessions(r.Context(), accountID); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newAdminAccount(account))
+}
+
+func (h *BaseHandler) UnsuspendAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "accountID"), 10, 64)
+	if err != nil {
+		util.Response(w, "invalid account id", http.StatusBadRequest)
+		return
+	}
+
+	account, err := sqlc.New(h.db).UnsuspendAccount(r.Context(), accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "account not found", http.StatusNotFound)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newAdminAccount(account))
+}
+
+type supportMessage struct {
+	ID         int64      `json:"id"`
+	AccountID  int64      `json:"account_id"`
+	Fullname   string     `json:"fullname"`
+	Email      string     `json:"email"`
+	Message    string     `json:"message"`
+	SentAt     time.Time  `json:"sent_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+type supportMessagesPage struct {
+	Messages []supportMessage `json:"messages"`
+	Page     int32            `json:"page"`
+	PerPage  int32            `json:"per_page"`
+	Total    int64            `json:"total"`
+}
+
+// GetSupportMessages pages through the support inbox, newest first. Open messages are listed unless resolved=true.
+func (h *BaseHandler) GetSupportMessages(w http.ResponseWriter, r *http.Request) {
+	number, size, err := page(r)
+	if err != nil {
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resolved := r.URL.Query().Get("resolved") == "true"
+
+	q := sqlc.New(h.db)
+
+	total, err := q.CountSupportMessages(r.Context(), resolved)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	messages, err := q.GetSupportMessages(r.Context(), sqlc.GetSupportMessagesParams{
+		Resolved:   resolved,
+		PageSize:   size,
+		PageOffset: (number - 1) * size,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	res := supportMessagesPage{
+		Messages: []supportMessage{},
+		Page:     number,
+		PerPage:  size,
+		Total:    total,
+	}
+
+	for _, m := range messages {
+		message := supportMessage{
+			ID:        m.ID,
+			AccountID: m.Account,
+			Fullname:  m.Fullname,
+			Email:     m.Email,
+			Message:   m.MessageBody,
+			SentAt:    m.SentAt,
+		}
+
+		if m.ResolvedAt.Valid {
+			message.ResolvedAt = &m.ResolvedAt.Time
+		}
+
+		res.Messages = append(res.Messages, message)
+	}
+
+	util.JsonResponse(w, res)
+}
+
+func (h *BaseHandler) ResolveSupportMessage(w http.ResponseWriter, r *http.Request) {
+	messageID, err := strconv.ParseInt(chi.URLParam(r, "messageID"), 10, 64)
+	if err != nil {
+		util.Response(w, "invalid message id", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := sqlc.New(h.db).ResolveSupportMessage(r.Context(), messageID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "message not found", http.StatusNotFound)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.Response(w, "message resolved", http.StatusOK)
+}
+
+func (h *BaseHandler) GetSystemStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := sqlc.New(h.db).GetSystemStats(r.Context())
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, stats)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/middleware"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// authorizeFolders loads every folder and checks the user has at least role min on each of them before anything is
+// changed. It writes the error response itself and returns false when a folder is missing or the role is too low.
+func authorizeFolders(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, folderIDs []string, accountID int64, min middleware.Role) ([]sqlc.Folder, bool) {
+	var folders []sqlc.Folder
+
+	for _, folderID := range folderIDs {
+		folder, err := q.GetFolder(ctx, folderID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				util.Response(w, "folder not found", http.StatusNotFound)
+				return nil, false
+			}
+
+			ErrorInternalServerError(w, err)
+			return nil, false
+		}
+
+		role, err := middleware.FolderRole(ctx, q, folder, accountID)
+		if err != nil {
+			ErrorInternalServerError(w, err)
+			return nil, false
+		}
+
+		if !hasRole(w, role, min) {
+			return nil, false
+		}
+
+		folders = append(folders, folder)
+	}
+
+	return folders, true
+}
+
+func authorizeFolder(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, folderID string, accountID int64, min middleware.Role) (sqlc.Folder, bool) {
+	folders, ok := authorizeFolders(w, ctx, q, []string{folderID}, accountID, min)
+	if !ok {
+		return sqlc.Folder{}, false
+	}
+
+	return folders[0], true
+}
+
+// authorizeLinks is authorizeFolders for links, a link's role is the role on the folder it is in
+func authorizeLinks(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, linkIDs []string, accountID int64, min middleware.Role) ([]sqlc.Link, bool) {
+	var links []sqlc.Link
+
+	for _, linkID := range linkIDs {
+		link, err := q.GetLink(ctx, linkID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				util.Response(w, "link not found", http.StatusNotFound)
+				return nil, false
+			}
+
+			ErrorInternalServerError(w, err)
+			return nil, false
+		}
+
+		role, err := middleware.LinkRole(ctx, q, link, accountID)
+		if err != nil {
+			ErrorInternalServerError(w, err)
+			return nil, false
+		}
+
+		if !hasRole(w, role, min) {
+			return nil, false
+		}
+
+		links = append(links, link)
+	}
+
+	return links, true
+}
+
+func authorizeLink(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, linkID string, accountID int64, min middleware.Role) (sqlc.Link, bool) {
+	links, ok := authorizeLinks(w, ctx, q, []string{linkID}, accountID, min)
+	if !ok {
+		return sqlc.Link{}, false
+	}
+
+	return links[0], true
+}
+
+// authorizeMoveOut checks what is in source may be moved to destination. Moving within a collection takes edit
+// access, moving to another one hands what is moved to that collection's owner so it takes owning it.
+func authorizeMoveOut(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, source, destination sqlc.Folder, accountID int64) bool {
+	if middleware.SameCollection(source, destination) {
+		return true
+	}
+
+	role, err := middleware.FolderRole(ctx, q, source, accountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return false
+	}
+
+	return hasRole(w, role, middleware.RoleOwner)
+}
+
+func hasRole(w http.ResponseWriter, role, min middleware.Role) bool {
+	switch {
+	case role == middleware.RoleNone:
+		log.Println("unauthorized")
+		util.Response(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	case role < min:
+		log.Printf(`user role "%v" is below the required "%v"`, role, min)
+		util.Response(w, "access denied due to insufficient access level", http.StatusUnauthorized)
+		return false
+	default:
+		return true
+	}
+}
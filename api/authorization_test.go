@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/dbtest"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/middleware"
+)
+
+const (
+	ownerID int64 = iota + 1
+	editorID
+	viewerID
+	strangerID
+)
+
+// testCollections are two collections of ownerID, the first shared with editorID and its subfolder with viewerID
+var testCollections = dbtest.Collections{
+	Folders: []sqlc.Folder{
+		{FolderID: "root", AccountID: ownerID, Path: "root", Label: "root"},
+		{FolderID: "child", AccountID: ownerID, Path: "root.child", Label: "child", SubfolderOf: sql.NullString{String: "root", Valid: true}},
+		{FolderID: "other", AccountID: ownerID, Path: "other", Label: "other"},
+		{FolderID: "editors", AccountID: editorID, Path: "editors", Label: "editors"},
+	},
+	Links: []sqlc.Link{
+		{LinkID: "root-link", AccountID: ownerID},
+		{LinkID: "child-link", AccountID: ownerID, FolderID: sql.NullString{String: "child", Valid: true}},
+	},
+	Members: []sqlc.CollectionMember{
+		{CollectionID: "root", MemberID: editorID, CollectionAccessLevel: sqlc.CollectionAccessLevelEdit},
+		{CollectionID: "child", MemberID: viewerID, CollectionAccessLevel: sqlc.CollectionAccessLevelView},
+	},
+}
+
+func TestHasRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		role, min  middleware.Role
+		want       bool
+		wantStatus int
+	}{
+		{"no role", middleware.RoleNone, middleware.RoleView, false, http.StatusUnauthorized},
+		{"below the minimum", middleware.RoleView, middleware.RoleEdit, false, http.StatusUnauthorized},
+		{"at the minimum", middleware.RoleEdit, middleware.RoleEdit, true, http.StatusOK},
+		{"above the minimum", middleware.RoleOwner, middleware.RoleView, true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			if got := hasRole(w, tt.role, tt.min); got != tt.want {
+				t.Errorf("hasRole(%v, %v) = %v, want %v", tt.role, tt.min, got, tt.want)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthorizeFolders(t *testing.T) {
+	q := sqlc.New(dbtest.Open(testCollections.Queries()))
+
+	tests := []struct {
+		name       string
+		folderIDs  []string
+		accountID  int64
+		min        middleware.Role
+		want       bool
+		wantStatus int
+	}{
+		{"owner", []string{"root", "child", "other"}, ownerID, middleware.RoleOwner, true, http.StatusOK},
+		{"editor", []string{"root", "child"}, editorID, middleware.RoleEdit, true, http.StatusOK},
+		{"editor is not owner", []string{"child"}, editorID, middleware.RoleOwner, false, http.StatusUnauthorized},
+		{"viewer of a subfolder", []string{"child"}, viewerID, middleware.RoleView, true, http.StatusOK},
+		{"viewer cannot edit", []string{"child"}, viewerID, middleware.RoleEdit, false, http.StatusUnauthorized},
+		{"viewer of a subfolder is not a viewer of its parent", []string{"child", "root"}, viewerID, middleware.RoleView, false, http.StatusUnauthorized},
+		{"one folder of another collection", []string{"root", "other"}, editorID, middleware.RoleEdit, false, http.StatusUnauthorized},
+		{"stranger", []string{"root"}, strangerID, middleware.RoleView, false, http.StatusUnauthorized},
+		{"missing folder", []string{"root", "missing"}, ownerID, middleware.RoleView, false, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			folders, ok := authorizeFolders(w, context.Background(), q, tt.folderIDs, tt.accountID, tt.min)
+			if ok != tt.want {
+				t.Fatalf("authorizeFolders(%v, %d, %v) = %v, want %v", tt.folderIDs, tt.accountID, tt.min, ok, tt.want)
+			}
+
+			if ok && len(folders) != len(tt.folderIDs) {
+				t.Errorf("got %d folders, want %d", len(folders), len(tt.folderIDs))
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthorizeLinks(t *testing.T) {
+	q := sqlc.New(dbtest.Open(testCollections.Queries()))
+
+	tests := []struct {
+		name       string
+		linkIDs    []string
+		accountID  int64
+		min        middleware.Role
+		want       bool
+		wantStatus int
+	}{
+		{"owner of root and folder links", []string{"root-link", "child-link"}, ownerID, middleware.RoleOwner, true, http.StatusOK},
+		{"editor inherits from the collection", []string{"child-link"}, editorID, middleware.RoleEdit, true, http.StatusOK},
+		{"editor has nothing on root links of others", []string{"root-link"}, editorID, middleware.RoleView, false, http.StatusUnauthorized},
+		{"viewer cannot edit", []string{"child-link"}, viewerID, middleware.RoleEdit, false, http.StatusUnauthorized},
+		{"stranger", []string{"child-link"}, strangerID, middleware.RoleView, false, http.StatusUnauthorized},
+		{"missing link", []string{"missing"}, ownerID, middleware.RoleView, false, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			if _, ok := authorizeLinks(w, context.Background(), q, tt.linkIDs, tt.accountID, tt.min); ok != tt.want {
+				t.Fatalf("authorizeLinks(%v, %d, %v) = %v, want %v", tt.linkIDs, tt.accountID, tt.min, ok, tt.want)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthorizeMoveOut(t *testing.T) {
+	q := sqlc.New(dbtest.Open(testCollections.Queries()))
+
+	tests := []struct {
+		name                string
+		source, destination string
+		accountID           int64
+		want                bool
+	}{
+		{"editor within a collection", "child", "root", editorID, true},
+		{"editor out of a collection", "child", "editors", editorID, false},
+		{"owner out of a collection", "child", "other", ownerID, true},
+		{"owner into a collection of another account", "child", "editors", ownerID, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := q.GetFolder(context.Background(), tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			destination, err := q.GetFolder(context.Background(), tt.destination)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := authorizeMoveOut(httptest.NewRecorder(), context.Background(), q, source, destination, tt.accountID); got != tt.want {
+				t.Errorf("authorizeMoveOut(%s, %s, %d) = %v, want %v", tt.source, tt.destination, tt.accountID, got, tt.want)
+			}
+		})
+	}
+}
@@ -108,7 +108,7 @@ func (h *BaseHandler) CheckIfFolderHasBeenSharedWithUser(w http.ResponseWriter,
 						MemberID:     int64(memberID),
 					})
 
-					util.JsonResponse(w, cm)
+					util.JsonResponse(w, newMembershipResponse(cm))
 					return
 				}
 			}
@@ -128,5 +128,5 @@ func (h *BaseHandler) CheckIfFolderHasBeenSharedWithUser(w http.ResponseWriter,
 		return
 	}
 
-	util.JsonResponse(w, collectionMemberInstance)
+	util.JsonResponse(w, newMembershipResponse(collectionMemberInstance))
 }
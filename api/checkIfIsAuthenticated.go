@@ -1,7 +1,6 @@
 package api
 
 import (
-	"database/sql"
 	"encoding/json"
 	"errors"
 	"log"
@@ -76,7 +75,7 @@ func (h *BaseHandler) CheckIfIsAuthenticated(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	payload, err := auth.VerifyToken(req.Token)
+	payload, err := auth.VerifyToken(req.Token, auth.AccessToken)
 	if err != nil {
 		log.Println(err)
 		util.Response(w, err.Error(), http.StatusUnauthorized)
@@ -85,26 +84,22 @@ func (h *BaseHandler) CheckIfIsAuthenticated(w http.ResponseWriter, r *http.Requ
 
 	q := sqlc.New(h.db)
 
-	account, err := q.GetAccount(r.Context(), int64(payload.AccountID))
+	_, active, err := auth.ActiveSession(r.Context(), q, payload)
 	if err != nil {
 		var pgErr *pgconn.PgError
 
 		if errors.As(err, &pgErr) {
-			log.Printf("failed to get account with err: %v", pgErr)
+			log.Printf("failed to get account session with err: %v", pgErr)
 			util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
 			return
-		} else if errors.Is(err, sql.ErrNoRows) {
-			log.Println("account not found")
-			util.Response(w, errors.New("account not found").Error(), http.StatusUnauthorized)
-			return
 		} else {
-			log.Printf("failed to get account with err: %v", err)
+			log.Printf("failed to get account session with err: %v", err)
 			util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
 			return
 		}
 	}
 
-	if account.LastLogin.Unix() != payload.IssuedAt.Unix() {
+	if !active {
 		util.Response(w, "user not logged in", http.StatusUnauthorized)
 		return
 	}
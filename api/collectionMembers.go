@@ -0,0 +1,457 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/mailjet"
+	"github.com/kwandapchumba/go-bookmark-manager/middleware"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// invites are valid for 3 days, resending one starts the 3 days again
+const inviteDuration = 72 * time.Hour
+
+type collectionMember struct {
+	AccountID   int64     `json:"account_id"`
+	Fullname    string    `json:"fullname"`
+	Email       string    `json:"email"`
+	AccessLevel string    `json:"access_level"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// pendingInvite leaves out the invite token, only the invited email gets to see it
+type pendingInvite struct {
+	InviteID    int64     `json:"invite_id"`
+	Email       string    `json:"email"`
+	AccessLevel string    `json:"access_level"`
+	InvitedBy   string    `json:"invited_by"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Expired     bool      `json:"expired"`
+}
+
+func newPendingInvite(i sqlc.MemberInvite) pendingInvite {
+	return pendingInvite{
+		InviteID:    i.InviteID,
+		Email:       i.CollectionSharedWith,
+		AccessLevel: string(i.MemberAccessLevel),
+		InvitedBy:   i.CollectionSharedByEmail,
+		ExpiresAt:   i.InviteExpiry,
+		Expired:     !time.Now().UTC().Before(i.InviteExpiry),
+	}
+}
+
+type collectionMembersResponse struct {
+	Owner   collectionMember   `json:"owner"`
+	Members []collectionMember `json:"members"`
+	// only admins get to see pending invites
+	Invites []pendingInvite `json:"invites,omitempty"`
+}
+
+// collectionOwner is the account that owns the top level folder a collection is in
+func collectionOwner(ctx context.Context, q *sqlc.Queries, folder sqlc.Folder) (sqlc.Account, error) {
+	ancestors, err := q.GetFolderAncestors(ctx, folder.Label)
+	if err != nil {
+		return sqlc.Account{}, err
+	}
+
+	ownerID := folder.AccountID
+
+	if len(ancestors) > 0 {
+		ownerID = ancestors[0].AccountID
+	}
+
+	return q.GetAccount(ctx, ownerID)
+}
+
+func (h *BaseHandler) GetCollectionMembers(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	folder, ok := authorizeFolder(w, r.Context(), q, chi.URLParam(r, "folderID"), payload.AccountID, middleware.RoleView)
+	if !ok {
+		return
+	}
+
+	owner, err := collectionOwner(r.Context(), q, folder)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	members, err := q.GetCollectionMembers(r.Context(), folder.FolderID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	res := collectionMembersResponse{
+		Owner: collectionMember{
+			AccountID:   owner.ID,
+			Fullname:    owner.Fullname,
+			Email:       owner.Email,
+			AccessLevel: middleware.RoleOwner.String(),
+			JoinedAt:    folder.FolderCreatedAt,
+		},
+		Members: []collectionMember{},
+	}
+
+	for _, member := range members {
+		res.Members = append(res.Members, collectionMember{
+			AccountID:   member.MemberID,
+			Fullname:    member.Fullname,
+			Email:       member.Email,
+			AccessLevel: string(member.CollectionAccessLevel),
+			JoinedAt:    member.JoinDate,
+		})
+	}
+
+	role, err := middleware.FolderRole(r.Context(), q, folder, payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if role >= middleware.RoleAdmin {
+		invites, err := q.GetInvitesByCollectionID(r.Context(), folder.FolderID)
+		if err != nil {
+			ErrorInternalServerError(w, err)
+			return
+		}
+
+		res.Invites = []pendingInvite{}
+
+		for _, invite := range invites {
+			res.Invites = append(res.Invites, newPendingInvite(invite))
+		}
+	}
+
+	util.JsonResponse(w, res)
+}
+
+type changeAccessLevelRequest struct {
+	CollectionID string `json:"collection_id"`
+	MemberID     int64  `json:"member_id"`
+	AccessLevel  string `json:"access_level"`
+}
+
+func (c changeAccessLevelRequest) validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.CollectionID, validation.Required.Error("collection id is required")),
+		validation.Field(&c.MemberID, validation.Required.Error("member id is required")),
+		validation.Field(&c.AccessLevel, validation.Required.Error("access level is required"), validation.In("view", "edit", "admin").Error("access level must be one of view, edit or admin")),
+	)
+}
+
+type removeMemberRequest struct {
+	CollectionID string `json:"collection_id"`
+	MemberID     int64  `json:"member_id"`
+}
+
+func (m removeMemberRequest) validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.CollectionID, validation.Required.Error("collection id is required")),
+		validation.Field(&m.MemberID, validation.Required.Error("member id is required")),
+	)
+}
+
+// authorizeMemberChange checks the user may change another member's access to a collection. Admins manage everyone
+// below admin, only the owner can manage admins, and nobody manages themselves here.
+func authorizeMemberChange(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, collectionID string, memberID, accountID int64) (sqlc.Folder, sqlc.CollectionMember, bool) {
+	if memberID == accountID {
+		util.Response(w, "you cannot change your own access", http.StatusBadRequest)
+		return sqlc.Folder{}, sqlc.CollectionMember{}, false
+	}
+
+	folder, ok := authorizeFolder(w, ctx, q, collectionID, accountID, middleware.RoleAdmin)
+	if !ok {
+		return sqlc.Folder{}, sqlc.CollectionMember{}, false
+	}
+
+	member, err := q.GetCollectionMemberByCollectionAndMemberIDs(ctx, sqlc.GetCollectionMemberByCollectionAndMemberIDsParams{
+		CollectionID: folder.FolderID,
+		MemberID:     memberID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "member not found", http.StatusNotFound)
+			return sqlc.Folder{}, sqlc.CollectionMember{}, false
+		}
+
+		ErrorInternalServerError(w, err)
+		return sqlc.Folder{}, sqlc.CollectionMember{}, false
+	}
+
+	if member.CollectionAccessLevel == sqlc.CollectionAccessLevelAdmin {
+		role, err := middleware.FolderRole(ctx, q, folder, accountID)
+		if err != nil {
+			ErrorInternalServerError(w, err)
+			return sqlc.Folder{}, sqlc.CollectionMember{}, false
+		}
+
+		if !hasRole(w, role, middleware.RoleOwner) {
+			return sqlc.Folder{}, sqlc.CollectionMember{}, false
+		}
+	}
+
+	return folder, member, true
+}
+
+func (h *BaseHandler) ChangeMemberAccessLevel(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req changeAccessLevelRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	folder, _, ok := authorizeMemberChange(w, r.Context(), q, req.CollectionID, req.MemberID, payload.AccountID)
+	if !ok {
+		return
+	}
+
+	member, err := q.UpdateCollectionMemberAccessLevel(r.Context(), sqlc.UpdateCollectionMemberAccessLevelParams{
+		CollectionAccessLevel: sqlc.CollectionAccessLevel(req.AccessLevel),
+		CollectionID:          folder.FolderID,
+		MemberID:              req.MemberID,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newMembershipResponse(member))
+}
+
+func (h *BaseHandler) RemoveCollectionMember(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req removeMemberRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	folder, member, ok := authorizeMemberChange(w, r.Context(), q, req.CollectionID, req.MemberID, payload.AccountID)
+	if !ok {
+		return
+	}
+
+	remover, err := q.GetAccount(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	removed, err := q.GetAccount(r.Context(), member.MemberID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if _, err := q.DeleteCollectionMember(r.Context(), sqlc.DeleteCollectionMemberParams{
+		CollectionID: folder.FolderID,
+		MemberID:     member.MemberID,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	mail := mailjet.NewRemovedFromCollection(removed.Email, remover.Fullname, remover.Email, folder.FolderName)
+
+	mail.SendRemovedFromCollectionMail()
+
+	util.JsonResponse(w, newMembershipResponse(member))
+}
+
+type leaveCollectionRequest struct {
+	CollectionID string `json:"collection_id"`
+}
+
+func (l leaveCollectionRequest) validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.CollectionID, validation.Required.Error("collection id is required")),
+	)
+}
+
+func (h *BaseHandler) LeaveCollection(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req leaveCollectionRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	// owners are not members of their own collections so they have nothing to leave
+	n, err := q.DeleteCollectionMember(r.Context(), sqlc.DeleteCollectionMemberParams{
+		CollectionID: req.CollectionID,
+		MemberID:     payload.AccountID,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if n == 0 {
+		util.Response(w, "you are not a member of this collection", http.StatusNotFound)
+		return
+	}
+
+	util.Response(w, "you have left the collection", http.StatusOK)
+}
+
+type inviteRequest struct {
+	InviteID int64 `json:"invite_id"`
+}
+
+func (i inviteRequest) validate() error {
+	return validation.ValidateStruct(&i,
+		validation.Field(&i.InviteID, validation.Required.Error("invite id is required")),
+	)
+}
+
+// authorizeInvite decodes an inviteRequest and checks the user is an admin of the collection the invite is for
+func authorizeInvite(w http.ResponseWriter, r *http.Request, q *sqlc.Queries) (sqlc.MemberInvite, sqlc.Folder, bool) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req inviteRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return sqlc.MemberInvite{}, sqlc.Folder{}, false
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return sqlc.MemberInvite{}, sqlc.Folder{}, false
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	invite, err := q.GetInvite(r.Context(), req.InviteID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "invite not found", http.StatusNotFound)
+			return sqlc.MemberInvite{}, sqlc.Folder{}, false
+		}
+
+		ErrorInternalServerError(w, err)
+		return sqlc.MemberInvite{}, sqlc.Folder{}, false
+	}
+
+	folder, ok := authorizeFolder(w, r.Context(), q, invite.SharedCollectionID, payload.AccountID, middleware.RoleAdmin)
+	if !ok {
+		return sqlc.MemberInvite{}, sqlc.Folder{}, false
+	}
+
+	return invite, folder, true
+}
+
+func (h *BaseHandler) ResendInvite(w http.ResponseWriter, r *http.Request) {
+	q := sqlc.New(h.db)
+
+	invite, folder, ok := authorizeInvite(w, r, q)
+	if !ok {
+		return
+	}
+
+	invite, err := resendInvitation(r.Context(), q, invite, folder.FolderName)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newPendingInvite(invite))
+}
+
+func (h *BaseHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	q := sqlc.New(h.db)
+
+	invite, _, ok := authorizeInvite(w, r, q)
+	if !ok {
+		return
+	}
+
+	if err := q.DeleteInviteByID(r.Context(), invite.InviteID); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newPendingInvite(invite))
+}
+
+// resendInvitation replaces the invite token, so links in earlier invite emails stop working, and emails the new one
+func resendInvitation(ctx context.Context, q *sqlc.Queries, invite sqlc.MemberInvite, collectionName string) (sqlc.MemberInvite, error) {
+	token := uuid.NewString()
+
+	invite, err := q.RenewInvite(ctx, sqlc.RenewInviteParams{
+		InviteToken:  base64.StdEncoding.EncodeToString([]byte(token)),
+		InviteExpiry: time.Now().UTC().Add(inviteDuration),
+		InviteID:     invite.InviteID,
+	})
+	if err != nil {
+		return sqlc.MemberInvite{}, err
+	}
+
+	mail := mailjet.NewInviteUserMail(invite.CollectionSharedByName, invite.CollectionSharedByEmail, invite.CollectionSharedWith, token, collectionName, invite.InviteExpiry)
+
+	mail.SendInviteUserEmail()
+
+	return invite, nil
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-ozzo/ozzo-validation/is"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/mailjet"
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// email change codes are valid for as long as sign in codes
+const emailChangeDuration = 30 * time.Minute
+
+var errEmailTaken = errors.New("email address is already in use")
+
+type changeEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (c changeEmailRequest) validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Email, validation.Required.Error("email address is required"), is.Email.Error("email must be valid email address")),
+	)
+}
+
+// ChangeEmail sends a code to the new address and a notice to the old one, the email only changes once the code is
+// confirmed with ConfirmEmailChange. Accounts with a password have to enter it again.
+func (h *BaseHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req changeEmailRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	account, err := q.GetAccount(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if account.AccountPassword != "" && !util.CompareHash(req.Password, account.AccountPassword) {
+		util.Response(w, "invalid password", http.StatusUnauthorized)
+		return
+	}
+
+	if strings.EqualFold(req.Email, account.Email) {
+		util.Response(w, "that is already your email address", http.StatusBadRequest)
+		return
+	}
+
+	exists, err := q.EmailExists(r.Context(), req.Email)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if exists {
+		util.Response(w, errEmailTaken.Error(), http.StatusConflict)
+		return
+	}
+
+	code := util.OTPGenerator()
+
+	if _, err := q.NewEmailChangeCode(r.Context(), sqlc.NewEmailChangeCodeParams{
+		Code:      code,
+		Email:     req.Email,
+		Expiry:    time.Now().UTC().Add(emailChangeDuration),
+		AccountID: account.ID,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	mailjet.NewMail(req.Email, account.Fullname, code).SendEmailVificationMail()
+
+	mailjet.NewEmailChangeNotice(account.Email, req.Email, account.Fullname).SendEmailChangeNotice()
+
+	util.Response(w, "verification code has been sent", http.StatusOK)
+}
+
+type confirmEmailChangeRequest struct {
+	Code string `json:"code"`
+}
+
+func (c confirmEmailChangeRequest) validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Code, validation.Required.Error("code is required")),
+	)
+}
+
+// ConfirmEmailChange moves the account and its pending invites over to the new address and signs out every other
+// device
+func (h *BaseHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req confirmEmailChangeRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	key := ratelimit.AccountKey(payload.AccountID)
+
+	if h.lockedOut(w, r.Context(), key) {
+		return
+	}
+
+	q := sqlc.New(h.db)
+
+	change, err := q.GetEmailChangeCode(r.Context(), payload.AccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "no email change is pending", http.StatusNotFound)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if change.Code != strings.TrimSpace(req.Code) {
+		h.signInFailed(r.Context(), key)
+		util.Response(w, "invalid code", http.StatusUnauthorized)
+		return
+	}
+
+	h.signInSucceeded(r.Context(), key)
+
+	if time.Now().UTC().After(change.Expiry) {
+		util.Response(w, "code has expired", http.StatusUnauthorized)
+		return
+	}
+
+	tx, err := h.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	defer tx.Rollback()
+
+	qtx := q.WithTx(tx)
+
+	old, err := qtx.GetAccount(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	account, err := qtx.UpdateAccountEmail(r.Context(), sqlc.UpdateAccountEmailParams{
+		Email: change.Email,
+		ID:    payload.AccountID,
+	})
+	if err != nil {
+		// someone signed up with the address after the code was sent
+		if isUniqueViolation(err, "email_must_be_unique") {
+			util.Response(w, errEmailTaken.Error(), http.StatusConflict)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	// invites are matched by email, the ones sent to the old address follow the account. If the new address already
+	// has an invite to the same collection that one is kept.
+	if err := qtx.MoveInvitesToEmail(r.Context(), sqlc.MoveInvitesToEmailParams{
+		NewEmail: account.Email,
+		OldEmail: old.Email,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err := qtx.DeleteInvitesForEmail(r.Context(), old.Email); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err := qtx.UpdateInviterEmail(r.Context(), sqlc.UpdateInviterEmailParams{
+		NewEmail: account.Email,
+		OldEmail: old.Email,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err := qtx.DeleteEmailChangeCode(r.Context(), payload.AccountID); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if _, err := qtx.DeleteOtherAccountSessions(r.Context(), sqlc.DeleteOtherAccountSessionsParams{
+		AccountID: payload.AccountID,
+		SessionID: payload.SessionID,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newAccountResponse(account))
+}
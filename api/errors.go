@@ -8,6 +8,7 @@ import (
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/jackc/pgconn"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
@@ -28,6 +29,18 @@ func ErrorInternalServerError(w http.ResponseWriter, err error) {
 	}
 }
 
+// ErrorStartingSession answers a sign in auth.LoginUser refused, suspended accounts are told why
+func ErrorStartingSession(w http.ResponseWriter, err error) {
+	if errors.Is(err, auth.ErrAccountSuspended) {
+		log.Println(err)
+		util.Response(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	log.Printf("failed to create session with err: %v", err)
+	util.Response(w, internalServerError, http.StatusInternalServerError)
+}
+
 func ErrorDecodingRequest(w http.ResponseWriter, err error) {
 	if e, ok := err.(*json.SyntaxError); ok {
 		log.Printf("JSON syntax error occurred at offset byte: %d", e.Offset)
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/middleware"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+type exportedLink struct {
+	LinkID    string     `json:"link_id"`
+	Title     string     `json:"title"`
+	URL       string     `json:"url"`
+	Hostname  string     `json:"hostname"`
+	Notes     string     `json:"notes"`
+	Favicon   string     `json:"favicon"`
+	Thumbnail string     `json:"thumbnail"`
+	AddedAt   time.Time  `json:"added_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+type exportedFolder struct {
+	FolderID  string            `json:"folder_id"`
+	Name      string            `json:"name"`
+	Starred   bool              `json:"starred"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	DeletedAt *time.Time        `json:"deleted_at"`
+	Folders   []*exportedFolder `json:"folders"`
+	Links     []*exportedLink   `json:"links"`
+}
+
+type exportDocument struct {
+	ExportedAt time.Time         `json:"exported_at"`
+	Folders    []*exportedFolder `json:"folders"`
+	Links      []*exportedLink   `json:"links"`
+}
+
+func newExportedLink(l sqlc.Link) *exportedLink {
+	el := &exportedLink{
+		LinkID:    l.LinkID,
+		Title:     l.LinkTitle,
+		URL:       l.LinkUrl,
+		Hostname:  l.LinkHostname,
+		Notes:     l.LinkNotes,
+		Favicon:   l.LinkFavicon,
+		Thumbnail: l.LinkThumbnail,
+		AddedAt:   l.AddedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+
+	if l.DeletedAt.Valid {
+		el.DeletedAt = &l.DeletedAt.Time
+	}
+
+	return el
+}
+
+func newExportedFolder(f sqlc.Folder) *exportedFolder {
+	ef := &exportedFolder{
+		FolderID:  f.FolderID,
+		Name:      f.FolderName,
+		Starred:   f.Starred,
+		CreatedAt: f.FolderCreatedAt,
+		UpdatedAt: f.FolderUpdatedAt,
+		Folders:   []*exportedFolder{},
+		Links:     []*exportedLink{},
+	}
+
+	if f.FolderDeletedAt.Valid {
+		ef.DeletedAt = &f.FolderDeletedAt.Time
+	}
+
+	return ef
+}
+
+// buildExportTree nests folders and links under a nameless root. folders must be ordered by path so parents come
+// before their children. Trashed folders take their whole subtree with them unless includeTrashed is set.
+func buildExportTree(folders []sqlc.Folder, links []sqlc.Link, includeTrashed bool) *exportedFolder {
+	root := &exportedFolder{Folders: []*exportedFolder{}, Links: []*exportedLink{}}
+
+	byID := make(map[string]*exportedFolder)
+
+	skipped := make(map[string]bool)
+
+	for _, f := range folders {
+		if (f.FolderDeletedAt.Valid && !includeTrashed) || (f.SubfolderOf.Valid && skipped[f.SubfolderOf.String]) {
+			skipped[f.FolderID] = true
+			continue
+		}
+
+		ef := newExportedFolder(f)
+
+		byID[f.FolderID] = ef
+
+		// the parent of an exported collection is not part of the export
+		parent, ok := byID[f.SubfolderOf.String]
+		if !f.SubfolderOf.Valid || !ok {
+			parent = root
+		}
+
+		parent.Folders = append(parent.Folders, ef)
+	}
+
+	for _, l := range links {
+		if (l.DeletedAt.Valid && !includeTrashed) || (l.FolderID.Valid && skipped[l.FolderID.String]) {
+			continue
+		}
+
+		parent, ok := byID[l.FolderID.String]
+		if !l.FolderID.Valid || !ok {
+			parent = root
+		}
+
+		parent.Links = append(parent.Links, newExportedLink(l))
+	}
+
+	return root
+}
+
+func exportTreeToNetscape(f *exportedFolder) *util.NetscapeFolder {
+	nf := &util.NetscapeFolder{
+		Name:         f.Name,
+		AddDate:      f.CreatedAt,
+		LastModified: f.UpdatedAt,
+	}
+
+	for _, child := range f.Folders {
+		nf.Folders = append(nf.Folders, exportTreeToNetscape(child))
+	}
+
+	for _, l := range f.Links {
+		nf.Bookmarks = append(nf.Bookmarks, &util.NetscapeBookmark{
+			Title:   l.Title,
+			URL:     l.URL,
+			Notes:   l.Notes,
+			AddDate: l.AddedAt,
+		})
+	}
+
+	return nf
+}
+
+var exportCSVHeader = []string{"type", "id", "name", "url", "folder_path", "notes", "starred", "created_at", "updated_at", "deleted_at"}
+
+func writeExportCSV(w *csv.Writer, f *exportedFolder, path string) {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	for _, l := range f.Links {
+		w.Write([]string{"link", l.LinkID, l.Title, l.URL, path, l.Notes, "", formatTime(&l.AddedAt), formatTime(&l.UpdatedAt), formatTime(l.DeletedAt)})
+	}
+
+	for _, child := range f.Folders {
+		w.Write([]string{"folder", child.FolderID, child.Name, "", path, "", strconv.FormatBool(child.Starred), formatTime(&child.CreatedAt), formatTime(&child.UpdatedAt), formatTime(child.DeletedAt)})
+
+		writeExportCSV(w, child, strings.TrimPrefix(path+"/"+child.Name, "/"))
+	}
+}
+
+// exportFormat reads ?format=, it answers with 400 and returns false when it is not one of html, json or csv
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+
+	if format != "html" && format != "json" && format != "csv" {
+		util.Response(w, "format must be one of html, json or csv", http.StatusBadRequest)
+		return "", false
+	}
+
+	return format, true
+}
+
+// ExportBookmarks streams the whole account (folderID "null") or a single collection as netscape html, nested json or
+// flat csv. ?format= picks one of html, json (default) and csv; ?include_trashed=true keeps trashed folders and links.
+func (h *BaseHandler) ExportBookmarks(w http.ResponseWriter, r *http.Request) {
+	body := r.Context().Value("readRequestOnCollectionDetails").(*middleware.ReadRequestOnCollectionDetails)
+
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	includeTrashed := r.URL.Query().Get("include_trashed") == "true"
+
+	q := sqlc.New(h.db)
+
+	var (
+		folders []sqlc.Folder
+		links   []sqlc.Link
+		err     error
+	)
+
+	if body.FolderID == "null" {
+		folders, err = q.GetAccountFoldersForExport(r.Context(), body.Payload.AccountID)
+		if err != nil {
+			log.Printf("could not get account folders at export.go: %v", err)
+			util.Response(w, internalServerError, http.StatusInternalServerError)
+			return
+		}
+
+		links, err = q.GetAccountLinksForExport(r.Context(), body.Payload.AccountID)
+		if err != nil {
+			log.Printf("could not get account links at export.go: %v", err)
+			util.Response(w, internalServerError, http.StatusInternalServerError)
+			return
+		}
+	} else {
+		folders, err = q.GetCollectionFoldersForExport(r.Context(), body.FolderID)
+		if err != nil {
+			log.Printf("could not get collection folders at export.go: %v", err)
+			util.Response(w, internalServerError, http.StatusInternalServerError)
+			return
+		}
+
+		links, err = q.GetCollectionLinksForExport(r.Context(), body.FolderID)
+		if err != nil {
+			log.Printf("could not get collection links at export.go: %v", err)
+			util.Response(w, internalServerError, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeExport(w, buildExportTree(folders, links, includeTrashed), format)
+}
+
+// writeExport answers with root as an attachment in format, which is one of html, json or csv
+func writeExport(w http.ResponseWriter, root *exportedFolder, format string) {
+	filename := fmt.Sprintf("linkspace-export-%s.%s", time.Now().UTC().Format("2006-01-02"), format)
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	switch format {
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		if err := util.WriteNetscapeBookmarks(w, exportTreeToNetscape(root)); err != nil {
+			log.Printf("could not write html export at export.go: %v", err)
+		}
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+
+		cw := csv.NewWriter(w)
+
+		cw.Write(exportCSVHeader)
+
+		writeExportCSV(cw, root, "")
+
+		cw.Flush()
+
+		if err := cw.Error(); err != nil {
+			log.Printf("could not write csv export at export.go: %v", err)
+		}
+	default:
+		w.Header().Set("Content-Type", "application/json")
+
+		doc := exportDocument{
+			ExportedAt: time.Now().UTC(),
+			Folders:    root.Folders,
+			Links:      root.Links,
+		}
+
+		if err := json.NewEncoder(w).Encode(doc); err != nil {
+			log.Printf("could not write json export at export.go: %v", err)
+		}
+	}
+}
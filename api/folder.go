@@ -220,24 +220,9 @@ func (h *BaseHandler) CreateChildFolder(w http.ResponseWriter, r *http.Request)
 
 	q := sqlc.New(h.db)
 
-	parentFolder, err := q.GetFolder(r.Context(), req.ParentFolder)
-	if err != nil {
-		var pgErr *pgconn.PgError
-
-		switch {
-		case errors.As(err, &pgErr):
-			log.Println(pgErr)
-			util.Response(w, internalServerError, http.StatusInternalServerError)
-			return
-		case errors.Is(err, sql.ErrNoRows):
-			log.Println(sql.ErrNoRows.Error())
-			util.Response(w, "not found", http.StatusNotFound)
-			return
-		default:
-			log.Println(err)
-			util.Response(w, internalServerError, http.StatusInternalServerError)
-			return
-		}
+	parentFolder, ok := authorizeFolder(w, r.Context(), q, req.ParentFolder, payload.AccountID, middleware.RoleEdit)
+	if !ok {
+		return
 	}
 
 	parentFolderPath := parentFolder.Path
@@ -284,7 +269,7 @@ func (h *BaseHandler) CreateChildFolder(w http.ResponseWriter, r *http.Request)
 
 	wg.Wait()
 
-	util.JsonResponse(w, createdChildFolder)
+	util.JsonResponse(w, newFolderResponse(createdChildFolder))
 }
 
 func (h *BaseHandler) GetRootFolders(w http.ResponseWriter, r *http.Request) {
@@ -316,9 +301,19 @@ func (h *BaseHandler) GetRootFolders(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	log.Println(folders)
+	smartFolders, err := q.GetRootSmartFolders(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	res := newFolderResponses(folders)
+
+	for _, f := range smartFolders {
+		res = append(res, newSmartFolderResponse(f))
+	}
 
-	util.JsonResponse(w, folders)
+	util.JsonResponse(w, res)
 }
 
 func (h *BaseHandler) GetFolderChildren(w http.ResponseWriter, r *http.Request) {
@@ -394,7 +389,7 @@ func (h *BaseHandler) GetFolderChildren(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	util.JsonResponse(w, childrenFolders)
+	util.JsonResponse(w, newFolderResponses(childrenFolders))
 }
 
 // GET FOLDER ANCESTORS
@@ -442,7 +437,7 @@ func (h *BaseHandler) GetFolderAncestors(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	util.JsonResponse(w, ancestors)
+	util.JsonResponse(w, newFolderResponses(ancestors))
 }
 
 // STAR FOLDER
@@ -504,40 +499,18 @@ func (h *BaseHandler) StarFolders(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// get folder
-	q := sqlc.New(h.db)
-
-	var starredFolders []sqlc.Folder
-
-	for _, fid := range req.FolderIDs {
-		folder, err := q.GetFolder(r.Context(), fid)
-		if err != nil {
-			var pgErr *pgconn.PgError
+	payload := r.Context().Value("payload").(*auth.PayLoad)
 
-			switch {
-			case errors.As(err, &pgErr):
-				log.Println(pgErr)
-				util.Response(w, internalServerError, http.StatusInternalServerError)
-				return
-			case errors.Is(err, sql.ErrNoRows):
-				log.Println("folder not found")
-				util.Response(w, "folder not found", http.StatusNotFound)
-				return
-			default:
-				log.Println(err)
-				util.Response(w, internalServerError, http.StatusInternalServerError)
-				return
-			}
-		}
+	q := sqlc.New(h.db)
 
-		// check if folder belongs to caller
-		payload := r.Context().Value("payload").(*auth.PayLoad)
+	folders, ok := authorizeFolders(w, r.Context(), q, req.FolderIDs, payload.AccountID, middleware.RoleEdit)
+	if !ok {
+		return
+	}
 
-		if folder.AccountID != payload.AccountID {
-			util.Response(w, "unauthorized", http.StatusUnauthorized)
-			return
-		}
+	var starredFolders []sqlc.Folder
 
+	for _, folder := range folders {
 		// toggle folder star status
 		starredFolder, err := q.StarFolder(r.Context(), folder.FolderID)
 		if err != nil {
@@ -561,7 +534,7 @@ func (h *BaseHandler) StarFolders(w http.ResponseWriter, r *http.Request) {
 	wg.Wait()
 
 	// return starred of folders
-	util.JsonResponse(w, starredFolders)
+	util.JsonResponse(w, newFolderResponses(starredFolders))
 }
 
 // UNSTAR FOLDERS
@@ -624,41 +597,18 @@ func (h *BaseHandler) UnstarFolders(w http.ResponseWriter, r *http.Request) {
 
 	wg.Wait()
 
-	q := sqlc.New(h.db)
-
-	var unstarredFolders []sqlc.Folder
-
-	for _, folderID := range req.FolderIDs {
-		// check if each folder exists
-		folder, err := q.GetFolder(r.Context(), folderID)
-		if err != nil {
-			var pgErr *pgconn.PgError
+	payload := r.Context().Value("payload").(*auth.PayLoad)
 
-			switch {
-			case errors.As(err, &pgErr):
-				log.Println(pgErr)
-				util.Response(w, internalServerError, http.StatusInternalServerError)
-				return
-			case errors.Is(err, sql.ErrNoRows):
-				log.Println(sql.ErrNoRows.Error())
-				util.Response(w, "folder not found", http.StatusNotFound)
-				return
-			default:
-				log.Println(err)
-				util.Response(w, internalServerError, http.StatusInternalServerError)
-				return
-			}
-		}
+	q := sqlc.New(h.db)
 
-		// check if user is authorized ie is the owner of the folders
-		payload := r.Context().Value("payload").(*auth.PayLoad)
+	folders, ok := authorizeFolders(w, r.Context(), q, req.FolderIDs, payload.AccountID, middleware.RoleEdit)
+	if !ok {
+		return
+	}
 
-		if payload.AccountID != folder.AccountID {
-			log.Println("unauthorized")
-			util.Response(w, "unauthorized", http.StatusUnauthorized)
-			return
-		}
+	var unstarredFolders []sqlc.Folder
 
+	for _, folder := range folders {
 		// unstar each folder
 		unstarredFolder, err := q.UnstarFolder(r.Context(), folder.FolderID)
 		if err != nil {
@@ -679,7 +629,7 @@ func (h *BaseHandler) UnstarFolders(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// return unstarred folders
-	util.JsonResponse(w, unstarredFolders)
+	util.JsonResponse(w, newFolderResponses(unstarredFolders))
 }
 
 // TOGGLE FOLDER STARRED
@@ -728,8 +678,14 @@ func (h *BaseHandler) ToggleFolderStarred(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
 	q := sqlc.New(h.db)
 
+	if _, ok := authorizeFolders(w, r.Context(), q, req.FolderIDs, payload.AccountID, middleware.RoleEdit); !ok {
+		return
+	}
+
 	var foldersStarred []sqlc.Folder
 
 	for _, folderID := range req.FolderIDs {
@@ -742,7 +698,7 @@ func (h *BaseHandler) ToggleFolderStarred(w http.ResponseWriter, r *http.Request
 		foldersStarred = append(foldersStarred, folderStarred)
 	}
 
-	util.JsonResponse(w, foldersStarred)
+	util.JsonResponse(w, newFolderResponses(foldersStarred))
 }
 
 // RENAME FOLDER
@@ -812,29 +768,7 @@ func (h *BaseHandler) RenameFolder(w http.ResponseWriter, r *http.Request) {
 
 	q := sqlc.New(h.db)
 
-	folder, err := q.GetFolder(r.Context(), req.FolderID)
-	if err != nil {
-		var pgErr *pgconn.PgError
-
-		switch {
-		case errors.As(err, &pgErr):
-			log.Println(pgErr)
-			util.Response(w, internalServerError, http.StatusInternalServerError)
-			return
-		case errors.Is(err, sql.ErrNoRows):
-			log.Println("folder to rename not found")
-			util.Response(w, sql.ErrNoRows.Error(), http.StatusNotFound)
-			return
-		default:
-			log.Println(err)
-			util.Response(w, internalServerError, http.StatusInternalServerError)
-			return
-		}
-	}
-
-	if folder.AccountID != payload.AccountID {
-		log.Println("user is unauthorized")
-		util.Response(w, "unauthorized", http.StatusUnauthorized)
+	if _, ok := authorizeFolder(w, r.Context(), q, req.FolderID, payload.AccountID, middleware.RoleEdit); !ok {
 		return
 	}
 
@@ -911,35 +845,14 @@ func (h *BaseHandler) MoveFoldersToTrash(w http.ResponseWriter, r *http.Request)
 
 	q := sqlc.New(h.db)
 
-	var trashedFolders []sqlc.Folder
-
-	for _, folderID := range req.FolderIDs {
-		folder, err := q.GetFolder(r.Context(), folderID)
-		if err != nil {
-			var pgErr *pgconn.PgError
-
-			switch {
-			case errors.As(err, &pgErr):
-				log.Println(pgErr)
-				util.Response(w, internalServerError, http.StatusInternalServerError)
-				return
-			case errors.Is(err, sql.ErrNoRows):
-				log.Println("folder not found")
-				util.Response(w, "folder not found", http.StatusNotFound)
-				return
-			default:
-				log.Println(err)
-				util.Response(w, internalServerError, http.StatusInternalServerError)
-				return
-			}
-		}
+	folders, ok := authorizeFolders(w, r.Context(), q, req.FolderIDs, payload.AccountID, middleware.RoleEdit)
+	if !ok {
+		return
+	}
 
-		if folder.AccountID != payload.AccountID {
-			log.Println("user unauthorized to delete this folder")
-			util.Response(w, "unauthorized", http.StatusUnauthorized)
-			return
-		}
+	var trashedFolders []sqlc.Folder
 
+	for _, folder := range folders {
 		trashedFolder, err := q.MoveFolderToTrash(r.Context(), folder.FolderID)
 		if err != nil {
 			var pgErr *pgconn.PgError
@@ -958,7 +871,7 @@ func (h *BaseHandler) MoveFoldersToTrash(w http.ResponseWriter, r *http.Request)
 		trashedFolders = append(trashedFolders, trashedFolder)
 	}
 
-	util.JsonResponse(w, trashedFolders)
+	util.JsonResponse(w, newFolderResponses(trashedFolders))
 }
 
 func (h *BaseHandler) GetFolder(w http.ResponseWriter, r *http.Request) {
@@ -995,7 +908,7 @@ func (h *BaseHandler) GetFolder(w http.ResponseWriter, r *http.Request) {
 	// 	return
 	// }
 
-	util.JsonResponse(w, folder)
+	util.JsonResponse(w, newFolderResponse(folder))
 }
 
 // MOVE FOLDERS
@@ -1064,61 +977,25 @@ func (h *BaseHandler) MoveFolders(w http.ResponseWriter, r *http.Request) {
 
 	q := sqlc.New(h.db)
 
-	destinationFolder, err := q.GetFolder(r.Context(), req.DestinationFolderID)
-	if err != nil {
-		var pgErr *pgconn.PgError
-
-		switch {
-		case errors.As(err, &pgErr):
-			log.Println(pgErr)
-			util.Response(w, internalServerError, http.StatusInternalServerError)
-			return
-		case errors.Is(err, sql.ErrNoRows):
-			log.Println("folder not found")
-			util.Response(w, "folder not found", http.StatusNotFound)
-			return
-		default:
-			log.Println(err)
-			util.Response(w, internalServerError, http.StatusInternalServerError)
-			return
-		}
+	destinationFolder, ok := authorizeFolder(w, r.Context(), q, req.DestinationFolderID, payload.AccountID, middleware.RoleEdit)
+	if !ok {
+		return
 	}
 
-	if destinationFolder.AccountID != payload.AccountID {
-		log.Println("unauthorized")
-		util.Response(w, "unauthorized", http.StatusUnauthorized)
+	folders, ok := authorizeFolders(w, r.Context(), q, req.FolderIDs, payload.AccountID, middleware.RoleEdit)
+	if !ok {
 		return
 	}
 
-	var foldersMoved []sqlc.Folder
-
-	for _, folder_ID := range req.FolderIDs {
-		folder, err := q.GetFolder(r.Context(), folder_ID)
-		if err != nil {
-			var pgErr *pgconn.PgError
-
-			switch {
-			case errors.As(err, &pgErr):
-				log.Println(pgErr)
-				util.Response(w, internalServerError, http.StatusInternalServerError)
-				return
-			case errors.Is(err, sql.ErrNoRows):
-				log.Println("folder not found")
-				util.Response(w, "folder not found", http.StatusNotFound)
-				return
-			default:
-				log.Println(err)
-				util.Response(w, internalServerError, http.StatusInternalServerError)
-				return
-			}
-		}
-
-		if folder.AccountID != payload.AccountID {
-			log.Println("unauthorized")
-			util.Response(w, "unauthorized", http.StatusUnauthorized)
+	for _, folder := range folders {
+		if !authorizeMoveOut(w, r.Context(), q, folder, destinationFolder, payload.AccountID) {
 			return
 		}
+	}
+
+	var foldersMoved []sqlc.Folder
 
+	for _, folder := range folders {
 		arg := sqlc.MoveFolderParams{
 			Label:   destinationFolder.Label,
 			Label_2: folder.Label,
@@ -1180,7 +1057,7 @@ func (h *BaseHandler) MoveFolders(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	util.JsonResponse(w, foldersMoved)
+	util.JsonResponse(w, newFolderResponses(foldersMoved))
 }
 
 // MOVE FOLDERS TO ROOT
@@ -1248,35 +1125,15 @@ func (h *BaseHandler) MoveFoldersToRoot(w http.ResponseWriter, r *http.Request)
 
 	payload := r.Context().Value("payload").(*auth.PayLoad)
 
-	var foldersMovedToRoot []sqlc.Folder
-
-	for _, folderID := range req.FolderIDs {
-		folder, err := q.GetFolder(r.Context(), folderID)
-		if err != nil {
-			var pgErr *pgconn.PgError
-
-			switch {
-			case errors.As(err, &pgErr):
-				log.Println(pgErr)
-				util.Response(w, internalServerError, http.StatusInternalServerError)
-				return
-			case errors.Is(err, sql.ErrNoRows):
-				log.Println("folder not found")
-				util.Response(w, "folder not found", http.StatusNotFound)
-				return
-			default:
-				log.Println(err)
-				util.Response(w, internalServerError, http.StatusInternalServerError)
-				return
-			}
-		}
+	// the root is the mover's own, folders shared with them cannot be taken there
+	folders, ok := authorizeFolders(w, r.Context(), q, req.FolderIDs, payload.AccountID, middleware.RoleOwner)
+	if !ok {
+		return
+	}
 
-		if folder.AccountID != payload.AccountID {
-			log.Println("unauthorized")
-			util.Response(w, "unauthorized", http.StatusUnauthorized)
-			return
-		}
+	var foldersMovedToRoot []sqlc.Folder
 
+	for _, folder := range folders {
 		arg := sqlc.MoveFoldersToRootParams{
 			Label:   folder.Label,
 			Label_2: folder.Label,
@@ -1314,7 +1171,7 @@ func (h *BaseHandler) MoveFoldersToRoot(w http.ResponseWriter, r *http.Request)
 		foldersMovedToRoot = append(foldersMovedToRoot, folderMovedToRoot...)
 	}
 
-	util.JsonResponse(w, foldersMovedToRoot)
+	util.JsonResponse(w, newFolderResponses(foldersMovedToRoot))
 }
 
 type restoreFoldersRequest struct {
@@ -1361,8 +1218,14 @@ func (h *BaseHandler) RestoreFoldersFromTrash(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
 	q := sqlc.New(h.db)
 
+	if _, ok := authorizeFolders(w, r.Context(), q, req.FolderIDS, payload.AccountID, middleware.RoleEdit); !ok {
+		return
+	}
+
 	var folders []sqlc.Folder
 
 	for _, folderID := range req.FolderIDS {
@@ -1384,7 +1247,7 @@ func (h *BaseHandler) RestoreFoldersFromTrash(w http.ResponseWriter, r *http.Req
 		folders = append(folders, f)
 	}
 
-	util.JsonResponse(w, folders)
+	util.JsonResponse(w, newFolderResponses(folders))
 }
 
 type deleteFoldersForeverRequest struct {
@@ -1431,8 +1294,14 @@ func (h *BaseHandler) DeleteFoldersForever(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
 	q := sqlc.New(h.db)
 
+	if _, ok := authorizeFolders(w, r.Context(), q, req.FolderIDS, payload.AccountID, middleware.RoleEdit); !ok {
+		return
+	}
+
 	var folders []sqlc.Folder
 
 	for _, folderID := range req.FolderIDS {
@@ -1454,5 +1323,5 @@ func (h *BaseHandler) DeleteFoldersForever(w http.ResponseWriter, r *http.Reques
 		folders = append(folders, f...)
 	}
 
-	util.JsonResponse(w, folders)
+	util.JsonResponse(w, newFolderResponses(folders))
 }
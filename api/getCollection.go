@@ -35,5 +35,5 @@ func (h *BaseHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.JsonResponse(w, folder)
+	util.JsonResponse(w, newFolderResponse(folder))
 }
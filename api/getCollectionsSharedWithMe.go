@@ -39,7 +39,7 @@ func (h *BaseHandler) GetCollectionsSharedWithMe(w http.ResponseWriter, r *http.
 	}
 
 	// declared reponse
-	var collectionsSharedWithMeResponse []sqlc.Folder
+	var collectionsSharedWithMeResponse []folderResponse
 
 	for _, collectionMemberInstance := range collectionsMemberInstances {
 		collection, err := q.GetFolder(r.Context(), collectionMemberInstance.CollectionID)
@@ -65,7 +65,7 @@ func (h *BaseHandler) GetCollectionsSharedWithMe(w http.ResponseWriter, r *http.
 		}
 
 		// append collection to slice  of collections to return via json response
-		collectionsSharedWithMeResponse = append(collectionsSharedWithMeResponse, collection)
+		collectionsSharedWithMeResponse = append(collectionsSharedWithMeResponse, newFolderResponse(collection))
 	}
 
 	util.JsonResponse(w, collectionsSharedWithMeResponse)
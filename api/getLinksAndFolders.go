@@ -15,13 +15,13 @@ import (
 
 type getLinksAndFoldersResponse struct {
 	Folders []returnFolder `json:"folders"`
-	Links   []sqlc.Link    `json:"links"`
+	Links   []linkResponse `json:"links"`
 }
 
 func newResponse(folders []returnFolder, links []sqlc.Link) *getLinksAndFoldersResponse {
 	return &getLinksAndFoldersResponse{
 		Folders: folders,
-		Links:   links,
+		Links:   newLinkResponses(links),
 	}
 }
 
@@ -36,6 +36,8 @@ type returnFolder struct {
 	FolderUpdatedAt string         `json:"folder_updated_at"`
 	SubfolderOf     sql.NullString `json:"subfolder_of"`
 	FolderDeletedAt sql.NullTime   `json:"folder_deleted_at"`
+	Smart           bool           `json:"smart,omitempty"`
+	Query           string         `json:"query,omitempty"`
 }
 
 func newReturnedFolder(f sqlc.Folder) returnFolder {
@@ -104,6 +106,16 @@ func getRootNodesAndLinks(h *BaseHandler, accountID int64, w http.ResponseWriter
 		rfs = append(rfs, folder)
 	}
 
+	smartFolders, err := q.GetRootSmartFolders(ctx, accountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	for _, f := range smartFolders {
+		rfs = append(rfs, newReturnedSmartFolder(f))
+	}
+
 	links, err := q.GetRootLinks(ctx, accountID)
 	if err != nil {
 		ErrorInternalServerError(w, err)
@@ -141,6 +153,16 @@ func getFolderNodesAndLinks(h *BaseHandler, accountID int64, folderID string, w
 	// 	FolderID:  sql.NullString{String: folderID, Valid: true},
 	// }
 
+	smartFolders, err := q.GetFolderSmartFolders(ctx, sql.NullString{String: folderID, Valid: true})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	for _, f := range smartFolders {
+		rfs = append(rfs, newReturnedSmartFolder(f))
+	}
+
 	links, err := q.GetFolderLinks(ctx, sql.NullString{String: folderID, Valid: true})
 	if err != nil {
 		ErrorInternalServerError(w, err)
@@ -15,14 +15,14 @@ import (
 )
 
 type response struct {
-	Folders []sqlc.Folder `json:"folders"`
-	Links   []sqlc.Link   `json:"links"`
+	Folders []folderResponse `json:"folders"`
+	Links   []linkResponse   `json:"links"`
 }
 
 func newRes(f []sqlc.Folder, l []sqlc.Link) *response {
 	return &response{
-		Folders: f,
-		Links:   l,
+		Folders: newFolderResponses(f),
+		Links:   newLinkResponses(l),
 	}
 }
 
@@ -1,13 +1,31 @@
 package api
 
-import "database/sql"
+import (
+	"database/sql"
+
+	"github.com/kwandapchumba/go-bookmark-manager/oauth"
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
+	"github.com/kwandapchumba/go-bookmark-manager/storage"
+	"github.com/kwandapchumba/go-bookmark-manager/tasks"
+	"github.com/kwandapchumba/go-bookmark-manager/webauthn"
+)
 
 type BaseHandler struct {
-	db *sql.DB
+	db           *sql.DB
+	queue        tasks.Queue
+	store        storage.BlobStore
+	providers    *oauth.Registry
+	relyingParty *webauthn.RelyingParty
+	limiter      *ratelimit.Limiter
 }
 
-func NewBaseHandler(db *sql.DB) *BaseHandler {
+func NewBaseHandler(db *sql.DB, queue tasks.Queue, store storage.BlobStore, providers *oauth.Registry, relyingParty *webauthn.RelyingParty, limiter *ratelimit.Limiter) *BaseHandler {
 	return &BaseHandler{
-		db: db,
+		db:           db,
+		queue:        queue,
+		store:        store,
+		providers:    providers,
+		relyingParty: relyingParty,
+		limiter:      limiter,
 	}
 }
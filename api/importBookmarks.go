@@ -0,0 +1,293 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/middleware"
+	"github.com/kwandapchumba/go-bookmark-manager/tasks"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+const (
+	importStatusCreated = "created"
+	importStatusSkipped = "skipped"
+	importStatusError   = "error"
+)
+
+type importedItem struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	URL    string `json:"url,omitempty"`
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	ID     string `json:"id,omitempty"`
+}
+
+type importBookmarksResponse struct {
+	Created int            `json:"created"`
+	Skipped int            `json:"skipped"`
+	Errors  int            `json:"errors"`
+	Items   []importedItem `json:"items"`
+}
+
+func (i *importBookmarksResponse) add(item importedItem) {
+	switch item.Status {
+	case importStatusCreated:
+		i.Created++
+	case importStatusSkipped:
+		i.Skipped++
+	case importStatusError:
+		i.Errors++
+	}
+
+	i.Items = append(i.Items, item)
+}
+
+type bookmarksImporter struct {
+	q         *sqlc.Queries
+	queue     tasks.Queue
+	ctx       context.Context
+	accountID int64
+	res       *importBookmarksResponse
+}
+
+// ImportBookmarks takes a netscape bookmarks.html upload (form field "bookmarks") and recreates its folders and links
+// under the folder in the "folder_id" form field, or at the root when it is empty or "null"
+func (h *BaseHandler) ImportBookmarks(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		log.Printf("could not parse multipart form at importBookmarks.go: %v", err)
+		util.Response(w, badRequest, http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("bookmarks")
+	if err != nil {
+		log.Printf("could not get bookmarks file at importBookmarks.go: %v", err)
+		util.Response(w, "bookmarks file is required", http.StatusBadRequest)
+		return
+	}
+
+	defer file.Close()
+
+	root, err := util.ParseNetscapeBookmarks(file)
+	if err != nil {
+		log.Printf("could not parse bookmarks file at importBookmarks.go: %v", err)
+		util.Response(w, "invalid bookmarks file", http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	var parent *sqlc.Folder
+
+	if folderID := r.FormValue("folder_id"); folderID != "" && folderID != "null" {
+		folder, ok := authorizeFolder(w, r.Context(), q, folderID, payload.AccountID, middleware.RoleEdit)
+		if !ok {
+			return
+		}
+
+		parent = &folder
+	}
+
+	importer := &bookmarksImporter{
+		q:         q,
+		queue:     h.queue,
+		ctx:       r.Context(),
+		accountID: payload.AccountID,
+		res:       &importBookmarksResponse{Items: []importedItem{}},
+	}
+
+	importer.importFolder(root, parent, "")
+
+	util.JsonResponse(w, importer.res)
+}
+
+func (b *bookmarksImporter) importFolder(f *util.NetscapeFolder, parent *sqlc.Folder, path string) {
+	for _, bookmark := range f.Bookmarks {
+		b.importLink(bookmark, parent, path)
+	}
+
+	for _, child := range f.Folders {
+		name := child.Name
+		if name == "" {
+			name = "Untitled folder"
+		}
+
+		childPath := strings.TrimPrefix(path+"/"+name, "/")
+
+		folder, err := b.createFolder(name, parent)
+		if err != nil {
+			log.Printf("could not create imported folder at importBookmarks.go: %v", err)
+
+			b.res.add(importedItem{Type: "folder", Name: name, Path: childPath, Status: importStatusError, Reason: "could not create folder"})
+
+			b.skipFolder(child, childPath)
+
+			continue
+		}
+
+		b.res.add(importedItem{Type: "folder", Name: name, Path: childPath, Status: importStatusCreated, ID: folder.FolderID})
+
+		b.importFolder(child, &folder, childPath)
+	}
+}
+
+// skipFolder reports everything below a folder that could not be created
+func (b *bookmarksImporter) skipFolder(f *util.NetscapeFolder, path string) {
+	for _, bookmark := range f.Bookmarks {
+		b.res.add(importedItem{Type: "link", Name: bookmark.Title, URL: bookmark.URL, Path: path, Status: importStatusSkipped, Reason: "parent folder could not be created"})
+	}
+
+	for _, child := range f.Folders {
+		childPath := path + "/" + child.Name
+
+		b.res.add(importedItem{Type: "folder", Name: child.Name, Path: childPath, Status: importStatusSkipped, Reason: "parent folder could not be created"})
+
+		b.skipFolder(child, childPath)
+	}
+}
+
+func (b *bookmarksImporter) createFolder(name string, parent *sqlc.Folder) (sqlc.Folder, error) {
+	stringChan := make(chan string, 1)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		util.RandomStringGenerator(stringChan)
+	}()
+
+	folderLabelChan := make(chan string, 1)
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		util.GenFolderLabel(folderLabelChan)
+	}()
+
+	folderID := <-stringChan
+
+	label := <-folderLabelChan
+
+	wg.Wait()
+
+	arg := sqlc.CreateFolderParams{
+		FolderID:   folderID,
+		FolderName: name,
+		AccountID:  b.accountID,
+		Path:       label,
+		Label:      label,
+	}
+
+	if parent != nil {
+		arg.SubfolderOf = sql.NullString{String: parent.FolderID, Valid: true}
+		arg.Path = strings.Join([]string{parent.Path, label}, ".")
+	}
+
+	return b.q.CreateFolder(b.ctx, arg)
+}
+
+func (b *bookmarksImporter) importLink(bookmark *util.NetscapeBookmark, parent *sqlc.Folder, path string) {
+	item := importedItem{Type: "link", Name: bookmark.Title, URL: bookmark.URL, Path: path}
+
+	u, err := url.Parse(bookmark.URL)
+	if err != nil || u.Host == "" {
+		item.Status = importStatusSkipped
+		item.Reason = "invalid url"
+		b.res.add(item)
+		return
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		item.Status = importStatusSkipped
+		item.Reason = fmt.Sprintf("unsupported url scheme %q", u.Scheme)
+		b.res.add(item)
+		return
+	}
+
+	var folderID sql.NullString
+
+	if parent != nil {
+		folderID = sql.NullString{String: parent.FolderID, Valid: true}
+	}
+
+	exists, err := b.q.LinkExistsInFolder(b.ctx, sqlc.LinkExistsInFolderParams{
+		AccountID: b.accountID,
+		LinkUrl:   bookmark.URL,
+		FolderID:  folderID.String,
+	})
+	if err != nil {
+		log.Printf("could not check if imported link exists at importBookmarks.go: %v", err)
+		item.Status = importStatusError
+		item.Reason = "could not save link"
+		b.res.add(item)
+		return
+	}
+
+	if exists {
+		item.Status = importStatusSkipped
+		item.Reason = "link already exists in this folder"
+		b.res.add(item)
+		return
+	}
+
+	title := bookmark.Title
+	if title == "" {
+		title = bookmark.URL
+	}
+
+	addedAt := bookmark.AddDate
+	if addedAt.IsZero() {
+		addedAt = time.Now().UTC()
+	}
+
+	stringChan := make(chan string, 1)
+
+	util.RandomStringGenerator(stringChan)
+
+	link, err := b.q.ImportLink(b.ctx, sqlc.ImportLinkParams{
+		LinkID:        <-stringChan,
+		LinkTitle:     title,
+		LinkHostname:  u.Host,
+		LinkUrl:       bookmark.URL,
+		LinkFavicon:   fmt.Sprintf("https://www.google.com/s2/favicons?domain=%v&sz=64", u.Host),
+		AccountID:     b.accountID,
+		FolderID:      folderID,
+		LinkThumbnail: "",
+		LinkNotes:     bookmark.Notes,
+		AddedAt:       addedAt,
+	})
+	if err != nil {
+		log.Printf("could not insert imported link at importBookmarks.go: %v", err)
+		item.Status = importStatusError
+		item.Reason = "could not save link"
+		b.res.add(item)
+		return
+	}
+
+	if err := tasks.EnqueueLinkPage(b.ctx, b.queue, b.q, link.LinkID); err != nil {
+		log.Printf("could not enqueue link metadata job at importBookmarks.go: %v", err)
+	}
+
+	item.Status = importStatusCreated
+	item.ID = link.LinkID
+	b.res.add(item)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
@@ -16,13 +17,13 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-ozzo/ozzo-validation/is"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
-	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"github.com/jackc/pgconn"
 	"github.com/kwandapchumba/go-bookmark-manager/auth"
 	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/middleware"
+	"github.com/kwandapchumba/go-bookmark-manager/storage"
+	"github.com/kwandapchumba/go-bookmark-manager/tasks"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
-	"github.com/kwandapchumba/go-bookmark-manager/vultr"
 )
 
 func (h *BaseHandler) GetRootLinks(w http.ResponseWriter, r *http.Request) {
@@ -52,7 +53,7 @@ func (h *BaseHandler) GetRootLinks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.JsonResponse(w, links)
+	util.JsonResponse(w, newLinkResponses(links))
 }
 
 type URL struct {
@@ -70,6 +71,8 @@ func (u URL) Validate(requestVaidatinChan chan error) error {
 	return validationError
 }
 
+// AddLink saves the link straight away with a "pending" metadata status, the title, favicon and thumbnail are
+// filled in by the link metadata worker
 func (h *BaseHandler) AddLink(w http.ResponseWriter, r *http.Request) {
 	rBody := json.NewDecoder(r.Body)
 
@@ -100,155 +103,31 @@ func (h *BaseHandler) AddLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var host string
-	var urlToOpen string
-
-	if strings.Contains(req.URL, "?") {
-		u, err := url.ParseRequestURI(req.URL)
-		if err != nil {
-			ErrorInternalServerError(w, err)
-			return
-		}
-
-		if u.Scheme == "https" {
-
-			host = u.Host
-
-			urlToOpen = fmt.Sprintf(`%v`, u)
-		} else {
-			util.Response(w, "invalid url", http.StatusBadRequest)
-			return
-		}
-
-	} else {
-		parsedUrl, err := url.Parse(req.URL)
-		if err != nil {
-			ErrorInternalServerError(w, err)
+	host, err := linkHostname(req.URL)
+	if err != nil {
+		if errors.Is(err, errInvalidURL) {
+			util.Response(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		if parsedUrl.Scheme == "https" {
-			host = parsedUrl.Host
-
-			urlToOpen = req.URL
-		} else {
-			host = parsedUrl.String()
-
-			urlToOpen = fmt.Sprintf(`https://%s`, req.URL)
-		}
-
-	}
-
-	resp, err := http.Get(fmt.Sprintf("https://www.google.com/s2/favicons?domain=%v&sz=64", req.URL))
-	if err != nil {
-		util.Response(w, "something went wrong", http.StatusInternalServerError)
+		ErrorInternalServerError(w, err)
 		return
 	}
 
-	var favicon string
-
-	if err := util.DownloaFavicon(resp.Header.Get("content-location"), "favicon.ico"); err != nil {
-		favicon = resp.Header.Get("content-location")
-	}
-
-	if favicon == "" {
-
-		urlFaviconChan := make(chan string, 1)
-
-		wg.Add(1)
-
-		go func() {
-			defer wg.Done()
-
-			vultr.UploadLinkFavicon(urlFaviconChan)
-
-			// util.UploadFavicon(urlFaviconChan)
-		}()
-
-		favicon = <-urlFaviconChan
-	}
-
-	u := launcher.New().UserDataDir("~/.config/google-chrome").Leakless(true).NoSandbox(true).Headless(true).
-		MustLaunch()
-
-	browser := rod.New().ControlURL(u).MustConnect()
-
-	page := browser.MustPage(urlToOpen).MustWaitLoad()
-
-	defer browser.MustClose()
-
-	var urlTitle string
-
-	urlTitleChan := make(chan string, 1)
-
-	urlHeadingChan := make(chan string, 1)
-
-	wg.Add(1)
-
-	go func() {
-		defer wg.Done()
-
-		util.GetUrlTitle(page, urlTitleChan)
-	}()
-
-	wg.Add(1)
-
-	go func() {
-		defer wg.Done()
-
-		util.GetUrlHeading(page, urlHeadingChan)
-	}()
-
-	title := strings.TrimSpace(<-urlTitleChan)
-
-	heading := strings.TrimSpace(<-urlHeadingChan)
-
-	if title != "" {
-		if heading != "" {
-			if len(heading) > len(title) {
-				urlTitle = heading
-			} else {
-				urlTitle = title
-			}
-		} else {
-			urlTitle = title
-		}
-	} else {
-		if heading != "" {
-			urlTitle = heading
-		} else {
-			urlTitle = req.URL
-		}
-	}
-
 	payload := r.Context().Value("payload").(*auth.PayLoad)
 
+	q := sqlc.New(h.db)
+
 	var folderID sql.NullString
 
 	if req.FolderID != "" {
+		if _, ok := authorizeFolder(w, r.Context(), q, req.FolderID, payload.AccountID, middleware.RoleEdit); !ok {
+			return
+		}
+
 		folderID = sql.NullString{String: req.FolderID, Valid: true}
 	}
 
-	util.RodGetUrlScreenshot(page)
-
-	urlScreenshotChan := make(chan string, 1)
-
-	wg.Add(1)
-
-	go func() {
-		defer wg.Done()
-
-		vultr.UploadLinkThumbnail(urlScreenshotChan)
-
-		// if err := util.UploadScreenshot(urlScreenshotChan); err != nil {
-		// 	log.Printf("failed to get url screenshot: %v", err)
-		// 	util.Response(w, "something went wrong", http.StatusInternalServerError)
-		// 	return
-		// }
-	}()
-
-	urlScreenshotLink := <-urlScreenshotChan
-
 	stringChan := make(chan string, 1)
 
 	wg.Add(1)
@@ -263,28 +142,60 @@ func (h *BaseHandler) AddLink(w http.ResponseWriter, r *http.Request) {
 
 	addLinkParams := sqlc.AddLinkParams{
 		LinkID:        linkID,
-		LinkTitle:     urlTitle,
+		LinkTitle:     req.URL,
 		LinkHostname:  host,
 		LinkUrl:       req.URL,
-		LinkFavicon:   favicon,
+		LinkFavicon:   fmt.Sprintf("https://www.google.com/s2/favicons?domain=%v&sz=64", req.URL),
 		AccountID:     payload.AccountID,
 		FolderID:      folderID,
-		LinkThumbnail: urlScreenshotLink,
+		LinkThumbnail: "",
 	}
 
-	q := sqlc.New(h.db)
-
 	link, err := q.AddLink(r.Context(), addLinkParams)
 	if err != nil {
 		ErrorInternalServerError(w, err)
 		return
 	}
 
-	util.JsonResponse(w, link)
+	if err := tasks.EnqueueLinkPage(r.Context(), h.queue, q, link.LinkID); err != nil {
+		log.Printf("could not enqueue link metadata job at link.go: %v", err)
+	}
+
+	util.JsonResponse(w, newLinkResponse(link))
 
 	wg.Wait()
 }
 
+var errInvalidURL = errors.New("invalid url")
+
+// linkHostname is what a link is listed under, the host of https urls and the whole url otherwise. Urls with a query
+// string have to be https.
+func linkHostname(rawURL string) (string, error) {
+	if strings.Contains(rawURL, "?") {
+		u, err := url.ParseRequestURI(rawURL)
+		if err != nil {
+			return "", err
+		}
+
+		if u.Scheme != "https" {
+			return "", errInvalidURL
+		}
+
+		return u.Host, nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme == "https" {
+		return u.Host, nil
+	}
+
+	return u.String(), nil
+}
+
 type renameLinkRequest struct {
 	LinkTitle string `json:"link_title"`
 	LinkID    string `json:"link_id"`
@@ -333,8 +244,14 @@ func (h *BaseHandler) RenameLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
 	q := sqlc.New(h.db)
 
+	if _, ok := authorizeLink(w, r.Context(), q, req.LinkID, payload.AccountID, middleware.RoleEdit); !ok {
+		return
+	}
+
 	renameLinkParams := sqlc.RenameLinkParams{
 		LinkTitle: req.LinkTitle,
 		LinkID:    req.LinkID,
@@ -346,7 +263,195 @@ func (h *BaseHandler) RenameLink(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.JsonResponse(w, link)
+	util.JsonResponse(w, newLinkResponse(link))
+}
+
+const (
+	// notes are markdown, kept to what fits comfortably in the notes panel
+	maxLinkNotesLength = 10000
+	maxThumbnailSize   = 5 << 20
+)
+
+type updateLinkRequest struct {
+	LinkTitle *string `json:"link_title"`
+	LinkNotes *string `json:"link_notes"`
+	LinkURL   *string `json:"link_url"`
+}
+
+func (u updateLinkRequest) validate() error {
+	return validation.ValidateStruct(&u,
+		validation.Field(&u.LinkTitle, validation.NilOrNotEmpty.Error("link title cannot be empty")),
+		validation.Field(&u.LinkNotes, validation.RuneLength(0, maxLinkNotesLength).Error(fmt.Sprintf("notes cannot be longer than %d characters", maxLinkNotesLength))),
+		validation.Field(&u.LinkURL, validation.NilOrNotEmpty.Error("url cannot be empty"), is.URL.Error("url must be a valid url")),
+	)
+}
+
+// UpdateLink changes the title, notes and url of a link, fields left out of the request are kept. A new url is fetched
+// again by the link metadata worker, its title follows the new page unless one is given with it.
+func (h *BaseHandler) UpdateLink(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req updateLinkRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.LinkTitle == nil && req.LinkNotes == nil && req.LinkURL == nil {
+		util.Response(w, "nothing to update", http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	link, ok := authorizeLink(w, r.Context(), q, chi.URLParam(r, "linkID"), payload.AccountID, middleware.RoleEdit)
+	if !ok {
+		return
+	}
+
+	params := sqlc.UpdateLinkParams{
+		LinkTitle:      link.LinkTitle,
+		LinkNotes:      link.LinkNotes,
+		LinkUrl:        link.LinkUrl,
+		LinkHostname:   link.LinkHostname,
+		MetadataStatus: link.MetadataStatus,
+		LinkID:         link.LinkID,
+	}
+
+	urlChanged := req.LinkURL != nil && strings.TrimSpace(*req.LinkURL) != link.LinkUrl
+
+	if urlChanged {
+		params.LinkUrl = strings.TrimSpace(*req.LinkURL)
+
+		host, err := linkHostname(params.LinkUrl)
+		if err != nil {
+			if errors.Is(err, errInvalidURL) {
+				util.Response(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			ErrorInternalServerError(w, err)
+			return
+		}
+
+		params.LinkHostname = host
+
+		// the metadata worker only replaces titles that are the url
+		params.LinkTitle = params.LinkUrl
+		params.MetadataStatus = sqlc.LinkMetadataStatusPending
+	}
+
+	if req.LinkTitle != nil {
+		params.LinkTitle = strings.TrimSpace(*req.LinkTitle)
+	}
+
+	if req.LinkNotes != nil {
+		params.LinkNotes = *req.LinkNotes
+	}
+
+	link, err := q.UpdateLink(r.Context(), params)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if urlChanged {
+		if err := tasks.EnqueueLinkPage(r.Context(), h.queue, q, link.LinkID); err != nil {
+			log.Printf("could not enqueue link metadata job at link.go: %v", err)
+		}
+	}
+
+	util.JsonResponse(w, newLinkResponse(link))
+}
+
+// SetLinkThumbnail replaces the thumbnail of a link with the image uploaded as "thumbnail". Changing the url of the
+// link later brings back a screenshot of the new page.
+func (h *BaseHandler) SetLinkThumbnail(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxThumbnailSize+1<<10)
+
+	if err := r.ParseMultipartForm(maxThumbnailSize); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, fmt.Sprintf("thumbnail must be an image of at most %d MB", maxThumbnailSize>>20), http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("thumbnail")
+	if err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, "thumbnail is required", http.StatusBadRequest)
+		return
+	}
+
+	defer file.Close()
+
+	// the content type sent by the browser is not trusted, it is served back to everyone viewing the link
+	head := make([]byte, 512)
+
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		util.Response(w, "thumbnail must be an image", http.StatusBadRequest)
+		return
+	}
+
+	contentType := http.DetectContentType(head[:n])
+
+	if !strings.HasPrefix(contentType, "image/") {
+		util.Response(w, "thumbnail must be an image", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	link, ok := authorizeLink(w, r.Context(), q, chi.URLParam(r, "linkID"), payload.AccountID, middleware.RoleEdit)
+	if !ok {
+		return
+	}
+
+	key := storage.NewKey(storage.LinkThumbnails)
+
+	if err := h.store.Put(r.Context(), key, file, contentType); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	updated, err := q.SetLinkThumbnail(r.Context(), sqlc.SetLinkThumbnailParams{
+		LinkThumbnail: h.store.URL(key),
+		LinkID:        link.LinkID,
+	})
+	if err != nil {
+		if err := h.store.Delete(r.Context(), key); err != nil {
+			log.Printf("could not delete blob %s at link.go: %v", key, err)
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if old, ok := storage.KeyFromURL(h.store, link.LinkThumbnail); ok {
+		if err := h.store.Delete(r.Context(), old); err != nil {
+			log.Printf("could not delete blob %s at link.go: %v", old, err)
+		}
+	}
+
+	util.JsonResponse(w, newLinkResponse(updated))
 }
 
 type moveLinksRequest struct {
@@ -397,11 +502,45 @@ func (h *BaseHandler) MoveLinks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
 	q := sqlc.New(h.db)
 
 	if req.FolerID == "" {
+		// the root is the mover's own, links shared with them cannot be taken there
+		if _, ok := authorizeLinks(w, r.Context(), q, req.Links, payload.AccountID, middleware.RoleOwner); !ok {
+			return
+		}
+
 		moveLinksToRoot(q, req.Links, w, r.Context())
 	} else {
+		links, ok := authorizeLinks(w, r.Context(), q, req.Links, payload.AccountID, middleware.RoleEdit)
+		if !ok {
+			return
+		}
+
+		destination, ok := authorizeFolder(w, r.Context(), q, req.FolerID, payload.AccountID, middleware.RoleEdit)
+		if !ok {
+			return
+		}
+
+		for _, link := range links {
+			// links at the root are already the mover's own
+			if !link.FolderID.Valid {
+				continue
+			}
+
+			source, err := q.GetFolder(r.Context(), link.FolderID.String)
+			if err != nil {
+				ErrorInternalServerError(w, err)
+				return
+			}
+
+			if !authorizeMoveOut(w, r.Context(), q, source, destination, payload.AccountID) {
+				return
+			}
+		}
+
 		moveLinksToFolder(q, req.Links, req.FolerID, w, r.Context())
 	}
 }
@@ -419,7 +558,7 @@ func moveLinksToRoot(q *sqlc.Queries, links []string, w http.ResponseWriter, ctx
 		linksMoved = append(linksMoved, link)
 	}
 
-	util.JsonResponse(w, linksMoved)
+	util.JsonResponse(w, newLinkResponses(linksMoved))
 }
 
 func moveLinksToFolder(q *sqlc.Queries, links []string, folderID string, w http.ResponseWriter, ctx context.Context) {
@@ -439,7 +578,7 @@ func moveLinksToFolder(q *sqlc.Queries, links []string, folderID string, w http.
 		linksMoved = append(linksMoved, link)
 	}
 
-	util.JsonResponse(w, linksMoved)
+	util.JsonResponse(w, newLinkResponses(linksMoved))
 }
 
 type moveLinksToTrashRequest struct {
@@ -488,8 +627,14 @@ func (h *BaseHandler) MoveLinksToTrash(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
 	q := sqlc.New(h.db)
 
+	if _, ok := authorizeLinks(w, r.Context(), q, req.LinkIDS, payload.AccountID, middleware.RoleEdit); !ok {
+		return
+	}
+
 	var trashedLinks []sqlc.Link
 
 	for _, linkID := range req.LinkIDS {
@@ -502,7 +647,7 @@ func (h *BaseHandler) MoveLinksToTrash(w http.ResponseWriter, r *http.Request) {
 		trashedLinks = append(trashedLinks, link)
 	}
 
-	util.JsonResponse(w, trashedLinks)
+	util.JsonResponse(w, newLinkResponses(trashedLinks))
 }
 
 func (h *BaseHandler) GetFolderLinks(w http.ResponseWriter, r *http.Request) {
@@ -547,7 +692,7 @@ func (h *BaseHandler) GetFolderLinks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	util.JsonResponse(w, links)
+	util.JsonResponse(w, newLinkResponses(links))
 }
 
 type restoreLinksRequest struct {
@@ -594,8 +739,14 @@ func (h *BaseHandler) RestoreLinksFromTrash(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
 	q := sqlc.New(h.db)
 
+	if _, ok := authorizeLinks(w, r.Context(), q, req.LinkIDS, payload.AccountID, middleware.RoleEdit); !ok {
+		return
+	}
+
 	var links []sqlc.Link
 
 	for _, linkID := range req.LinkIDS {
@@ -617,7 +768,7 @@ func (h *BaseHandler) RestoreLinksFromTrash(w http.ResponseWriter, r *http.Reque
 		links = append(links, l)
 	}
 
-	util.JsonResponse(w, links)
+	util.JsonResponse(w, newLinkResponses(links))
 }
 
 type deleteLinksForeverRequest struct {
@@ -664,45 +815,25 @@ func (h *BaseHandler) DeleteLinksForever(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
 	q := sqlc.New(h.db)
 
+	linksToDelete, ok := authorizeLinks(w, r.Context(), q, req.LinkIDS, payload.AccountID, middleware.RoleEdit)
+	if !ok {
+		return
+	}
+
 	var links []sqlc.Link
 
-	for _, linkID := range req.LinkIDS {
-		// get link
-		link, err := q.GetLink(r.Context(), linkID)
-		if err != nil {
-			log.Println(err)
-			util.Response(w, "something went wrong", http.StatusInternalServerError)
+	for _, link := range linksToDelete {
+		// read before the link is deleted, its archive goes with it
+		linkArchive, err := q.GetLinkArchive(r.Context(), link.LinkID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			ErrorInternalServerError(w, err)
 			return
 		}
 
-		tn := strings.Split(link.LinkThumbnail, "/")
-
-		key := (tn[len(tn)-1])
-
-		// log.Println(key)
-
-		// linkScreenshotKey := strings.Split(link.LinkThumbnail, "/")[4]
-
-		// linkFaviconKey := strings.Split(link.LinkFavicon, "/")[4]
-
-		vultr.DeleteObjectFromBucket("/link-thumbnails", key)
-
-		vultr.DeleteObjectFromBucket("/link-favicons", key)
-
-		// if err := util.DeleteFileFromBucket("/screenshots", linkScreenshotKey); err != nil {
-		// 	log.Printf("could not delete screenshot from spaces %v", err)
-		// 	util.Response(w, "something went wrong", http.StatusInternalServerError)
-		// 	return
-		// }
-
-		// if err := util.DeleteFileFromBucket("/favicons", linkFaviconKey); err != nil {
-		// 	log.Printf("could not delete favicon from spaces %v", err)
-		// 	util.Response(w, "something went wrong", http.StatusInternalServerError)
-		// 	return
-		// }
-
 		l, err := q.DeleteLinkForever(r.Context(), link.LinkID)
 		if err != nil {
 			var pgErr *pgconn.PgError
@@ -718,34 +849,28 @@ func (h *BaseHandler) DeleteLinksForever(w http.ResponseWriter, r *http.Request)
 			}
 		}
 
-		links = append(links, l)
-	}
-
-	util.JsonResponse(w, links)
-}
-
-func (h *BaseHandler) GetLinksByUserID(w http.ResponseWriter, r *http.Request) {
-	accountIDString := chi.URLParam(r, "accountID")
+		// blobs only go once the link is gone, a link that could not be deleted keeps all of its own
+		keys := []string{linkArchive.SnapshotKey, linkArchive.ArticleKey}
 
-	accountID, err := strconv.ParseInt(accountIDString, 10, 64)
-	if err != nil {
-		log.Printf("could parse string to int64: %v", err)
-		util.Response(w, "something went wrong", http.StatusInternalServerError)
-		return
-	}
+		// favicons google could not give us are linked to rather than stored, those urls are not ours to delete
+		for _, blobURL := range []string{l.LinkThumbnail, l.LinkFavicon} {
+			if key, ok := storage.KeyFromURL(h.store, blobURL); ok {
+				keys = append(keys, key)
+			}
+		}
 
-	q := sqlc.New(h.db)
+		for _, key := range keys {
+			if key == "" {
+				continue
+			}
 
-	links, err := q.GetLinksByUserID(r.Context(), accountID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			util.Response(w, "no links found", http.StatusNotFound)
-		} else {
-			log.Printf("could not get links by user id: %v", err)
-			util.Response(w, "something went wrong", http.StatusInternalServerError)
-			return
+			if err := h.store.Delete(r.Context(), key); err != nil {
+				log.Printf("could not delete blob %s at link.go: %v", key, err)
+			}
 		}
+
+		links = append(links, l)
 	}
 
-	util.JsonResponse(w, links)
+	util.JsonResponse(w, newLinkResponses(links))
 }
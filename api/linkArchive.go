@@ -0,0 +1,141 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/middleware"
+	"github.com/kwandapchumba/go-bookmark-manager/storage"
+	"github.com/kwandapchumba/go-bookmark-manager/tasks"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// archiveRetryAfter is how long a pending archive blocks archiving the link again, jobs lost when the in-process
+// queue restarts leave theirs pending for good
+const archiveRetryAfter = 10 * time.Minute
+
+// snapshotPolicy keeps snapshots from running scripts or loading anything, they are served from the api's origin
+const snapshotPolicy = "sandbox; default-src 'none'; img-src data:; style-src 'unsafe-inline' data:; font-src data:; media-src data:"
+
+// authorizeLinkArchive loads the archive of a link the user has at least role min on
+func authorizeLinkArchive(w http.ResponseWriter, r *http.Request, q *sqlc.Queries, min middleware.Role) (sqlc.LinkArchive, bool) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	link, ok := authorizeLink(w, r.Context(), q, chi.URLParam(r, "linkID"), payload.AccountID, min)
+	if !ok {
+		return sqlc.LinkArchive{}, false
+	}
+
+	linkArchive, err := q.GetLinkArchive(r.Context(), link.LinkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "link has not been archived", http.StatusNotFound)
+			return sqlc.LinkArchive{}, false
+		}
+
+		ErrorInternalServerError(w, err)
+		return sqlc.LinkArchive{}, false
+	}
+
+	return linkArchive, true
+}
+
+// GetLinkArchive tells whether a link has been archived and when
+func (h *BaseHandler) GetLinkArchive(w http.ResponseWriter, r *http.Request) {
+	linkArchive, ok := authorizeLinkArchive(w, r, sqlc.New(h.db), middleware.RoleView)
+	if !ok {
+		return
+	}
+
+	util.JsonResponse(w, newLinkArchiveResponse(linkArchive))
+}
+
+// ArchiveLink archives a link again, or for the first time when it was added before links were archived
+func (h *BaseHandler) ArchiveLink(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	link, ok := authorizeLink(w, r.Context(), q, chi.URLParam(r, "linkID"), payload.AccountID, middleware.RoleEdit)
+	if !ok {
+		return
+	}
+
+	linkArchive, err := q.GetLinkArchive(r.Context(), link.LinkID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err == nil && linkArchive.ArchiveStatus == sqlc.LinkArchiveStatusPending && time.Since(linkArchive.RequestedAt) < archiveRetryAfter {
+		util.Response(w, "link is already being archived", http.StatusConflict)
+		return
+	}
+
+	linkArchive, err = tasks.RequestLinkArchive(r.Context(), h.queue, q, link.LinkID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newLinkArchiveResponse(linkArchive))
+}
+
+// GetLinkArchiveSnapshot serves the page as it was when the link was last archived
+func (h *BaseHandler) GetLinkArchiveSnapshot(w http.ResponseWriter, r *http.Request) {
+	linkArchive, ok := authorizeLinkArchive(w, r, sqlc.New(h.db), middleware.RoleView)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Security-Policy", snapshotPolicy)
+
+	h.serveArchiveBlob(w, r, linkArchive.SnapshotKey, "text/html; charset=utf-8")
+}
+
+// GetLinkArchiveArticle serves the text of the article of the page, in paragraphs separated by blank lines
+func (h *BaseHandler) GetLinkArchiveArticle(w http.ResponseWriter, r *http.Request) {
+	linkArchive, ok := authorizeLinkArchive(w, r, sqlc.New(h.db), middleware.RoleView)
+	if !ok {
+		return
+	}
+
+	h.serveArchiveBlob(w, r, linkArchive.ArticleKey, "text/plain; charset=utf-8")
+}
+
+func (h *BaseHandler) serveArchiveBlob(w http.ResponseWriter, r *http.Request, key, contentType string) {
+	// the archive is requested but no snapshot has been taken yet
+	if key == "" {
+		util.Response(w, "link has not been archived yet", http.StatusNotFound)
+		return
+	}
+
+	blob, err := h.store.Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			util.Response(w, "archive not found", http.StatusNotFound)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	defer blob.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-cache")
+
+	// the headers are gone once the blob is being written, errors from here on can only be logged
+	if _, err := io.Copy(w, blob); err != nil {
+		log.Printf("could not write archive at linkArchive.go: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
+)
+
+// lockedOut answers with 429 and returns true while key is locked out after too many failed sign ins
+func (h *BaseHandler) lockedOut(w http.ResponseWriter, ctx context.Context, key string) bool {
+	wait, err := h.limiter.Locked(ctx, key)
+	if err != nil {
+		log.Printf("could not check lockout at lockout.go: %v", err)
+		return false
+	}
+
+	if wait > 0 {
+		ratelimit.TooManyRequests(w, wait)
+		return true
+	}
+
+	return false
+}
+
+// signInFailed counts a failed sign in against key, enough of them lock it out for a while
+func (h *BaseHandler) signInFailed(ctx context.Context, key string) {
+	if err := h.limiter.Fail(ctx, key); err != nil {
+		log.Printf("could not count failed sign in at lockout.go: %v", err)
+	}
+}
+
+func (h *BaseHandler) signInSucceeded(ctx context.Context, key string) {
+	if err := h.limiter.Succeed(ctx, key); err != nil {
+		log.Printf("could not reset failed sign ins at lockout.go: %v", err)
+	}
+}
@@ -7,13 +7,13 @@ import (
 	"log"
 	"net/http"
 	"sync"
-	"time"
 
 	"github.com/go-ozzo/ozzo-validation/is"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/jackc/pgconn"
 	"github.com/kwandapchumba/go-bookmark-manager/auth"
 	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
@@ -92,6 +92,7 @@ func (h *BaseHandler) SignIn(w http.ResponseWriter, r *http.Request) {
 			return
 		} else if errors.Is(err, sql.ErrNoRows) {
 			log.Println("email not found")
+			h.signInFailed(r.Context(), ratelimit.EmailKey(req.Email))
 			util.Response(w, "invalid email", http.StatusUnauthorized)
 			return
 		} else {
@@ -103,67 +104,29 @@ func (h *BaseHandler) SignIn(w http.ResponseWriter, r *http.Request) {
 
 	if !util.CompareHash(req.Password, account.AccountPassword) {
 		log.Println("invalid password")
+		h.signInFailed(r.Context(), ratelimit.EmailKey(req.Email))
 		util.Response(w, "invalid password", http.StatusUnauthorized)
 		return
 	}
 
-	config, err := util.LoadConfig(".")
-	if err != nil {
-		log.Printf("failed to load config file with err: %v", err)
-		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
-		return
-	}
+	h.signInSucceeded(r.Context(), ratelimit.EmailKey(req.Email))
 
-	accessToken, accessTokenPayload, err := auth.CreateToken(account.ID, time.Now().UTC(), config.Access_Token_Duration)
-	if err != nil {
-		log.Printf("failed to create access token with err: %v", err)
+	if required, err := requireTwoFactor(w, r.Context(), q, account.ID); err != nil {
+		log.Printf("could not check two factor authentication at login.go: %v", err)
 		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
 		return
+	} else if required {
+		return
 	}
 
-	refreshToken, refreshTokenPayload, err := auth.CreateToken(account.ID, accessTokenPayload.IssuedAt, config.Refresh_Token_Duration)
+	accessToken, refreshToken, refreshTokenCookie, err := auth.LoginUser(r, q, account)
 	if err != nil {
-		log.Printf("failed to create refresh token with err: %v", err)
-		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
+		ErrorStartingSession(w, err)
 		return
 	}
 
-	refreshTokenCookie := http.Cookie{
-		Name:     "refreshTokenCookie",
-		Value:    refreshToken,
-		Path:     "/",
-		Expires:  refreshTokenPayload.Expiry,
-		Secure:   true,
-		SameSite: http.SameSite(http.SameSiteStrictMode),
-		HttpOnly: true,
-	}
-
 	http.SetCookie(w, &refreshTokenCookie)
 
-	createAccountSessionParams := sqlc.CreateAccountSessionParams{
-		RefreshTokenID: refreshTokenPayload.ID,
-		AccountID:      account.ID,
-		IssuedAt:       refreshTokenPayload.IssuedAt,
-		Expiry:         refreshTokenPayload.Expiry,
-		UserAgent:      "",
-		ClientIp:       "",
-	}
-
-	_, err = q.CreateAccountSession(r.Context(), createAccountSessionParams)
-	if err != nil {
-		var pgErr *pgconn.PgError
-
-		if errors.As(err, &pgErr) {
-			log.Printf("failed co create session with err: %s", pgErr.Message)
-			util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
-			return
-		} else {
-			log.Printf("failed to create session with error: %s", err.Error())
-			util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-
 	account, err = q.GetAccount(r.Context(), account.ID)
 	if err != nil {
 		var pgErr *pgconn.PgError
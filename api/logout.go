@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// Logout ends the session the request was made with, its access and refresh tokens stop working straight away
+func (h *BaseHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	if _, err := q.DeleteAccountSession(r.Context(), sqlc.DeleteAccountSessionParams{
+		SessionID: payload.SessionID,
+		AccountID: payload.AccountID,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	auth.ClearRefreshTokenCookie(w)
+
+	util.Response(w, "logged out", http.StatusOK)
+}
@@ -0,0 +1,462 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgconn"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/oauth"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// users have this long to finish signing in with a provider
+const oauthStateDuration = 10 * time.Minute
+
+// oauthStateCookie holds a hash of the state of the sign in the browser started, a callback with a state the browser
+// did not start is someone else's sign in being finished in it
+const oauthStateCookie = "oauthStateCookie"
+
+var (
+	errEmailNotVerified = errors.New("provider did not share a verified email address")
+	// the account already has a different identity from the same provider linked
+	errIdentityTaken = errors.New("account is linked to another identity from this provider")
+	// the identity is linked to some other account
+	errIdentityInUse = errors.New("identity is linked to another account")
+)
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
+
+// accountForIdentity finds the account an identity is linked to. The first time an identity signs in it is linked to
+// the account with the same email, or to a new account, but only when the provider has verified the email.
+func (h *BaseHandler) accountForIdentity(ctx context.Context, identity *oauth.Identity) (sqlc.Account, error) {
+	q := sqlc.New(h.db)
+
+	account, err := q.GetAccountByIdentity(ctx, sqlc.GetAccountByIdentityParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+	if err == nil || !errors.Is(err, sql.ErrNoRows) {
+		return account, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return sqlc.Account{}, errEmailNotVerified
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return sqlc.Account{}, err
+	}
+
+	defer tx.Rollback()
+
+	qtx := q.WithTx(tx)
+
+	account, err = qtx.GetAccountByEmail(ctx, identity.Email)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		name := identity.Name
+		if name == "" {
+			name = strings.Split(identity.Email, "@")[0]
+		}
+
+		account, err = qtx.NewVerifiedAccount(ctx, sqlc.NewVerifiedAccountParams{
+			Fullname: name,
+			Email:    identity.Email,
+			Picture:  identity.Picture,
+		})
+		if err != nil {
+			return sqlc.Account{}, err
+		}
+	case err != nil:
+		return sqlc.Account{}, err
+	case !account.EmailVerified:
+		// whoever signed up with the email never proved it was theirs, it may not be the person the provider verified
+		// it for. The password they chose and any session they have go so only the provider's user gets in.
+		if err := qtx.UpdatePassword(ctx, sqlc.UpdatePasswordParams{AccountPassword: "", ID: account.ID}); err != nil {
+			return sqlc.Account{}, err
+		}
+
+		if err := qtx.DeleteAccountSessions(ctx, account.ID); err != nil {
+			return sqlc.Account{}, err
+		}
+
+		// the provider has verified the email so the account it belongs to has been verified too
+		if err := qtx.UpdateAccountEmailVerificationStatus(ctx, account.Email); err != nil {
+			return sqlc.Account{}, err
+		}
+
+		account.AccountPassword = ""
+		account.EmailVerified = true
+	}
+
+	if _, err := qtx.CreateAccountIdentity(ctx, sqlc.CreateAccountIdentityParams{
+		AccountID:     account.ID,
+		Provider:      identity.Provider,
+		Subject:       identity.Subject,
+		IdentityEmail: identity.Email,
+	}); err != nil {
+		if isUniqueViolation(err, "one_identity_per_provider") {
+			return sqlc.Account{}, errIdentityTaken
+		}
+
+		return sqlc.Account{}, err
+	}
+
+	return account, tx.Commit()
+}
+
+// linkIdentity links an identity to an account that is already signed in
+func linkIdentity(ctx context.Context, q *sqlc.Queries, accountID int64, identity *oauth.Identity) error {
+	existing, err := q.GetAccountByIdentity(ctx, sqlc.GetAccountByIdentityParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+	if err == nil {
+		if existing.ID == accountID {
+			return nil
+		}
+
+		return errIdentityInUse
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if _, err := q.CreateAccountIdentity(ctx, sqlc.CreateAccountIdentityParams{
+		AccountID:     accountID,
+		Provider:      identity.Provider,
+		Subject:       identity.Subject,
+		IdentityEmail: identity.Email,
+	}); err != nil {
+		switch {
+		case isUniqueViolation(err, "one_identity_per_provider"):
+			return errIdentityTaken
+		case isUniqueViolation(err, "identity_subject_must_be_unique"):
+			return errIdentityInUse
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func oauthCallbackURL(r *http.Request, provider string) string {
+	base := "http://" + r.Host
+
+	if config, err := util.LoadConfig("."); err == nil && config.APIURL != "" {
+		base = strings.TrimSuffix(config.APIURL, "/")
+	}
+
+	return base + "/public/oauth/" + provider + "/callback"
+}
+
+// redirectToFrontend ends every callback, the frontend reads the outcome from the query
+func redirectToFrontend(w http.ResponseWriter, r *http.Request, query url.Values) {
+	base := "http://localhost:5173"
+
+	if config, err := util.LoadConfig("."); err == nil && config.FrontendURL != "" {
+		base = strings.TrimSuffix(config.FrontendURL, "/")
+	}
+
+	http.Redirect(w, r, base+"/accounts/oauth_callback?"+query.Encode(), http.StatusFound)
+}
+
+func hashOAuthState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// setOAuthStateCookie is lax so the browser sends it along when the provider redirects back
+func setOAuthStateCookie(w http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    value,
+		Path:     "/public/oauth",
+		MaxAge:   maxAge,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		HttpOnly: true,
+	})
+}
+
+// oauthStateStartedHere tells whether the browser the callback came to is the one that started the sign in
+func oauthStateStartedHere(r *http.Request, state string) bool {
+	c, err := r.Cookie(oauthStateCookie)
+	if err != nil || state == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(c.Value), []byte(hashOAuthState(state))) == 1
+}
+
+// startOAuth saves the state, nonce and PKCE verifier of a new sign in, binds the state to the browser with a cookie
+// and returns the provider url to send the user to
+func startOAuth(w http.ResponseWriter, r *http.Request, q *sqlc.Queries, provider oauth.Provider, linkAccountID sql.NullInt64) (string, error) {
+	if err := q.DeleteExpiredOauthStates(r.Context()); err != nil {
+		log.Printf("could not delete expired oauth states at oauth.go: %v", err)
+	}
+
+	var tokens [3]string
+
+	for i := range tokens {
+		token, err := oauth.RandomToken()
+		if err != nil {
+			return "", err
+		}
+
+		tokens[i] = token
+	}
+
+	state, err := q.CreateOauthState(r.Context(), sqlc.CreateOauthStateParams{
+		State:         tokens[0],
+		Provider:      provider.Name(),
+		Nonce:         tokens[1],
+		CodeVerifier:  tokens[2],
+		LinkAccountID: linkAccountID,
+		StateExpiry:   time.Now().UTC().Add(oauthStateDuration),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	setOAuthStateCookie(w, hashOAuthState(state.State), int(oauthStateDuration.Seconds()))
+
+	return provider.AuthCodeURL(r.Context(), oauth.AuthRequest{
+		State:        state.State,
+		Nonce:        state.Nonce,
+		CodeVerifier: state.CodeVerifier,
+		RedirectURI:  oauthCallbackURL(r, provider.Name()),
+	})
+}
+
+func (h *BaseHandler) GetOAuthProviders(w http.ResponseWriter, r *http.Request) {
+	util.JsonResponse(w, h.providers.Names())
+}
+
+// OAuthLogin sends the browser off to sign in with a provider
+func (h *BaseHandler) OAuthLogin(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.providers.Get(chi.URLParam(r, "provider"))
+	if !ok {
+		util.Response(w, "unknown provider", http.StatusNotFound)
+		return
+	}
+
+	authURL, err := startOAuth(w, r, sqlc.New(h.db), provider, sql.NullInt64{})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OAuthCallback is where providers send the browser back to. Signing in sets the refresh token cookie, the frontend
+// then gets an access token from /public/refreshToken so no session tokens ever end up in a url. Accounts with two
+// factor authentication get a challenge token instead, which needs a code to be worth anything.
+func (h *BaseHandler) OAuthCallback(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "provider")
+
+	provider, ok := h.providers.Get(name)
+	if !ok {
+		util.Response(w, "unknown provider", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+
+	if e := query.Get("error"); e != "" {
+		log.Printf("%s sign in was not completed at oauth.go: %s", name, e)
+		redirectToFrontend(w, r, url.Values{"provider": {name}, "error": {"access_denied"}})
+		return
+	}
+
+	startedHere := oauthStateStartedHere(r, query.Get("state"))
+
+	// the state is good for one callback whatever happens to this one
+	setOAuthStateCookie(w, "", -1)
+
+	if !startedHere {
+		redirectToFrontend(w, r, url.Values{"provider": {name}, "error": {"invalid_state"}})
+		return
+	}
+
+	q := sqlc.New(h.db)
+
+	// states can only be used once, so the same callback cannot be replayed
+	state, err := q.ConsumeOauthState(r.Context(), query.Get("state"))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("could not get oauth state at oauth.go: %v", err)
+		}
+
+		redirectToFrontend(w, r, url.Values{"provider": {name}, "error": {"invalid_state"}})
+		return
+	}
+
+	if state.Provider != name || !time.Now().UTC().Before(state.StateExpiry) {
+		redirectToFrontend(w, r, url.Values{"provider": {name}, "error": {"invalid_state"}})
+		return
+	}
+
+	identity, err := provider.Exchange(r.Context(), query.Get("code"), oauth.AuthRequest{
+		State:        state.State,
+		Nonce:        state.Nonce,
+		CodeVerifier: state.CodeVerifier,
+		RedirectURI:  oauthCallbackURL(r, name),
+	})
+	if err != nil {
+		log.Printf("could not exchange %s code at oauth.go: %v", name, err)
+		redirectToFrontend(w, r, url.Values{"provider": {name}, "error": {"sign_in_failed"}})
+		return
+	}
+
+	if state.LinkAccountID.Valid {
+		if err := linkIdentity(r.Context(), q, state.LinkAccountID.Int64, identity); err != nil {
+			redirectToFrontend(w, r, url.Values{"provider": {name}, "error": {oauthErrorCode(err)}})
+			return
+		}
+
+		redirectToFrontend(w, r, url.Values{"provider": {name}, "linked": {"true"}})
+		return
+	}
+
+	account, err := h.accountForIdentity(r.Context(), identity)
+	if err != nil {
+		redirectToFrontend(w, r, url.Values{"provider": {name}, "error": {oauthErrorCode(err)}})
+		return
+	}
+
+	// the frontend finishes signing in at VerifyTwoFactorSignIn, the challenge token is no good without a code
+	challenge, err := newTwoFactorChallenge(r.Context(), q, account.ID)
+	if err != nil {
+		log.Printf("could not check two factor authentication at oauth.go: %v", err)
+		redirectToFrontend(w, r, url.Values{"provider": {name}, "error": {"sign_in_failed"}})
+		return
+	}
+
+	if challenge != nil {
+		redirectToFrontend(w, r, url.Values{"provider": {name}, "two_factor_required": {"true"}, "challenge_token": {challenge.ChallengeToken}})
+		return
+	}
+
+	_, _, refreshTokenCookie, err := auth.LoginUser(r, q, account)
+	if err != nil {
+		log.Printf("could not create session at oauth.go: %v", err)
+
+		code := "sign_in_failed"
+		if errors.Is(err, auth.ErrAccountSuspended) {
+			code = "account_suspended"
+		}
+
+		redirectToFrontend(w, r, url.Values{"provider": {name}, "error": {code}})
+		return
+	}
+
+	http.SetCookie(w, &refreshTokenCookie)
+
+	redirectToFrontend(w, r, url.Values{"provider": {name}})
+}
+
+func oauthErrorCode(err error) string {
+	switch {
+	case errors.Is(err, errEmailNotVerified):
+		return "email_not_verified"
+	case errors.Is(err, errIdentityTaken):
+		return "provider_already_linked"
+	case errors.Is(err, errIdentityInUse):
+		return "identity_in_use"
+	default:
+		log.Printf("could not sign in with identity at oauth.go: %v", err)
+		return "sign_in_failed"
+	}
+}
+
+// LinkOAuthProvider starts linking a provider to the signed in account. The frontend sends the browser to the url in
+// the response, it is not a redirect because the request carries the access token. It has to be made with credentials
+// for the browser to keep the state cookie.
+func (h *BaseHandler) LinkOAuthProvider(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	provider, ok := h.providers.Get(chi.URLParam(r, "provider"))
+	if !ok {
+		util.Response(w, "unknown provider", http.StatusNotFound)
+		return
+	}
+
+	authURL, err := startOAuth(w, r, sqlc.New(h.db), provider, sql.NullInt64{Int64: payload.AccountID, Valid: true})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, map[string]string{"url": authURL})
+}
+
+type accountIdentity struct {
+	Provider string    `json:"provider"`
+	Email    string    `json:"email"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+func (h *BaseHandler) GetAccountIdentities(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	identities, err := sqlc.New(h.db).GetAccountIdentities(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	res := []accountIdentity{}
+
+	for _, identity := range identities {
+		res = append(res, accountIdentity{
+			Provider: identity.Provider,
+			Email:    identity.IdentityEmail,
+			LinkedAt: identity.LinkedAt,
+		})
+	}
+
+	util.JsonResponse(w, res)
+}
+
+// UnlinkOAuthProvider cannot lock anyone out, every account can still sign in with a code sent to its email
+func (h *BaseHandler) UnlinkOAuthProvider(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	n, err := sqlc.New(h.db).DeleteAccountIdentity(r.Context(), sqlc.DeleteAccountIdentityParams{
+		AccountID: payload.AccountID,
+		Provider:  chi.URLParam(r, "provider"),
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if n == 0 {
+		util.Response(w, "provider is not linked", http.StatusNotFound)
+		return
+	}
+
+	util.Response(w, "provider unlinked", http.StatusOK)
+}
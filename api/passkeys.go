@@ -0,0 +1,372 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+	"github.com/kwandapchumba/go-bookmark-manager/webauthn"
+)
+
+const (
+	ceremonyRegistration   = "registration"
+	ceremonyAuthentication = "authentication"
+)
+
+var errPasskeyChallenge = errors.New("passkey challenge not found or expired")
+
+type passkey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func newPasskey(c sqlc.WebauthnCredential) passkey {
+	p := passkey{
+		ID:        c.CredentialID,
+		Name:      c.CredentialName,
+		CreatedAt: c.CredentialCreatedAt,
+	}
+
+	if c.LastUsedAt.Valid {
+		p.LastUsedAt = &c.LastUsedAt.Time
+	}
+
+	return p
+}
+
+// startCeremony saves the challenge for a new registration or sign in
+func startCeremony(ctx context.Context, q *sqlc.Queries, ceremony string, accountID sql.NullInt64) (string, error) {
+	if err := q.DeleteExpiredWebauthnChallenges(ctx); err != nil {
+		log.Printf("could not delete expired webauthn challenges at passkeys.go: %v", err)
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := q.CreateWebauthnChallenge(ctx, sqlc.CreateWebauthnChallengeParams{
+		Challenge:       challenge,
+		Ceremony:        ceremony,
+		AccountID:       accountID,
+		ChallengeExpiry: time.Now().UTC().Add(webauthn.CeremonyTimeout),
+	}); err != nil {
+		return "", err
+	}
+
+	return challenge, nil
+}
+
+// finishCeremony uses up the challenge a response was signed for, each one only works once
+func finishCeremony(ctx context.Context, q *sqlc.Queries, ceremony, clientDataJSON string) (sqlc.WebauthnChallenge, error) {
+	challenge, err := webauthn.Challenge(clientDataJSON)
+	if err != nil {
+		return sqlc.WebauthnChallenge{}, err
+	}
+
+	stored, err := q.ConsumeWebauthnChallenge(ctx, sqlc.ConsumeWebauthnChallengeParams{
+		Challenge: challenge,
+		Ceremony:  ceremony,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sqlc.WebauthnChallenge{}, errPasskeyChallenge
+		}
+
+		return sqlc.WebauthnChallenge{}, err
+	}
+
+	if time.Now().After(stored.ChallengeExpiry) {
+		return sqlc.WebauthnChallenge{}, errPasskeyChallenge
+	}
+
+	return stored, nil
+}
+
+// ceremonyFailed answers a response that did not verify, anything else is our fault
+func ceremonyFailed(w http.ResponseWriter, err error) {
+	if errors.Is(err, errPasskeyChallenge) || errors.Is(err, webauthn.ErrInvalidResponse) || errors.Is(err, webauthn.ErrSignCount) {
+		log.Printf("passkey ceremony failed at passkeys.go: %v", err)
+		util.Response(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	ErrorInternalServerError(w, err)
+}
+
+type passkeyName struct {
+	Name string `json:"name"`
+}
+
+func (p passkeyName) validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Name, validation.Required.Error("name is required"), validation.Length(1, 100).Error("name must be at most 100 characters long")),
+	)
+}
+
+// BeginPasskeyRegistration returns the options the frontend passes to navigator.credentials.create
+func (h *BaseHandler) BeginPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	account, err := q.GetAccount(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	credentials, err := q.GetWebauthnCredentials(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	// the browser refuses to register an authenticator that already has a passkey on the account
+	exclude := []string{}
+
+	for _, credential := range credentials {
+		exclude = append(exclude, credential.CredentialID)
+	}
+
+	challenge, err := startCeremony(r.Context(), q, ceremonyRegistration, sql.NullInt64{Int64: payload.AccountID, Valid: true})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, h.relyingParty.CreationOptions(challenge, account.ID, account.Email, account.Fullname, exclude))
+}
+
+type finishPasskeyRegistration struct {
+	Name       string                        `json:"name"`
+	Credential webauthn.RegistrationResponse `json:"credential"`
+}
+
+// FinishPasskeyRegistration stores the passkey navigator.credentials.create made
+func (h *BaseHandler) FinishPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	var req finishPasskeyRegistration
+
+	// the credential is passed on as the browser serialized it, which can carry fields we have no use for
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if req.Name == "" {
+		req.Name = "Passkey"
+	}
+
+	if err := (passkeyName{Name: req.Name}).validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	stored, err := finishCeremony(r.Context(), q, ceremonyRegistration, req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		ceremonyFailed(w, err)
+		return
+	}
+
+	if stored.AccountID.Int64 != payload.AccountID {
+		ceremonyFailed(w, errPasskeyChallenge)
+		return
+	}
+
+	credential, err := h.relyingParty.VerifyRegistration(req.Credential, stored.Challenge)
+	if err != nil {
+		ceremonyFailed(w, err)
+		return
+	}
+
+	created, err := q.CreateWebauthnCredential(r.Context(), sqlc.CreateWebauthnCredentialParams{
+		CredentialID:   credential.ID,
+		AccountID:      payload.AccountID,
+		PublicKey:      credential.PublicKey,
+		SignCount:      int64(credential.SignCount),
+		CredentialName: req.Name,
+	})
+	if err != nil {
+		if isUniqueViolation(err, "webauthn_credential_pkey") {
+			util.Response(w, "passkey is already registered", http.StatusConflict)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newPasskey(created))
+}
+
+// BeginPasskeySignIn returns the options the frontend passes to navigator.credentials.get
+func (h *BaseHandler) BeginPasskeySignIn(w http.ResponseWriter, r *http.Request) {
+	challenge, err := startCeremony(r.Context(), sqlc.New(h.db), ceremonyAuthentication, sql.NullInt64{})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, h.relyingParty.RequestOptions(challenge))
+}
+
+type finishPasskeySignIn struct {
+	Credential webauthn.AssertionResponse `json:"credential"`
+}
+
+// FinishPasskeySignIn signs in with a passkey. The authenticator verified the user itself, so there is no two factor
+// challenge after it.
+func (h *BaseHandler) FinishPasskeySignIn(w http.ResponseWriter, r *http.Request) {
+	var req finishPasskeySignIn
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	q := sqlc.New(h.db)
+
+	stored, err := finishCeremony(r.Context(), q, ceremonyAuthentication, req.Credential.Response.ClientDataJSON)
+	if err != nil {
+		ceremonyFailed(w, err)
+		return
+	}
+
+	credential, err := q.GetWebauthnCredential(r.Context(), req.Credential.RawID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "passkey not found", http.StatusUnauthorized)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if req.Credential.Response.UserHandle != "" {
+		accountID, err := webauthn.AccountID(req.Credential.Response.UserHandle)
+		if err != nil || accountID != credential.AccountID {
+			ceremonyFailed(w, webauthn.ErrInvalidResponse)
+			return
+		}
+	}
+
+	signCount, err := h.relyingParty.VerifyAssertion(req.Credential, stored.Challenge, webauthn.Credential{
+		ID:        credential.CredentialID,
+		PublicKey: credential.PublicKey,
+		SignCount: uint32(credential.SignCount),
+	})
+	if err != nil {
+		ceremonyFailed(w, err)
+		return
+	}
+
+	if err := q.UseWebauthnCredential(r.Context(), sqlc.UseWebauthnCredentialParams{
+		SignCount:    int64(signCount),
+		CredentialID: credential.CredentialID,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	account, err := q.GetAccount(r.Context(), credential.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	// no two factor challenge, the passkey was verified with the user's biometrics or pin on top of being possessed
+	loginUser(account, w, h, r)
+}
+
+func (h *BaseHandler) GetPasskeys(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	credentials, err := sqlc.New(h.db).GetWebauthnCredentials(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	res := []passkey{}
+
+	for _, credential := range credentials {
+		res = append(res, newPasskey(credential))
+	}
+
+	util.JsonResponse(w, res)
+}
+
+func (h *BaseHandler) RenamePasskey(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req passkeyName
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	credential, err := sqlc.New(h.db).RenameWebauthnCredential(r.Context(), sqlc.RenameWebauthnCredentialParams{
+		CredentialName: req.Name,
+		CredentialID:   chi.URLParam(r, "credentialID"),
+		AccountID:      payload.AccountID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "passkey not found", http.StatusNotFound)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newPasskey(credential))
+}
+
+func (h *BaseHandler) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	n, err := sqlc.New(h.db).DeleteWebauthnCredential(r.Context(), sqlc.DeleteWebauthnCredentialParams{
+		CredentialID: chi.URLParam(r, "credentialID"),
+		AccountID:    payload.AccountID,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if n == 0 {
+		util.Response(w, "passkey not found", http.StatusNotFound)
+		return
+	}
+
+	util.Response(w, "passkey removed", http.StatusOK)
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// personalAccessToken leaves out the token hash, the token itself is only returned once by CreatePersonalAccessToken
+type personalAccessToken struct {
+	TokenID     int64      `json:"token_id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func newPersonalAccessToken(t sqlc.PersonalAccessToken) personalAccessToken {
+	res := personalAccessToken{
+		TokenID:     t.TokenID,
+		Name:        t.TokenName,
+		TokenPrefix: t.TokenPrefix,
+		Scopes:      strings.Split(t.Scopes, ","),
+		CreatedAt:   t.TokenCreatedAt,
+	}
+
+	if t.ExpiresAt.Valid {
+		res.ExpiresAt = &t.ExpiresAt.Time
+	}
+
+	if t.LastUsedAt.Valid {
+		res.LastUsedAt = &t.LastUsedAt.Time
+	}
+
+	return res
+}
+
+type createPersonalAccessToken struct {
+	Name   string     `json:"name"`
+	Scopes []string   `json:"scopes"`
+	Expiry *time.Time `json:"expiry"`
+}
+
+func (c createPersonalAccessToken) validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Name, validation.Required.Error("name is required"), validation.Length(1, 100).Error("name must be at most 100 characters long")),
+		validation.Field(&c.Scopes, validation.Required.Error("at least one scope is required"), validation.Each(validation.By(func(value interface{}) error {
+			if !auth.ValidScope(value.(string)) {
+				return errors.New("scope must be one of " + strings.Join(auth.Scopes, ", "))
+			}
+
+			return nil
+		}))),
+		validation.Field(&c.Expiry, validation.By(func(value interface{}) error {
+			if expiry := value.(*time.Time); expiry != nil && !expiry.After(time.Now()) {
+				return errors.New("expiry must be in the future")
+			}
+
+			return nil
+		})),
+	)
+}
+
+type createdPersonalAccessToken struct {
+	Token string `json:"token"`
+	personalAccessToken
+}
+
+func (h *BaseHandler) CreatePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req createPersonalAccessToken
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	token, prefix, err := auth.NewPersonalAccessToken()
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	var expiry sql.NullTime
+	if req.Expiry != nil {
+		expiry = sql.NullTime{Time: req.Expiry.UTC(), Valid: true}
+	}
+
+	created, err := sqlc.New(h.db).CreatePersonalAccessToken(r.Context(), sqlc.CreatePersonalAccessTokenParams{
+		AccountID:   payload.AccountID,
+		TokenName:   req.Name,
+		TokenHash:   auth.HashPersonalAccessToken(token),
+		TokenPrefix: prefix,
+		Scopes:      strings.Join(req.Scopes, ","),
+		ExpiresAt:   expiry,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, createdPersonalAccessToken{
+		Token:               token,
+		personalAccessToken: newPersonalAccessToken(created),
+	})
+}
+
+func (h *BaseHandler) GetPersonalAccessTokens(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	tokens, err := sqlc.New(h.db).GetPersonalAccessTokens(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	res := []personalAccessToken{}
+
+	for _, token := range tokens {
+		res = append(res, newPersonalAccessToken(token))
+	}
+
+	util.JsonResponse(w, res)
+}
+
+// RevokePersonalAccessToken deletes a token, requests made with it fail straight away
+func (h *BaseHandler) RevokePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := strconv.ParseInt(chi.URLParam(r, "tokenID"), 10, 64)
+	if err != nil {
+		util.Response(w, "invalid token id", http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	n, err := sqlc.New(h.db).DeletePersonalAccessToken(r.Context(), sqlc.DeletePersonalAccessTokenParams{
+		TokenID:   tokenID,
+		AccountID: payload.AccountID,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if n == 0 {
+		util.Response(w, "token not found", http.StatusNotFound)
+		return
+	}
+
+	util.Response(w, "token revoked", http.StatusOK)
+}
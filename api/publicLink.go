@@ -0,0 +1,415 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/middleware"
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// visitors have to unlock password protected links again after this long
+const shareAccessTokenDuration = time.Hour
+
+// publicLink leaves out the password hash
+type publicLink struct {
+	ShareToken   string     `json:"share_token"`
+	CollectionID string     `json:"collection_id"`
+	HasPassword  bool       `json:"has_password"`
+	SharedAt     time.Time  `json:"shared_at"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Expired      bool       `json:"expired"`
+}
+
+func newPublicLink(p sqlc.PublicSharedCollection) publicLink {
+	link := publicLink{
+		ShareToken:   p.ShareToken,
+		CollectionID: p.CollectionID,
+		HasPassword:  p.CollectionPassword.Valid,
+		SharedAt:     p.CollectionSharedAt,
+		Expired:      publicLinkExpired(p),
+	}
+
+	if p.CollectionShareExpiry.Valid {
+		link.ExpiresAt = &p.CollectionShareExpiry.Time
+	}
+
+	return link
+}
+
+func publicLinkExpired(p sqlc.PublicSharedCollection) bool {
+	return p.CollectionShareExpiry.Valid && !time.Now().UTC().Before(p.CollectionShareExpiry.Time)
+}
+
+type createPublicLinkRequest struct {
+	FolderID  string     `json:"folder_id"`
+	Password  string     `json:"password"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (c createPublicLinkRequest) validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.FolderID, validation.Required.Error("folder id is required")),
+		// bcrypt ignores everything after 72 bytes
+		validation.Field(&c.Password, validation.Length(6, 72).Error("password must be between 6 and 72 characters long")),
+		validation.Field(&c.ExpiresAt, validation.By(func(value interface{}) error {
+			if c.ExpiresAt != nil && !c.ExpiresAt.After(time.Now()) {
+				return errors.New("expiry must be in the future")
+			}
+			return nil
+		})),
+	)
+}
+
+func (h *BaseHandler) CreatePublicLink(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req createPublicLinkRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	folder, ok := authorizeFolder(w, r.Context(), q, req.FolderID, payload.AccountID, middleware.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	if folder.FolderDeletedAt.Valid {
+		util.Response(w, "folder is in trash", http.StatusBadRequest)
+		return
+	}
+
+	arg := sqlc.ShareCollectionWithPublicLinkParams{
+		ShareToken:         uuid.NewString(),
+		CollectionID:       folder.FolderID,
+		CollectionSharedBy: payload.AccountID,
+	}
+
+	if req.Password != "" {
+		hashedPassword, err := util.HashPassword(req.Password)
+		if err != nil {
+			log.Printf("could not hash public link password at publicLink.go: %v", err)
+			util.Response(w, internalServerError, http.StatusInternalServerError)
+			return
+		}
+
+		arg.CollectionPassword = sql.NullString{String: hashedPassword, Valid: true}
+	}
+
+	if req.ExpiresAt != nil {
+		arg.CollectionShareExpiry = sql.NullTime{Time: req.ExpiresAt.UTC(), Valid: true}
+	}
+
+	link, err := q.ShareCollectionWithPublicLink(r.Context(), arg)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newPublicLink(link))
+}
+
+func (h *BaseHandler) GetPublicLinks(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	folder, ok := authorizeFolder(w, r.Context(), q, chi.URLParam(r, "folderID"), payload.AccountID, middleware.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	links, err := q.GetPublicSharedCollectionsByCollectionID(r.Context(), folder.FolderID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	res := []publicLink{}
+
+	for _, link := range links {
+		res = append(res, newPublicLink(link))
+	}
+
+	util.JsonResponse(w, res)
+}
+
+func (h *BaseHandler) RevokePublicLink(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	link, err := q.GetPublicSharedCollection(r.Context(), chi.URLParam(r, "shareToken"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "link not found", http.StatusNotFound)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if _, ok := authorizeFolder(w, r.Context(), q, link.CollectionID, payload.AccountID, middleware.RoleAdmin); !ok {
+		return
+	}
+
+	if err := q.RevokePublicSharedCollection(r.Context(), link.ShareToken); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newPublicLink(link))
+}
+
+// getActivePublicLink writes the error response itself and returns false when the link is unknown, expired or its
+// collection is in the trash
+func getActivePublicLink(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, shareToken string) (sqlc.PublicSharedCollection, sqlc.Folder, bool) {
+	link, err := q.GetPublicSharedCollection(ctx, shareToken)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "link not found", http.StatusNotFound)
+			return sqlc.PublicSharedCollection{}, sqlc.Folder{}, false
+		}
+
+		ErrorInternalServerError(w, err)
+		return sqlc.PublicSharedCollection{}, sqlc.Folder{}, false
+	}
+
+	if publicLinkExpired(link) {
+		util.Response(w, "link has expired", http.StatusGone)
+		return sqlc.PublicSharedCollection{}, sqlc.Folder{}, false
+	}
+
+	collection, err := q.GetFolder(ctx, link.CollectionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "link not found", http.StatusNotFound)
+			return sqlc.PublicSharedCollection{}, sqlc.Folder{}, false
+		}
+
+		ErrorInternalServerError(w, err)
+		return sqlc.PublicSharedCollection{}, sqlc.Folder{}, false
+	}
+
+	if collection.FolderDeletedAt.Valid {
+		util.Response(w, "link not found", http.StatusNotFound)
+		return sqlc.PublicSharedCollection{}, sqlc.Folder{}, false
+	}
+
+	return link, collection, true
+}
+
+// visitorUnlockedLink checks the bearer token handed out by UnlockPublicLink when the link has a password
+func visitorUnlockedLink(w http.ResponseWriter, r *http.Request, link sqlc.PublicSharedCollection) bool {
+	if !link.CollectionPassword.Valid {
+		return true
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("authorization"), "Bearer"))
+	if token == "" {
+		util.Response(w, "password required", http.StatusUnauthorized)
+		return false
+	}
+
+	payload, err := auth.VerifyShareAccessToken(token)
+	if err != nil || payload.ShareToken != link.ShareToken {
+		log.Printf("invalid share access token: %v", err)
+		util.Response(w, "password required", http.StatusUnauthorized)
+		return false
+	}
+
+	return true
+}
+
+type sharedCollectionInfo struct {
+	CollectionName string     `json:"collection_name"`
+	HasPassword    bool       `json:"has_password"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+// GetSharedCollectionInfo tells visitors what they are about to open and whether they need a password for it
+func (h *BaseHandler) GetSharedCollectionInfo(w http.ResponseWriter, r *http.Request) {
+	q := sqlc.New(h.db)
+
+	link, collection, ok := getActivePublicLink(w, r.Context(), q, chi.URLParam(r, "shareToken"))
+	if !ok {
+		return
+	}
+
+	util.JsonResponse(w, sharedCollectionInfo{
+		CollectionName: collection.FolderName,
+		HasPassword:    link.CollectionPassword.Valid,
+		ExpiresAt:      newPublicLink(link).ExpiresAt,
+	})
+}
+
+type unlockPublicLinkRequest struct {
+	Password string `json:"password"`
+}
+
+type unlockPublicLinkResponse struct {
+	AccessToken string    `json:"access_token"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+func (h *BaseHandler) UnlockPublicLink(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req unlockPublicLinkRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	q := sqlc.New(h.db)
+
+	link, _, ok := getActivePublicLink(w, r.Context(), q, chi.URLParam(r, "shareToken"))
+	if !ok {
+		return
+	}
+
+	if link.CollectionPassword.Valid {
+		// guesses are counted per link so spreading them over addresses does not help
+		key := ratelimit.ShareTokenKey(link.ShareToken)
+
+		if h.lockedOut(w, r.Context(), key) {
+			return
+		}
+
+		if !util.CompareHash(req.Password, link.CollectionPassword.String) {
+			h.signInFailed(r.Context(), key)
+			util.Response(w, "wrong password", http.StatusUnauthorized)
+			return
+		}
+
+		h.signInSucceeded(r.Context(), key)
+	}
+
+	expiry := time.Now().UTC().Add(shareAccessTokenDuration)
+
+	if link.CollectionShareExpiry.Valid && link.CollectionShareExpiry.Time.Before(expiry) {
+		expiry = link.CollectionShareExpiry.Time
+	}
+
+	accessToken, payload, err := auth.CreateShareAccessToken(link.ShareToken, expiry)
+	if err != nil {
+		log.Printf("could not create share access token at publicLink.go: %v", err)
+		util.Response(w, internalServerError, http.StatusInternalServerError)
+		return
+	}
+
+	util.JsonResponse(w, unlockPublicLinkResponse{
+		AccessToken: accessToken,
+		Expiry:      payload.Expiry,
+	})
+}
+
+// GetSharedLinksAndFolders lists a folder of a public link, folderID "null" is the shared collection itself
+func (h *BaseHandler) GetSharedLinksAndFolders(w http.ResponseWriter, r *http.Request) {
+	q := sqlc.New(h.db)
+
+	link, collection, ok := getActivePublicLink(w, r.Context(), q, chi.URLParam(r, "shareToken"))
+	if !ok {
+		return
+	}
+
+	if !visitorUnlockedLink(w, r, link) {
+		return
+	}
+
+	folder := collection
+
+	if folderID := chi.URLParam(r, "folderID"); folderID != "null" && folderID != collection.FolderID {
+		var ok bool
+
+		folder, ok = getSharedSubfolder(w, r.Context(), q, collection, folderID)
+		if !ok {
+			return
+		}
+	}
+
+	folders, err := q.GetFolderNodes(r.Context(), sql.NullString{String: folder.FolderID, Valid: true})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	rfs := []returnFolder{}
+
+	for _, f := range folders {
+		rfs = append(rfs, newReturnedFolder(f))
+	}
+
+	links, err := q.GetFolderLinks(r.Context(), sql.NullString{String: folder.FolderID, Valid: true})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newResponse(rfs, links))
+}
+
+// getSharedSubfolder only returns folders below the shared collection that are not in the trash themselves or through
+// one of their parents
+func getSharedSubfolder(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, collection sqlc.Folder, folderID string) (sqlc.Folder, bool) {
+	folder, err := q.GetFolder(ctx, folderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "folder not found", http.StatusNotFound)
+			return sqlc.Folder{}, false
+		}
+
+		ErrorInternalServerError(w, err)
+		return sqlc.Folder{}, false
+	}
+
+	if !strings.HasPrefix(folder.Path, collection.Path+".") {
+		util.Response(w, "folder not found", http.StatusNotFound)
+		return sqlc.Folder{}, false
+	}
+
+	ancestors, err := q.GetFolderAncestors(ctx, folder.Label)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return sqlc.Folder{}, false
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor.FolderDeletedAt.Valid && strings.HasPrefix(ancestor.Path, collection.Path+".") {
+			util.Response(w, "folder not found", http.StatusNotFound)
+			return sqlc.Folder{}, false
+		}
+	}
+
+	return folder, true
+}
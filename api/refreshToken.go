@@ -5,7 +5,6 @@ import (
 	"errors"
 	"log"
 	"net/http"
-	"time"
 
 	"github.com/jackc/pgconn"
 	"github.com/kwandapchumba/go-bookmark-manager/auth"
@@ -21,7 +20,7 @@ func (h *BaseHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	payload, err := auth.VerifyToken(c.Value)
+	payload, err := auth.VerifyToken(c.Value, auth.RefreshToken)
 	if err != nil {
 		util.Response(w, err.Error(), http.StatusUnauthorized)
 		return
@@ -40,50 +39,35 @@ func (h *BaseHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	config, err := util.LoadConfig(".")
+	session, active, err := auth.ActiveSession(r.Context(), queries, payload)
 	if err != nil {
-		log.Printf("failed to load config file with err: %v", err)
+		log.Printf("failed to get session with err: %v", err)
 		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
 		return
 	}
 
-	accessToken, accessTokenPayload, err := auth.CreateToken(account.ID, time.Now(), config.Access_Token_Duration)
-	if err != nil {
-		log.Printf("failed to create access token with err: %v", err)
-		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
+	if !active {
+		util.Response(w, "invalid token", http.StatusUnauthorized)
 		return
 	}
 
-	refreshToken, refreshTokenPayload, err := auth.CreateToken(account.ID, accessTokenPayload.IssuedAt, config.Refresh_Token_Duration)
+	accessToken, refreshToken, refreshTokenCookie, err := auth.RefreshSession(r, queries, session, payload.ID)
 	if err != nil {
-		log.Printf("failed to create refresh token with err: %v", err)
-		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
-		return
-	}
-
-	refreshTokenCookie := http.Cookie{
-		Name:     "refreshTokenCookie",
-		Value:    refreshToken,
-		Path:     "/",
-		Expires:  refreshTokenPayload.Expiry,
-		Secure:   true,
-		SameSite: http.SameSite(http.SameSiteStrictMode),
-		HttpOnly: true,
-	}
-
-	http.SetCookie(w, &refreshTokenCookie)
-
-	createAccountSessionParams := sqlc.CreateAccountSessionParams{
-		RefreshTokenID: refreshTokenPayload.ID,
-		AccountID:      account.ID,
-		IssuedAt:       refreshTokenPayload.IssuedAt,
-		Expiry:         refreshTokenPayload.Expiry,
-		UserAgent:      "",
-		ClientIp:       "",
-	}
+		if errors.Is(err, auth.ErrRefreshTokenReused) {
+			log.Printf("refresh token reused, revoking session %s of account %d", session.SessionID, session.AccountID)
+
+			if _, err := queries.DeleteAccountSession(r.Context(), sqlc.DeleteAccountSessionParams{
+				SessionID: session.SessionID,
+				AccountID: session.AccountID,
+			}); err != nil {
+				log.Printf("failed to revoke session with err: %v", err)
+			}
+
+			auth.ClearRefreshTokenCookie(w)
+			util.Response(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
 
-	_, err = queries.CreateAccountSession(r.Context(), createAccountSessionParams)
-	if err != nil {
 		var pgErr *pgconn.PgError
 
 		if errors.As(err, &pgErr) {
@@ -97,6 +81,8 @@ func (h *BaseHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	http.SetCookie(w, &refreshTokenCookie)
+
 	account, err = queries.GetAccount(r.Context(), account.ID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
+// accountResponse is what the API shows of an account. The password hash never leaves the database.
+type accountResponse struct {
+	ID            int64          `json:"id"`
+	Fullname      string         `json:"fullname"`
+	Email         string         `json:"email"`
+	EmailVerified bool           `json:"email_verified"`
+	Picture       string         `json:"picture"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Intention     sql.NullString `json:"intention"`
+	LastLogin     time.Time      `json:"last_login"`
+	IsAdmin       bool           `json:"is_admin"`
+}
+
+func newAccountResponse(a sqlc.Account) accountResponse {
+	return accountResponse{
+		ID:            a.ID,
+		Fullname:      a.Fullname,
+		Email:         a.Email,
+		EmailVerified: a.EmailVerified,
+		Picture:       a.Picture,
+		CreatedAt:     a.CreatedAt,
+		Intention:     a.Intention,
+		LastLogin:     a.LastLogin,
+		IsAdmin:       a.IsAdmin,
+	}
+}
+
+// linkResponse leaves out the search index column
+type linkResponse struct {
+	LinkID         string                  `json:"link_id"`
+	LinkTitle      string                  `json:"link_title"`
+	LinkThumbnail  string                  `json:"link_thumbnail"`
+	LinkFavicon    string                  `json:"link_favicon"`
+	LinkHostname   string                  `json:"link_hostname"`
+	LinkUrl        string                  `json:"link_url"`
+	LinkNotes      string                  `json:"link_notes"`
+	AccountID      int64                   `json:"account_id"`
+	FolderID       sql.NullString          `json:"folder_id"`
+	AddedAt        time.Time               `json:"added_at"`
+	UpdatedAt      time.Time               `json:"updated_at"`
+	DeletedAt      sql.NullTime            `json:"deleted_at"`
+	MetadataStatus sqlc.LinkMetadataStatus `json:"metadata_status"`
+}
+
+func newLinkResponse(l sqlc.Link) linkResponse {
+	return linkResponse{
+		LinkID:         l.LinkID,
+		LinkTitle:      l.LinkTitle,
+		LinkThumbnail:  l.LinkThumbnail,
+		LinkFavicon:    l.LinkFavicon,
+		LinkHostname:   l.LinkHostname,
+		LinkUrl:        l.LinkUrl,
+		LinkNotes:      l.LinkNotes,
+		AccountID:      l.AccountID,
+		FolderID:       l.FolderID,
+		AddedAt:        l.AddedAt,
+		UpdatedAt:      l.UpdatedAt,
+		DeletedAt:      l.DeletedAt,
+		MetadataStatus: l.MetadataStatus,
+	}
+}
+
+func newLinkResponses(links []sqlc.Link) []linkResponse {
+	var res []linkResponse
+
+	for _, link := range links {
+		res = append(res, newLinkResponse(link))
+	}
+
+	return res
+}
+
+// linkArchiveResponse leaves out where the archive is stored and its text, they are fetched on their own.
+// ArchivedAt is when the snapshot that can be fetched was taken, it stays set while a new one is pending.
+type linkArchiveResponse struct {
+	LinkID        string                 `json:"link_id"`
+	ArchiveStatus sqlc.LinkArchiveStatus `json:"archive_status"`
+	RequestedAt   time.Time              `json:"requested_at"`
+	ArchivedAt    sql.NullTime           `json:"archived_at"`
+}
+
+func newLinkArchiveResponse(a sqlc.LinkArchive) linkArchiveResponse {
+	return linkArchiveResponse{
+		LinkID:        a.LinkID,
+		ArchiveStatus: a.ArchiveStatus,
+		RequestedAt:   a.RequestedAt,
+		ArchivedAt:    a.ArchivedAt,
+	}
+}
+
+// folderResponse leaves out the search index column. Unlike returnFolder it keeps the timestamps as they are.
+type folderResponse struct {
+	FolderID        string         `json:"folder_id"`
+	AccountID       int64          `json:"account_id"`
+	FolderName      string         `json:"folder_name"`
+	Path            string         `json:"path"`
+	Label           string         `json:"label"`
+	Starred         bool           `json:"starred"`
+	FolderCreatedAt time.Time      `json:"folder_created_at"`
+	FolderUpdatedAt time.Time      `json:"folder_updated_at"`
+	SubfolderOf     sql.NullString `json:"subfolder_of"`
+	FolderDeletedAt sql.NullTime   `json:"folder_deleted_at"`
+	// Smart is set for smart folders, Query is the search that fills them
+	Smart bool   `json:"smart,omitempty"`
+	Query string `json:"query,omitempty"`
+}
+
+func newFolderResponse(f sqlc.Folder) folderResponse {
+	return folderResponse{
+		FolderID:        f.FolderID,
+		AccountID:       f.AccountID,
+		FolderName:      f.FolderName,
+		Path:            f.Path,
+		Label:           f.Label,
+		Starred:         f.Starred,
+		FolderCreatedAt: f.FolderCreatedAt,
+		FolderUpdatedAt: f.FolderUpdatedAt,
+		SubfolderOf:     f.SubfolderOf,
+		FolderDeletedAt: f.FolderDeletedAt,
+	}
+}
+
+func newFolderResponses(folders []sqlc.Folder) []folderResponse {
+	var res []folderResponse
+
+	for _, folder := range folders {
+		res = append(res, newFolderResponse(folder))
+	}
+
+	return res
+}
+
+// membershipResponse is one account's membership of a shared collection
+type membershipResponse struct {
+	CollectionID          string                     `json:"collection_id"`
+	MemberID              int64                      `json:"member_id"`
+	JoinDate              time.Time                  `json:"join_date"`
+	CollectionAccessLevel sqlc.CollectionAccessLevel `json:"collection_access_level"`
+}
+
+func newMembershipResponse(m sqlc.CollectionMember) membershipResponse {
+	return membershipResponse{
+		CollectionID:          m.CollectionID,
+		MemberID:              m.MemberID,
+		JoinDate:              m.JoinDate,
+		CollectionAccessLevel: m.CollectionAccessLevel,
+	}
+}
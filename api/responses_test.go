@@ -0,0 +1,119 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
+const (
+	passwordHash = "$2a$16$passwordhashpasswordhashpasswordhashpasswordhash"
+	searchIndex  = "'searchindex':1"
+)
+
+var (
+	now = time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	fullAccount = sqlc.Account{
+		ID:              1,
+		Fullname:        "Jane Doe",
+		Email:           "jane@example.com",
+		EmailVerified:   true,
+		Picture:         "https://example.com/jane.png",
+		AccountPassword: passwordHash,
+		CreatedAt:       now,
+		Intention:       sql.NullString{String: "work", Valid: true},
+		LastLogin:       now,
+		IsAdmin:         true,
+		SuspendedAt:     sql.NullTime{Time: now, Valid: true},
+	}
+
+	fullLink = sqlc.Link{
+		LinkID:                 "link",
+		LinkTitle:              "Title",
+		LinkThumbnail:          "https://example.com/thumbnail.png",
+		LinkFavicon:            "https://example.com/favicon.ico",
+		LinkHostname:           "example.com",
+		LinkUrl:                "https://example.com",
+		LinkNotes:              "notes",
+		AccountID:              1,
+		FolderID:               sql.NullString{String: "folder", Valid: true},
+		AddedAt:                now,
+		UpdatedAt:              now,
+		DeletedAt:              sql.NullTime{Time: now, Valid: true},
+		MetadataStatus:         sqlc.LinkMetadataStatusReady,
+		TextsearchableIndexCol: searchIndex,
+	}
+
+	fullFolder = sqlc.Folder{
+		FolderID:               "folder",
+		AccountID:              1,
+		FolderName:             "Folder",
+		Path:                   "root.folder",
+		Label:                  "folder",
+		Starred:                true,
+		FolderCreatedAt:        now,
+		FolderUpdatedAt:        now,
+		SubfolderOf:            sql.NullString{String: "root", Valid: true},
+		FolderDeletedAt:        sql.NullTime{Time: now, Valid: true},
+		TextsearchableIndexCol: searchIndex,
+	}
+)
+
+// TestResponsesLeaveOutSecrets marshals every response built from a fully populated row, neither the password hash
+// nor the search index may show up under any name
+func TestResponsesLeaveOutSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		response interface{}
+	}{
+		{"account", newAccountResponse(fullAccount)},
+		{"session", newsession(fullAccount, "access", "refresh")},
+		{"admin account", newAdminAccount(fullAccount)},
+		{"link", newLinkResponse(fullLink)},
+		{"links", newLinkResponses([]sqlc.Link{fullLink})},
+		{"link search result", linkSearchResult{linkResponse: newLinkResponse(fullLink), Rank: 1, Headline: "headline"}},
+		{"folder", newFolderResponse(fullFolder)},
+		{"folders", newFolderResponses([]sqlc.Folder{fullFolder})},
+		{"folder search result", folderSearchResult{folderResponse: newFolderResponse(fullFolder), Rank: 1, Headline: "headline"}},
+		{"returned folder", newReturnedFolder(fullFolder)},
+		{"links and folders", newResponse([]returnFolder{newReturnedFolder(fullFolder)}, []sqlc.Link{fullLink})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.response)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := string(b)
+
+			for _, secret := range []string{"account_password", "AccountPassword", passwordHash, "textsearchable_index_col", "TextsearchableIndexCol", searchIndex} {
+				if strings.Contains(got, secret) {
+					t.Errorf("%s shows %q: %s", tt.name, secret, got)
+				}
+			}
+		})
+	}
+}
+
+// TestRowsLeaveOutSecrets makes sure rows that slip through without a response type leave them out too
+func TestRowsLeaveOutSecrets(t *testing.T) {
+	for _, row := range []interface{}{fullAccount, fullLink, fullFolder} {
+		b, err := json.Marshal(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, secret := range []string{passwordHash, searchIndex} {
+			if strings.Contains(string(b), secret) {
+				t.Errorf("%T shows %q: %s", row, secret, b)
+			}
+		}
+	}
+}
@@ -1,95 +1,120 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
-	"fmt"
+	"context"
 	"log"
 	"net/http"
+	"strings"
 
-	"github.com/go-chi/chi/v5"
-	"github.com/jackc/pgconn"
 	"github.com/kwandapchumba/go-bookmark-manager/auth"
-	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/search"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
-func (h *BaseHandler) SearchLinks(w http.ResponseWriter, r *http.Request) {
-	query := chi.URLParam(r, "query")
+// linkSearchResult is a link matching a search, headline is the matching part of its title and notes escaped for html
+// with the matched words wrapped in <b></b>. It is empty when the query is only filters.
+type linkSearchResult struct {
+	linkResponse
+	Rank     float32 `json:"rank"`
+	Headline string  `json:"headline"`
+}
 
-	q := sqlc.New(h.db)
+type folderSearchResult struct {
+	folderResponse
+	Rank     float32 `json:"rank"`
+	Headline string  `json:"headline"`
+}
 
-	payload := r.Context().Value("payload").(*auth.PayLoad)
+type searchResults struct {
+	Links        []linkSearchResult   `json:"links"`
+	Folders      []folderSearchResult `json:"folders"`
+	Page         int32                `json:"page"`
+	PerPage      int32                `json:"per_page"`
+	TotalLinks   int64                `json:"total_links"`
+	TotalFolders int64                `json:"total_folders"`
+}
 
-	percent := "%"
+// Search looks for links and folders of the account, best matches first. query takes the search box syntax of the
+// search package: words, "quoted phrases" and filters such as site:github.com or is:trashed, any of them excluded with
+// a leading -. page and per_page apply to links and folders each.
+func (h *BaseHandler) Search(w http.ResponseWriter, r *http.Request) {
+	raw := strings.TrimSpace(r.URL.Query().Get("query"))
 
-	linkTitle := fmt.Sprintf("%s%s%s", percent, query, percent)
+	if raw == "" {
+		util.Response(w, "query is required", http.StatusBadRequest)
+		return
+	}
 
-	arg := sqlc.SearchLinkzParams{
-		LinkTitle: linkTitle,
-		AccountID: payload.AccountID,
+	query, err := search.Parse(raw)
+	if err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	links, err := q.SearchLinkz(r.Context(), arg)
+	number, size, err := page(r)
 	if err != nil {
-		var pgErr *pgconn.PgError
-
-		if errors.As(err, &pgErr) {
-			log.Printf("failed to search links: %v", err)
-			util.Response(w, "something went wrong", http.StatusInternalServerError)
-			return
-		} else if errors.Is(err, sql.ErrNoRows) {
-			err := errors.New("no links found matching the search query")
-			log.Println(err)
-			util.Response(w, err.Error(), http.StatusNotFound)
-			return
-		} else {
-			log.Printf("failed to search links: %v", err)
-			util.Response(w, "something went wrong", http.StatusInternalServerError)
-			return
-		}
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	util.JsonResponse(w, links)
-}
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	res, err := h.runSearch(r.Context(), query, search.AccountScope(payload.AccountID), number, size)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
 
-func (h *BaseHandler) SearchFolders(w http.ResponseWriter, r *http.Request) {
+	util.JsonResponse(w, res)
+}
 
-	q := sqlc.New(h.db)
+// runSearch runs query against the links and folders in scope, page number of size applies to links and folders each
+func (h *BaseHandler) runSearch(ctx context.Context, query *search.Query, scope search.Scope, number, size int32) (searchResults, error) {
+	res := searchResults{
+		Links:   []linkSearchResult{},
+		Folders: []folderSearchResult{},
+		Page:    number,
+		PerPage: size,
+	}
 
-	query := chi.URLParam(r, "query")
+	var err error
 
-	payload := r.Context().Value("payload").(*auth.PayLoad)
+	res.TotalLinks, err = query.CountLinks(ctx, h.db, scope)
+	if err != nil {
+		return searchResults{}, err
+	}
 
-	percent := "%"
+	links, err := query.Links(ctx, h.db, scope, size, (number-1)*size)
+	if err != nil {
+		return searchResults{}, err
+	}
 
-	folderName := fmt.Sprintf("%s%s%s", percent, query, percent)
+	for _, l := range links {
+		res.Links = append(res.Links, linkSearchResult{
+			linkResponse: newLinkResponse(l.Link),
+			Rank:         l.Rank,
+			Headline:     l.Headline,
+		})
+	}
 
-	arg := sqlc.SearchFolderzParams{
-		//PlaintoTsquery: query,
-		FolderName: folderName,
-		AccountID:  payload.AccountID,
+	res.TotalFolders, err = query.CountFolders(ctx, h.db, scope)
+	if err != nil {
+		return searchResults{}, err
 	}
 
-	folders, err := q.SearchFolderz(r.Context(), arg)
+	folders, err := query.Folders(ctx, h.db, scope, size, (number-1)*size)
 	if err != nil {
-		var pgErr *pgconn.PgError
-
-		if errors.As(err, &pgErr) {
-			log.Printf("failed to search folders: %v", err)
-			util.Response(w, "something went wrong", http.StatusInternalServerError)
-			return
-		} else if errors.Is(err, sql.ErrNoRows) {
-			err := errors.New("no folders found matching the search query")
-			log.Println(err)
-			util.Response(w, err.Error(), http.StatusNotFound)
-			return
-		} else {
-			log.Printf("failed to search folders: %v", err)
-			util.Response(w, "something went wrong", http.StatusInternalServerError)
-			return
-		}
+		return searchResults{}, err
+	}
+
+	for _, f := range folders {
+		res.Folders = append(res.Folders, folderSearchResult{
+			folderResponse: newFolderResponse(f.Folder),
+			Rank:           f.Rank,
+			Headline:       f.Headline,
+		})
 	}
 
-	util.JsonResponse(w, folders)
+	return res, nil
 }
@@ -76,8 +76,9 @@ func (h *BaseHandler) ShareCollection(w http.ResponseWriter, r *http.Request) {
 
 				encodedToken := base64.StdEncoding.EncodeToString([]byte(token))
 
-				inviteEpiry := time.Now().UTC().Add(72 * time.Hour) // 3 days
+				inviteEpiry := time.Now().UTC().Add(inviteDuration)
 
+				// inviting the same email again renews their pending invite
 				params := sqlc.CreateInviteParams{
 					SharedCollectionID:      b.CollectionID,
 					CollectionSharedByName:  inviterAccount.Fullname,
@@ -94,13 +95,6 @@ func (h *BaseHandler) ShareCollection(w http.ResponseWriter, r *http.Request) {
 					var pgErr *pgconn.PgError
 
 					if errors.As(err, &pgErr) {
-
-						e := `duplicate key value violates unique constraint "invite_shared_collection_id_collection_shared_with_key"`
-						if pgErr.Message == e {
-							resendInvitation(email)
-							return
-						}
-
 						log.Printf("could not create invite: pgErr: %v", pgErr)
 						util.Response(w, "something went wrong", http.StatusInternalServerError)
 						return
@@ -213,5 +207,3 @@ func (h *BaseHandler) ShareCollection(w http.ResponseWriter, r *http.Request) {
 
 	util.JsonResponse(w, b.EmailsToInvite)
 }
-
-func resendInvitation(email string) {}
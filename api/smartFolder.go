@@ -0,0 +1,394 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/middleware"
+	"github.com/kwandapchumba/go-bookmark-manager/search"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// smartFolderAsFolder lets smart folders be listed with regular folders, they have no path or label
+func smartFolderAsFolder(f sqlc.SmartFolder) sqlc.Folder {
+	return sqlc.Folder{
+		FolderID:        f.SmartFolderID,
+		AccountID:       f.AccountID,
+		FolderName:      f.SmartFolderName,
+		Starred:         f.Starred,
+		FolderCreatedAt: f.CreatedAt,
+		FolderUpdatedAt: f.UpdatedAt,
+		SubfolderOf:     f.FolderID,
+	}
+}
+
+func newSmartFolderResponse(f sqlc.SmartFolder) folderResponse {
+	res := newFolderResponse(smartFolderAsFolder(f))
+
+	res.Smart = true
+	res.Query = f.SearchQuery
+
+	return res
+}
+
+func newReturnedSmartFolder(f sqlc.SmartFolder) returnFolder {
+	res := newReturnedFolder(smartFolderAsFolder(f))
+
+	res.Smart = true
+	res.Query = f.SearchQuery
+
+	return res
+}
+
+// smartFolderScope is what a smart folder searches. One in a folder finds what is in that folder, whoever added it,
+// so members it is shared with only see what is in the collection, and its tag: filters match the tags of accountID,
+// the account running it. One at the root searches its owner's account.
+func smartFolderScope(ctx context.Context, q *sqlc.Queries, f sqlc.SmartFolder, accountID int64) (search.Scope, error) {
+	if !f.FolderID.Valid {
+		return search.AccountScope(f.AccountID), nil
+	}
+
+	folder, err := q.GetFolder(ctx, f.FolderID.String)
+	if err != nil {
+		return search.Scope{}, err
+	}
+
+	return search.FolderScope(accountID, folder.Path), nil
+}
+
+// authorizeSmartFolder loads a smart folder and checks the user has at least role min on it. Only the account that
+// saved it owns it, and only for as long as it can read the folder it is in, so members who leave or are removed from
+// a collection lose their smart folders in it. Anyone who can read the folder can view it and admins of the folder
+// can delete it.
+func authorizeSmartFolder(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, smartFolderID string, accountID int64, min middleware.Role) (sqlc.SmartFolder, bool) {
+	smartFolder, err := q.GetSmartFolder(ctx, smartFolderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "smart folder not found", http.StatusNotFound)
+			return sqlc.SmartFolder{}, false
+		}
+
+		ErrorInternalServerError(w, err)
+		return sqlc.SmartFolder{}, false
+	}
+
+	role := middleware.RoleNone
+
+	switch {
+	case !smartFolder.FolderID.Valid:
+		if smartFolder.AccountID == accountID {
+			role = middleware.RoleOwner
+		}
+	default:
+		folder, err := q.GetFolder(ctx, smartFolder.FolderID.String)
+		if err != nil {
+			ErrorInternalServerError(w, err)
+			return sqlc.SmartFolder{}, false
+		}
+
+		folderRole, err := middleware.FolderRole(ctx, q, folder, accountID)
+		if err != nil {
+			ErrorInternalServerError(w, err)
+			return sqlc.SmartFolder{}, false
+		}
+
+		// the search is its owner's, everyone else only gets to see what it finds
+		switch {
+		case folderRole >= middleware.RoleView && smartFolder.AccountID == accountID:
+			role = middleware.RoleOwner
+		case folderRole >= middleware.RoleAdmin:
+			role = middleware.RoleAdmin
+		case folderRole >= middleware.RoleView:
+			role = middleware.RoleView
+		}
+	}
+
+	if !hasRole(w, role, min) {
+		return sqlc.SmartFolder{}, false
+	}
+
+	return smartFolder, true
+}
+
+type createSmartFolderRequest struct {
+	Name     string `json:"name"`
+	Query    string `json:"query"`
+	FolderID string `json:"folder_id"`
+}
+
+func (c createSmartFolderRequest) validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Name, validation.Required.Error("name is required"), validation.Length(1, 100).Error("name must be at most 100 characters long")),
+		validation.Field(&c.Query, validation.Required.Error("query is required"), validation.By(validSearchQuery)),
+		validation.Field(&c.FolderID, validation.When(c.FolderID != "", validation.Length(33, 33).Error("folder id must be 33 characters long"))),
+	)
+}
+
+func validSearchQuery(value interface{}) error {
+	value, _ = validation.Indirect(value)
+
+	query, _ := value.(string)
+
+	if _, err := search.Parse(query); err != nil {
+		return validation.NewError("validation_invalid_search_query", err.Error())
+	}
+
+	return nil
+}
+
+// CreateSmartFolder saves a search as a smart folder at the root or, with folder_id, inside a folder the user can add to
+func (h *BaseHandler) CreateSmartFolder(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req createSmartFolderRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Query = strings.TrimSpace(req.Query)
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	var folderID sql.NullString
+
+	if req.FolderID != "" {
+		if _, ok := authorizeFolder(w, r.Context(), q, req.FolderID, payload.AccountID, middleware.RoleEdit); !ok {
+			return
+		}
+
+		folderID = sql.NullString{String: req.FolderID, Valid: true}
+	}
+
+	ids := make(chan string, 1)
+
+	util.RandomStringGenerator(ids)
+
+	smartFolder, err := q.CreateSmartFolder(r.Context(), sqlc.CreateSmartFolderParams{
+		SmartFolderID:   <-ids,
+		AccountID:       payload.AccountID,
+		FolderID:        folderID,
+		SmartFolderName: req.Name,
+		SearchQuery:     req.Query,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newSmartFolderResponse(smartFolder))
+}
+
+type smartFolderContents struct {
+	SmartFolder folderResponse `json:"smart_folder"`
+	searchResults
+}
+
+// GetSmartFolder runs the search of a smart folder, page and per_page apply to links and folders each
+func (h *BaseHandler) GetSmartFolder(w http.ResponseWriter, r *http.Request) {
+	number, size, err := page(r)
+	if err != nil {
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	smartFolder, ok := authorizeSmartFolder(w, r.Context(), q, chi.URLParam(r, "smartFolderID"), payload.AccountID, middleware.RoleView)
+	if !ok {
+		return
+	}
+
+	query, err := search.Parse(smartFolder.SearchQuery)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	scope, err := smartFolderScope(r.Context(), q, smartFolder, payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	results, err := h.runSearch(r.Context(), query, scope, number, size)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, smartFolderContents{
+		SmartFolder:   newSmartFolderResponse(smartFolder),
+		searchResults: results,
+	})
+}
+
+type updateSmartFolderRequest struct {
+	Name    *string `json:"name"`
+	Query   *string `json:"query"`
+	Starred *bool   `json:"starred"`
+}
+
+func (u updateSmartFolderRequest) validate() error {
+	return validation.ValidateStruct(&u,
+		validation.Field(&u.Name, validation.NilOrNotEmpty.Error("name cannot be empty"), validation.Length(1, 100).Error("name must be at most 100 characters long")),
+		validation.Field(&u.Query, validation.NilOrNotEmpty.Error("query cannot be empty"), validation.By(validSearchQuery)),
+	)
+}
+
+// UpdateSmartFolder renames, stars or changes the search of a smart folder, fields left out of the request are kept
+func (h *BaseHandler) UpdateSmartFolder(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req updateSmartFolderRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if req.Name != nil {
+		*req.Name = strings.TrimSpace(*req.Name)
+	}
+
+	if req.Query != nil {
+		*req.Query = strings.TrimSpace(*req.Query)
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	smartFolder, ok := authorizeSmartFolder(w, r.Context(), q, chi.URLParam(r, "smartFolderID"), payload.AccountID, middleware.RoleOwner)
+	if !ok {
+		return
+	}
+
+	params := sqlc.UpdateSmartFolderParams{
+		SmartFolderName: smartFolder.SmartFolderName,
+		SearchQuery:     smartFolder.SearchQuery,
+		Starred:         smartFolder.Starred,
+		SmartFolderID:   smartFolder.SmartFolderID,
+	}
+
+	if req.Name != nil {
+		params.SmartFolderName = *req.Name
+	}
+
+	if req.Query != nil {
+		params.SearchQuery = *req.Query
+	}
+
+	if req.Starred != nil {
+		params.Starred = *req.Starred
+	}
+
+	smartFolder, err := q.UpdateSmartFolder(r.Context(), params)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, newSmartFolderResponse(smartFolder))
+}
+
+// DeleteSmartFolder only deletes the saved search, what it found stays where it is
+func (h *BaseHandler) DeleteSmartFolder(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	smartFolder, ok := authorizeSmartFolder(w, r.Context(), q, chi.URLParam(r, "smartFolderID"), payload.AccountID, middleware.RoleAdmin)
+	if !ok {
+		return
+	}
+
+	if err := q.DeleteSmartFolder(r.Context(), smartFolder.SmartFolderID); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.Response(w, "smart folder deleted", http.StatusOK)
+}
+
+// ExportSmartFolder exports the links a smart folder finds as a folder of that name, ?format= works as it does for
+// ExportBookmarks
+func (h *BaseHandler) ExportSmartFolder(w http.ResponseWriter, r *http.Request) {
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	smartFolder, ok := authorizeSmartFolder(w, r.Context(), q, chi.URLParam(r, "smartFolderID"), payload.AccountID, middleware.RoleView)
+	if !ok {
+		return
+	}
+
+	query, err := search.Parse(smartFolder.SearchQuery)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	scope, err := smartFolderScope(r.Context(), q, smartFolder, payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	found, err := query.Links(r.Context(), h.db, scope, math.MaxInt32, 0)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	var links []sqlc.Link
+
+	for _, l := range found {
+		// the links are exported as if they were in the smart folder
+		l.FolderID = sql.NullString{String: smartFolder.SmartFolderID, Valid: true}
+
+		links = append(links, l.Link)
+	}
+
+	// trashed links are only found when the search asks for them, they are exported when it does
+	root := buildExportTree([]sqlc.Folder{smartFolderAsFolder(smartFolder)}, links, true)
+
+	writeExport(w, root, format)
+}
@@ -0,0 +1,460 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/jackc/pgconn"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/middleware"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// getOwnedTag writes the error response itself and returns false when the tag does not exist or is not the user's
+func getOwnedTag(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, tagID, accountID int64) (sqlc.Tag, bool) {
+	tag, err := q.GetTag(ctx, tagID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			util.Response(w, "tag not found", http.StatusNotFound)
+		case errors.As(err, &pgErr):
+			log.Printf("could not get tag at tag.go: %v", pgErr)
+			util.Response(w, internalServerError, http.StatusInternalServerError)
+		default:
+			log.Printf("could not get tag at tag.go: %v", err)
+			util.Response(w, internalServerError, http.StatusInternalServerError)
+		}
+
+		return sqlc.Tag{}, false
+	}
+
+	if tag.AccountID != accountID {
+		log.Println("unauthorized")
+		util.Response(w, "unauthorized", http.StatusUnauthorized)
+		return sqlc.Tag{}, false
+	}
+
+	return tag, true
+}
+
+func tagNameTaken(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, accountID int64, name string) bool {
+	exists, err := q.TagNameExists(ctx, sqlc.TagNameExistsParams{
+		AccountID: accountID,
+		TagName:   name,
+	})
+	if err != nil {
+		log.Printf("could not check if tag name exists at tag.go: %v", err)
+		util.Response(w, internalServerError, http.StatusInternalServerError)
+		return true
+	}
+
+	if exists {
+		util.Response(w, "a tag with this name already exists", http.StatusConflict)
+		return true
+	}
+
+	return false
+}
+
+type createTagRequest struct {
+	TagName string `json:"tag_name"`
+}
+
+func (c createTagRequest) validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.TagName, validation.Required.Error("tag name is required"), validation.Length(1, 100).Error("tag name must be between 1 and 100 characters long")),
+	)
+}
+
+func (h *BaseHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req createTagRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	req.TagName = strings.TrimSpace(req.TagName)
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	if tagNameTaken(w, r.Context(), q, payload.AccountID, req.TagName) {
+		return
+	}
+
+	tag, err := q.CreateTag(r.Context(), sqlc.CreateTagParams{
+		AccountID: payload.AccountID,
+		TagName:   req.TagName,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, tag)
+}
+
+func (h *BaseHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	tags, err := q.GetTagsByAccountID(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, tags)
+}
+
+type renameTagRequest struct {
+	TagID   int64  `json:"tag_id"`
+	TagName string `json:"tag_name"`
+}
+
+func (rt renameTagRequest) validate() error {
+	return validation.ValidateStruct(&rt,
+		validation.Field(&rt.TagID, validation.Required.Error("tag id is required")),
+		validation.Field(&rt.TagName, validation.Required.Error("tag name is required"), validation.Length(1, 100).Error("tag name must be between 1 and 100 characters long")),
+	)
+}
+
+func (h *BaseHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req renameTagRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	req.TagName = strings.TrimSpace(req.TagName)
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	tag, ok := getOwnedTag(w, r.Context(), q, req.TagID, payload.AccountID)
+	if !ok {
+		return
+	}
+
+	if tag.TagName == req.TagName {
+		util.JsonResponse(w, tag)
+		return
+	}
+
+	if tagNameTaken(w, r.Context(), q, payload.AccountID, req.TagName) {
+		return
+	}
+
+	renamedTag, err := q.RenameTag(r.Context(), sqlc.RenameTagParams{
+		TagName: req.TagName,
+		TagID:   tag.TagID,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, renamedTag)
+}
+
+func (h *BaseHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := strconv.ParseInt(chi.URLParam(r, "tagID"), 10, 64)
+	if err != nil {
+		util.Response(w, "invalid tag id", http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	if _, ok := getOwnedTag(w, r.Context(), q, tagID, payload.AccountID); !ok {
+		return
+	}
+
+	tag, err := q.DeleteTag(r.Context(), tagID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, tag)
+}
+
+type mergeTagsRequest struct {
+	SourceTagIDs []int64 `json:"source_tag_ids"`
+	TargetTagID  int64   `json:"target_tag_id"`
+}
+
+func (m mergeTagsRequest) validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.SourceTagIDs, validation.Required.Error("source tag ids are required")),
+		validation.Field(&m.TargetTagID, validation.Required.Error("target tag id is required")),
+	)
+}
+
+// MergeTags moves every link and folder tagged with one of the source tags over to the target tag and then
+// deletes the source tags
+func (h *BaseHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req mergeTagsRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	target, ok := getOwnedTag(w, r.Context(), q, req.TargetTagID, payload.AccountID)
+	if !ok {
+		return
+	}
+
+	for _, sourceTagID := range req.SourceTagIDs {
+		if sourceTagID == target.TagID {
+			util.Response(w, "a tag cannot be merged into itself", http.StatusBadRequest)
+			return
+		}
+
+		if _, ok := getOwnedTag(w, r.Context(), q, sourceTagID, payload.AccountID); !ok {
+			return
+		}
+	}
+
+	tx, err := h.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	defer tx.Rollback()
+
+	qtx := q.WithTx(tx)
+
+	merged := make(map[int64]bool)
+
+	for _, sourceTagID := range req.SourceTagIDs {
+		if merged[sourceTagID] {
+			continue
+		}
+
+		merged[sourceTagID] = true
+
+		arg := sqlc.MergeLinkTagsParams{
+			TargetTagID: target.TagID,
+			SourceTagID: sourceTagID,
+		}
+
+		if err := qtx.MergeLinkTags(r.Context(), arg); err != nil {
+			ErrorInternalServerError(w, err)
+			return
+		}
+
+		if err := qtx.MergeFolderTags(r.Context(), sqlc.MergeFolderTagsParams(arg)); err != nil {
+			ErrorInternalServerError(w, err)
+			return
+		}
+
+		if _, err := qtx.DeleteTag(r.Context(), sourceTagID); err != nil {
+			ErrorInternalServerError(w, err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, target)
+}
+
+type tagItemsRequest struct {
+	TagIDs    []int64  `json:"tag_ids"`
+	LinkIDs   []string `json:"link_ids"`
+	FolderIDs []string `json:"folder_ids"`
+}
+
+func (t tagItemsRequest) validate() error {
+	return validation.ValidateStruct(&t,
+		validation.Field(&t.TagIDs, validation.Required.Error("tag ids are required")),
+		validation.Field(&t.LinkIDs, validation.Required.When(len(t.FolderIDs) == 0).Error("link ids or folder ids are required"), validation.Each(validation.Length(33, 33).Error("link id must be 33 characters long"))),
+		validation.Field(&t.FolderIDs, validation.Each(validation.Length(33, 33).Error("folder id must be 33 characters long"))),
+	)
+}
+
+func (h *BaseHandler) TagItems(w http.ResponseWriter, r *http.Request) {
+	h.changeItemTags(w, r, true)
+}
+
+func (h *BaseHandler) UntagItems(w http.ResponseWriter, r *http.Request) {
+	h.changeItemTags(w, r, false)
+}
+
+func (h *BaseHandler) changeItemTags(w http.ResponseWriter, r *http.Request, add bool) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req tagItemsRequest
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	for _, tagID := range req.TagIDs {
+		if _, ok := getOwnedTag(w, r.Context(), q, tagID, payload.AccountID); !ok {
+			return
+		}
+	}
+
+	// tags are personal, anything the user can see may be tagged. Tagged items are only listed while the user can see them
+	if _, ok := authorizeLinks(w, r.Context(), q, req.LinkIDs, payload.AccountID, middleware.RoleView); !ok {
+		return
+	}
+
+	if _, ok := authorizeFolders(w, r.Context(), q, req.FolderIDs, payload.AccountID, middleware.RoleView); !ok {
+		return
+	}
+
+	for _, tagID := range req.TagIDs {
+		for _, linkID := range req.LinkIDs {
+			var err error
+
+			if add {
+				err = q.AddTagToLink(r.Context(), sqlc.AddTagToLinkParams{LinkID: linkID, TagID: tagID})
+			} else {
+				err = q.RemoveTagFromLink(r.Context(), sqlc.RemoveTagFromLinkParams{LinkID: linkID, TagID: tagID})
+			}
+
+			if err != nil {
+				ErrorInternalServerError(w, err)
+				return
+			}
+		}
+
+		for _, folderID := range req.FolderIDs {
+			var err error
+
+			if add {
+				err = q.AddTagToFolder(r.Context(), sqlc.AddTagToFolderParams{FolderID: folderID, TagID: tagID})
+			} else {
+				err = q.RemoveTagFromFolder(r.Context(), sqlc.RemoveTagFromFolderParams{FolderID: folderID, TagID: tagID})
+			}
+
+			if err != nil {
+				ErrorInternalServerError(w, err)
+				return
+			}
+		}
+	}
+
+	util.Response(w, "tags updated", http.StatusOK)
+}
+
+// GetTagItems lists the folders and links carrying a tag. Trashed items are left out unless ?include_trashed=true, and so
+// is whatever the user can no longer read, such as what is in a collection they were removed from.
+func (h *BaseHandler) GetTagItems(w http.ResponseWriter, r *http.Request) {
+	tagID, err := strconv.ParseInt(chi.URLParam(r, "tagID"), 10, 64)
+	if err != nil {
+		util.Response(w, "invalid tag id", http.StatusBadRequest)
+		return
+	}
+
+	includeTrashed := r.URL.Query().Get("include_trashed") == "true"
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	if _, ok := getOwnedTag(w, r.Context(), q, tagID, payload.AccountID); !ok {
+		return
+	}
+
+	folders, err := q.GetFoldersByTag(r.Context(), sqlc.GetFoldersByTagParams{
+		TagID:          tagID,
+		IncludeTrashed: includeTrashed,
+		AccountID:      payload.AccountID,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	links, err := q.GetLinksByTag(r.Context(), sqlc.GetLinksByTagParams{
+		TagID:          tagID,
+		IncludeTrashed: includeTrashed,
+		AccountID:      payload.AccountID,
+	})
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	var rfs []returnFolder
+
+	for _, f := range folders {
+		rfs = append(rfs, newReturnedFolder(f))
+	}
+
+	util.JsonResponse(w, newResponse(rfs, links))
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
+// TestTaggedItemsPostgres checks tags only list and count what their account can still read
+func TestTaggedItemsPostgres(t *testing.T) {
+	q := sqlc.New(testCollections.Postgres(t))
+
+	ctx := context.Background()
+
+	tag, err := q.CreateTag(ctx, sqlc.CreateTagParams{AccountID: viewerID, TagName: "later"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.AddTagToLink(ctx, sqlc.AddTagToLinkParams{LinkID: "child-link", TagID: tag.TagID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.AddTagToFolder(ctx, sqlc.AddTagToFolderParams{FolderID: "child", TagID: tag.TagID}); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(want int) {
+		t.Helper()
+
+		links, err := q.GetLinksByTag(ctx, sqlc.GetLinksByTagParams{TagID: tag.TagID, AccountID: viewerID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		folders, err := q.GetFoldersByTag(ctx, sqlc.GetFoldersByTagParams{TagID: tag.TagID, AccountID: viewerID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tags, err := q.GetTagsByAccountID(ctx, viewerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(links) != want || len(folders) != want {
+			t.Errorf("got %d links and %d folders, want %d of each", len(links), len(folders), want)
+		}
+
+		if len(tags) != 1 || tags[0].LinkCount != int64(want) || tags[0].FolderCount != int64(want) {
+			t.Errorf("tags = %+v, want %d link and %d folder", tags, want, want)
+		}
+	}
+
+	check(1)
+
+	if _, err := q.DeleteCollectionMember(ctx, sqlc.DeleteCollectionMemberParams{CollectionID: "child", MemberID: viewerID}); err != nil {
+		t.Fatal(err)
+	}
+
+	check(0)
+}
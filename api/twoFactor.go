@@ -0,0 +1,488 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+const (
+	totpIssuer        = "Linkspace"
+	recoveryCodeCount = 10
+)
+
+type twoFactorChallenge struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	Expiry            time.Time `json:"expiry"`
+}
+
+// requireTwoFactor writes a challenge instead of a session when the account has two factor authentication on. Sign
+// ins go on at VerifyTwoFactorSignIn with the challenge token and a code. Every sign in but one with a passkey has
+// to go through it, passkeys are only accepted with user verification so they are two factors on their own.
+func requireTwoFactor(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, accountID int64) (bool, error) {
+	challenge, err := newTwoFactorChallenge(ctx, q, accountID)
+	if err != nil || challenge == nil {
+		return false, err
+	}
+
+	util.JsonResponse(w, challenge)
+
+	return true, nil
+}
+
+// newTwoFactorChallenge is nil when the account has no two factor authentication to challenge
+func newTwoFactorChallenge(ctx context.Context, q *sqlc.Queries, accountID int64) (*twoFactorChallenge, error) {
+	totp, err := q.GetAccountTotp(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if !totp.ConfirmedAt.Valid {
+		return nil, nil
+	}
+
+	token, payload, err := auth.CreateTwoFactorChallengeToken(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &twoFactorChallenge{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		Expiry:            payload.Expiry,
+	}, nil
+}
+
+// verifySecondFactor accepts a code from the authenticator app or an unused recovery code, either only works once
+func verifySecondFactor(ctx context.Context, q *sqlc.Queries, totp sqlc.AccountTotp, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if strings.Contains(code, "-") {
+		n, err := q.UseTotpRecoveryCode(ctx, sqlc.UseTotpRecoveryCodeParams{
+			AccountID: totp.AccountID,
+			CodeHash:  auth.HashRecoveryCode(code),
+		})
+
+		return n > 0, err
+	}
+
+	step, ok := auth.ValidateTOTP(totp.TotpSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	// only moves last_used_step forward, so a code that was already used is refused
+	n, err := q.UseTotpStep(ctx, sqlc.UseTotpStepParams{
+		LastUsedStep: step,
+		AccountID:    totp.AccountID,
+	})
+
+	return n > 0, err
+}
+
+// checkSecondFactor writes the error response itself and returns false when the code is wrong. Wrong codes count
+// towards locking the account's second factor out, so codes cannot be guessed.
+func (h *BaseHandler) checkSecondFactor(w http.ResponseWriter, r *http.Request, q *sqlc.Queries, totp sqlc.AccountTotp, code string) bool {
+	key := ratelimit.AccountKey(totp.AccountID)
+
+	if h.lockedOut(w, r.Context(), key) {
+		return false
+	}
+
+	valid, err := verifySecondFactor(r.Context(), q, totp, code)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return false
+	}
+
+	if !valid {
+		h.signInFailed(r.Context(), key)
+		util.Response(w, "invalid code", http.StatusUnauthorized)
+		return false
+	}
+
+	h.signInSucceeded(r.Context(), key)
+
+	return true
+}
+
+// enabledTotp writes the error response itself and returns false when two factor authentication is not on
+func enabledTotp(w http.ResponseWriter, ctx context.Context, q *sqlc.Queries, accountID int64) (sqlc.AccountTotp, bool) {
+	totp, err := q.GetAccountTotp(ctx, accountID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ErrorInternalServerError(w, err)
+		return sqlc.AccountTotp{}, false
+	}
+
+	if err != nil || !totp.ConfirmedAt.Valid {
+		util.Response(w, "two factor authentication is not on", http.StatusBadRequest)
+		return sqlc.AccountTotp{}, false
+	}
+
+	return totp, true
+}
+
+// newRecoveryCodes replaces every recovery code of an account, the codes are only ever returned here
+func newRecoveryCodes(ctx context.Context, q *sqlc.Queries, accountID int64) ([]string, error) {
+	if err := q.DeleteTotpRecoveryCodes(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	codes := []string{}
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := auth.NewRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := q.CreateTotpRecoveryCode(ctx, sqlc.CreateTotpRecoveryCodeParams{
+			AccountID: accountID,
+			CodeHash:  auth.HashRecoveryCode(code),
+		}); err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+type twoFactorCode struct {
+	Code string `json:"code"`
+}
+
+func (t twoFactorCode) validate() error {
+	return validation.ValidateStruct(&t,
+		validation.Field(&t.Code, validation.Required.Error("code is required")),
+	)
+}
+
+func decodeTwoFactorCode(w http.ResponseWriter, r *http.Request) (string, bool) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req twoFactorCode
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return "", false
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return "", false
+	}
+
+	return req.Code, true
+}
+
+type twoFactorStatus struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+func (h *BaseHandler) GetTwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	totp, err := q.GetAccountTotp(r.Context(), payload.AccountID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	res := twoFactorStatus{Enabled: err == nil && totp.ConfirmedAt.Valid}
+
+	if res.Enabled {
+		res.RecoveryCodesLeft, err = q.CountUnusedTotpRecoveryCodes(r.Context(), payload.AccountID)
+		if err != nil {
+			ErrorInternalServerError(w, err)
+			return
+		}
+	}
+
+	util.JsonResponse(w, res)
+}
+
+type twoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+// EnrollTwoFactor starts turning on two factor authentication, nothing changes for sign ins until a first code from
+// the authenticator app is confirmed
+func (h *BaseHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	totp, err := q.GetAccountTotp(r.Context(), payload.AccountID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err == nil && totp.ConfirmedAt.Valid {
+		util.Response(w, "two factor authentication is already on", http.StatusConflict)
+		return
+	}
+
+	account, err := q.GetAccount(r.Context(), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if _, err := q.UpsertAccountTotp(r.Context(), sqlc.UpsertAccountTotpParams{
+		AccountID:  payload.AccountID,
+		TotpSecret: secret,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, twoFactorEnrollment{
+		Secret:     secret,
+		OtpauthURI: auth.TOTPURI(totpIssuer, account.Email, secret),
+	})
+}
+
+// ConfirmTwoFactor turns two factor authentication on and returns the recovery codes
+func (h *BaseHandler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	code, ok := decodeTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	totp, err := q.GetAccountTotp(r.Context(), payload.AccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.Response(w, "enroll before confirming two factor authentication", http.StatusBadRequest)
+			return
+		}
+
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if totp.ConfirmedAt.Valid {
+		util.Response(w, "two factor authentication is already on", http.StatusConflict)
+		return
+	}
+
+	step, ok := auth.ValidateTOTP(totp.TotpSecret, code, time.Now())
+	if !ok {
+		util.Response(w, "invalid code", http.StatusUnauthorized)
+		return
+	}
+
+	tx, err := h.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	defer tx.Rollback()
+
+	qtx := q.WithTx(tx)
+
+	if _, err := qtx.ConfirmAccountTotp(r.Context(), sqlc.ConfirmAccountTotpParams{
+		LastUsedStep: step,
+		AccountID:    payload.AccountID,
+	}); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	codes, err := newRecoveryCodes(r.Context(), qtx, payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, map[string][]string{"recovery_codes": codes})
+}
+
+// DisableTwoFactor needs a fresh code so a stolen session alone cannot turn it off
+func (h *BaseHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	code, ok := decodeTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	totp, ok := enabledTotp(w, r.Context(), q, payload.AccountID)
+	if !ok {
+		return
+	}
+
+	if !h.checkSecondFactor(w, r, q, totp, code) {
+		return
+	}
+
+	tx, err := h.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	defer tx.Rollback()
+
+	qtx := q.WithTx(tx)
+
+	if err := qtx.DeleteTotpRecoveryCodes(r.Context(), payload.AccountID); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err := qtx.DeleteAccountTotp(r.Context(), payload.AccountID); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.Response(w, "two factor authentication turned off", http.StatusOK)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, used or not
+func (h *BaseHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	code, ok := decodeTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	payload := r.Context().Value("payload").(*auth.PayLoad)
+
+	q := sqlc.New(h.db)
+
+	totp, ok := enabledTotp(w, r.Context(), q, payload.AccountID)
+	if !ok {
+		return
+	}
+
+	if !h.checkSecondFactor(w, r, q, totp, code) {
+		return
+	}
+
+	tx, err := h.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	defer tx.Rollback()
+
+	codes, err := newRecoveryCodes(r.Context(), q.WithTx(tx), payload.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	util.JsonResponse(w, map[string][]string{"recovery_codes": codes})
+}
+
+type twoFactorSignIn struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+func (t twoFactorSignIn) validate() error {
+	return validation.ValidateStruct(&t,
+		validation.Field(&t.ChallengeToken, validation.Required.Error("challenge token is required")),
+		validation.Field(&t.Code, validation.Required.Error("code is required")),
+	)
+}
+
+// VerifyTwoFactorSignIn finishes a sign in that was answered with a two factor challenge
+func (h *BaseHandler) VerifyTwoFactorSignIn(w http.ResponseWriter, r *http.Request) {
+	body := json.NewDecoder(r.Body)
+
+	body.DisallowUnknownFields()
+
+	var req twoFactorSignIn
+
+	if err := body.Decode(&req); err != nil {
+		ErrorDecodingRequest(w, err)
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		log.Printf("bad request: %v", err)
+		util.Response(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	challenge, err := auth.VerifyTwoFactorChallengeToken(req.ChallengeToken)
+	if err != nil {
+		log.Printf("could not verify two factor challenge token at twoFactor.go: %v", err)
+		util.Response(w, "invalid challenge token", http.StatusUnauthorized)
+		return
+	}
+
+	q := sqlc.New(h.db)
+
+	totp, ok := enabledTotp(w, r.Context(), q, challenge.AccountID)
+	if !ok {
+		return
+	}
+
+	if !h.checkSecondFactor(w, r, q, totp, req.Code) {
+		return
+	}
+
+	account, err := q.GetAccount(r.Context(), challenge.AccountID)
+	if err != nil {
+		ErrorInternalServerError(w, err)
+		return
+	}
+
+	loginUser(account, w, h, r)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/dbtest"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
+// TestSecondFactorOnlyWorksOncePostgres checks the queries that keep codes from being used twice
+func TestSecondFactorOnlyWorksOncePostgres(t *testing.T) {
+	q := sqlc.New(dbtest.Postgres(t))
+
+	ctx := context.Background()
+
+	account, err := q.NewAccount(ctx, sqlc.NewAccountParams{Fullname: "Jane", Email: "jane@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := q.UpsertAccountTotp(ctx, sqlc.UpsertAccountTotpParams{AccountID: account.ID, TotpSecret: secret}); err != nil {
+		t.Fatal(err)
+	}
+
+	totp, err := q.ConfirmAccountTotp(ctx, sqlc.ConfirmAccountTotpParams{LastUsedStep: 100, AccountID: account.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name string
+		step int64
+		want int64
+	}{
+		{"the step confirmed with", 100, 0},
+		{"an earlier step", 99, 0},
+		{"the next step", 101, 1},
+		{"the next step again", 101, 0},
+		{"a step much later", 120, 1},
+	}
+
+	for _, s := range steps {
+		n, err := q.UseTotpStep(ctx, sqlc.UseTotpStepParams{LastUsedStep: s.step, AccountID: account.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != s.want {
+			t.Errorf("UseTotpStep(%s) = %d rows, want %d", s.name, n, s.want)
+		}
+	}
+
+	code, err := auth.NewRecoveryCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.CreateTotpRecoveryCode(ctx, sqlc.CreateTotpRecoveryCodeParams{AccountID: account.ID, CodeHash: auth.HashRecoveryCode(code)}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, want := range []bool{true, false} {
+		ok, err := verifySecondFactor(ctx, q, totp, code)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ok != want {
+			t.Errorf("recovery code use %d = %v, want %v", i+1, ok, want)
+		}
+	}
+
+	if ok, err := verifySecondFactor(ctx, q, totp, "aaaaa-bbbbb"); err != nil || ok {
+		t.Errorf("unknown recovery code = %v, %v, want false", ok, err)
+	}
+}
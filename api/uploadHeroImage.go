@@ -1,13 +1,11 @@
 package api
 
 import (
-	"io"
 	"log"
 	"net/http"
-	"os"
 
+	"github.com/kwandapchumba/go-bookmark-manager/storage"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
-	"github.com/kwandapchumba/go-bookmark-manager/vultr"
 )
 
 func (h *BaseHandler) UploadHeroImage(w http.ResponseWriter, r *http.Request) {
@@ -27,27 +25,13 @@ func (h *BaseHandler) UploadHeroImage(w http.ResponseWriter, r *http.Request) {
 
 	defer file.Close()
 
-	dst, err := os.Create(handler.Filename)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+	key := storage.NewKey(storage.AppAssets)
 
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, file); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	imgFile, err := os.Open(handler.Filename)
-	if err != nil {
-		log.Printf("failed to open file: %v", err)
+	if err := h.store.Put(r.Context(), key, file, handler.Header.Get("Content-Type")); err != nil {
+		log.Printf("could not store hero image at uploadHeroImage.go: %v", err)
 		util.Response(w, "something went wrong", http.StatusInternalServerError)
 		return
 	}
 
-	heroImgScr := vultr.UploadHeroImage(imgFile)
-
-	util.JsonResponse(w, heroImgScr)
+	util.JsonResponse(w, h.store.URL(key))
 }
@@ -13,6 +13,7 @@ import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/kwandapchumba/go-bookmark-manager/auth"
 	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
@@ -84,6 +85,7 @@ func (h *BaseHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 	otp, err := queries.GetOtp(r.Context(), req.Email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
+			h.signInFailed(r.Context(), ratelimit.EmailKey(req.Email))
 			util.Response(w, "otp was not found", http.StatusNotFound)
 			return
 		} else {
@@ -93,10 +95,13 @@ func (h *BaseHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if otp.Code != req.Code {
+		h.signInFailed(r.Context(), ratelimit.EmailKey(req.Email))
 		util.Response(w, "invalid code", http.StatusUnauthorized)
 		return
 	}
 
+	h.signInSucceeded(r.Context(), ratelimit.EmailKey(req.Email))
+
 	if time.Now().UTC().After(otp.Expiry) {
 		util.Response(w, "code has expired", http.StatusUnauthorized)
 		return
@@ -113,59 +118,27 @@ func (h *BaseHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	config, err := util.LoadConfig(".")
-	if err != nil {
-		log.Printf("failed to load config file with err: %v", err)
-		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
-		return
-	}
-
 	if err = queries.DeleteEmailVerificationCode(r.Context(), account.Email); err != nil {
 		util.Response(w, "something went wrong", http.StatusInternalServerError)
 		return
 	}
 
-	accessToken, accessTokenPayload, err := auth.CreateToken(account.ID, time.Now(), config.Access_Token_Duration)
-	if err != nil {
-		log.Printf("failed to create access token with err: %v", err)
+	if required, err := requireTwoFactor(w, r.Context(), queries, account.ID); err != nil {
+		log.Printf("could not check two factor authentication at verifyOtp.go: %v", err)
 		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
 		return
+	} else if required {
+		return
 	}
 
-	refreshToken, refreshTokenPayload, err := auth.CreateToken(account.ID, accessTokenPayload.IssuedAt, config.Refresh_Token_Duration)
+	accessToken, refreshToken, refreshTokenCookie, err := auth.LoginUser(r, queries, account)
 	if err != nil {
-		log.Printf("failed to create refresh token with err: %v", err)
-		util.Response(w, errors.New("something went wrong").Error(), http.StatusInternalServerError)
+		ErrorStartingSession(w, err)
 		return
 	}
 
-	refreshTokenCookie := http.Cookie{
-		Name:     "refreshTokenCookie",
-		Value:    refreshToken,
-		Path:     "/",
-		Expires:  refreshTokenPayload.Expiry,
-		Secure:   true,
-		SameSite: http.SameSiteStrictMode,
-		HttpOnly: true,
-	}
-
 	http.SetCookie(w, &refreshTokenCookie)
 
-	createAccountSessionParams := sqlc.CreateAccountSessionParams{
-		RefreshTokenID: refreshTokenPayload.ID,
-		AccountID:      account.ID,
-		IssuedAt:       refreshTokenPayload.IssuedAt,
-		Expiry:         refreshTokenPayload.Expiry,
-		UserAgent:      "",
-		ClientIp:       "",
-	}
-
-	_, err = queries.CreateAccountSession(r.Context(), createAccountSessionParams)
-	if err != nil {
-		util.Response(w, "something went wrong", http.StatusInternalServerError)
-		return
-	}
-
 	// refreshTokenCookie := http.Cookie{
 	// 	Name:     "refresh_token",
 	// 	Value:    refreshToken,
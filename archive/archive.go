@@ -0,0 +1,102 @@
+// Package archive turns a page rendered by the browser into what is kept of a link when it is archived: a single html
+// file that needs nothing from the network to be displayed, and the text of its article as a reader view would show
+// it.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	maxAssetSize = 5 << 20
+	// maxSnapshotSize is how much of its assets are inlined into a snapshot, the ones after it are linked to
+	maxSnapshotSize = 30 << 20
+	assetTimeout    = 20 * time.Second
+)
+
+var (
+	errAssetTooLarge  = errors.New("asset too large")
+	errPrivateAddress = errors.New("refusing to connect to a private address")
+)
+
+// NewClient returns the client assets are downloaded with. Pages are chosen by users, so it only connects to public
+// addresses to keep them from reading internal services into their archives.
+func NewClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: publicOnly,
+	}
+
+	return &http.Client{
+		Timeout: assetTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: assetTimeout,
+			MaxIdleConnsPerHost:   4,
+		},
+	}
+}
+
+// publicOnly runs once the address has been resolved, so names pointing at private addresses are caught too
+func publicOnly(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w %s", errPrivateAddress, host)
+	}
+
+	return nil
+}
+
+// fetch downloads an asset, contentType is sniffed when the server does not send one
+func fetch(ctx context.Context, client *http.Client, url string) (body []byte, contentType string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("received %d response code", resp.StatusCode)
+	}
+
+	body, err = io.ReadAll(io.LimitReader(resp.Body, maxAssetSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(body) > maxAssetSize {
+		return nil, "", errAssetTooLarge
+	}
+
+	contentType, _, _ = strings.Cut(resp.Header.Get("Content-Type"), ";")
+
+	contentType = strings.TrimSpace(contentType)
+
+	if contentType == "" {
+		contentType, _, _ = strings.Cut(http.DetectContentType(body), ";")
+	}
+
+	return body, contentType, nil
+}
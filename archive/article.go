@@ -0,0 +1,369 @@
+package archive
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// the class and id patterns reader views use to tell articles from what surrounds them
+var (
+	unlikelyCandidate = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote`)
+	maybeCandidate    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveWeight    = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negativeWeight    = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	displayNone       = regexp.MustCompile(`(?i)display\s*:\s*none|visibility\s*:\s*hidden`)
+)
+
+// minParagraphLength is the length of the shortest text that counts towards the score of its ancestors
+const minParagraphLength = 25
+
+// nonContent are never part of an article
+var nonContent = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+	atom.Canvas:   true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Input:    true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Nav:      true,
+	atom.Aside:    true,
+	atom.Footer:   true,
+}
+
+// blocks start a new paragraph of the article text
+var blocks = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Blockquote: true, atom.Br: true, atom.Dd: true, atom.Div: true,
+	atom.Dl: true, atom.Dt: true, atom.Figcaption: true, atom.Figure: true, atom.H1: true, atom.H2: true,
+	atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true, atom.Header: true, atom.Hr: true, atom.Li: true,
+	atom.Main: true, atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true, atom.Table: true,
+	atom.Td: true, atom.Th: true, atom.Tr: true, atom.Ul: true,
+}
+
+// Article returns the text of the main article of page, in paragraphs separated by blank lines. Like reader views it
+// scores the elements holding paragraphs by how much text they have and what they are called, takes the best one
+// and the siblings that look like part of it. Pages where nothing looks like an article give the text of their body.
+func Article(page string) (string, error) {
+	doc, err := html.ParseWithOptions(strings.NewReader(page), html.ParseOptionEnableScripting(false))
+	if err != nil {
+		return "", err
+	}
+
+	body := doc
+
+	for _, n := range elements(doc) {
+		if n.DataAtom == atom.Body {
+			body = n
+			break
+		}
+	}
+
+	prune(body)
+
+	scores := make(map[*html.Node]float64)
+
+	for _, n := range elements(body) {
+		if !scored(n) {
+			continue
+		}
+
+		text := innerText(n)
+
+		length := utf8.RuneCountInString(text)
+		if length < minParagraphLength {
+			continue
+		}
+
+		// a point for the paragraph, one per comma and one per hundred characters up to three
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(length/100), 3)
+
+		for level, ancestor := 0, n.Parent; level < 3 && ancestor != nil && ancestor.Type == html.ElementNode; level, ancestor = level+1, ancestor.Parent {
+			if _, ok := scores[ancestor]; !ok {
+				scores[ancestor] = initialScore(ancestor)
+			}
+
+			switch level {
+			case 0:
+				scores[ancestor] += score
+			case 1:
+				scores[ancestor] += score / 2
+			default:
+				scores[ancestor] += score / 6
+			}
+		}
+	}
+
+	var top *html.Node
+
+	// in document order so the first of equally good candidates wins
+	for _, n := range elements(body) {
+		score, ok := scores[n]
+		if !ok {
+			continue
+		}
+
+		// elements full of links are menus and lists of other articles
+		scores[n] = score * (1 - linkDensity(n))
+
+		if top == nil || scores[n] > scores[top] {
+			top = n
+		}
+	}
+
+	if top == nil {
+		return paragraphs(body), nil
+	}
+
+	threshold := scores[top] * 0.2
+	if threshold < 10 {
+		threshold = 10
+	}
+
+	var article strings.Builder
+
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type != html.ElementNode {
+			continue
+		}
+
+		include := sibling == top || scores[sibling] >= threshold
+
+		if !include && sibling.DataAtom == atom.P {
+			text := innerText(sibling)
+
+			include = utf8.RuneCountInString(text) > 80 && linkDensity(sibling) < 0.25
+		}
+
+		if include {
+			if text := paragraphs(sibling); text != "" {
+				if article.Len() > 0 {
+					article.WriteString("\n\n")
+				}
+
+				article.WriteString(text)
+			}
+		}
+	}
+
+	return article.String(), nil
+}
+
+// prune removes what cannot be part of the article: hidden elements, forms and menus, and elements named like
+// comments or sidebars
+func prune(body *html.Node) {
+	for _, n := range elements(body) {
+		if n == body || !attached(n, body) {
+			continue
+		}
+
+		if nonContent[n.DataAtom] || hidden(n) {
+			remove(n)
+			continue
+		}
+
+		if n.DataAtom == atom.Article || n.DataAtom == atom.Main || n.DataAtom == atom.A {
+			continue
+		}
+
+		match := classAndID(n)
+
+		if match != "" && unlikelyCandidate.MatchString(match) && !maybeCandidate.MatchString(match) {
+			remove(n)
+		}
+	}
+}
+
+func hidden(n *html.Node) bool {
+	if _, ok := attr(n, "hidden"); ok {
+		return true
+	}
+
+	if ariaHidden, _ := attr(n, "aria-hidden"); ariaHidden == "true" {
+		return true
+	}
+
+	style, _ := attr(n, "style")
+
+	return displayNone.MatchString(style)
+}
+
+func classAndID(n *html.Node) string {
+	class, _ := attr(n, "class")
+	id, _ := attr(n, "id")
+
+	return strings.TrimSpace(class + " " + id)
+}
+
+// scored are the elements whose text counts as paragraphs, divs only when they hold no other blocks
+func scored(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		return true
+	case atom.Div:
+		for _, c := range elements(n) {
+			if c != n && blocks[c.DataAtom] && c.DataAtom != atom.Br {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// initialScore is what an element is worth before its paragraphs are counted
+func initialScore(n *html.Node) float64 {
+	var score float64
+
+	switch n.DataAtom {
+	case atom.Article:
+		score = 10
+	case atom.Div, atom.Main, atom.Section:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+
+	class, _ := attr(n, "class")
+	id, _ := attr(n, "id")
+
+	for _, name := range []string{class, id} {
+		if name == "" {
+			continue
+		}
+
+		if negativeWeight.MatchString(name) {
+			score -= 25
+		}
+
+		if positiveWeight.MatchString(name) {
+			score += 25
+		}
+	}
+
+	return score
+}
+
+// linkDensity is the share of the text of n that is in links
+func linkDensity(n *html.Node) float64 {
+	length := utf8.RuneCountInString(innerText(n))
+	if length == 0 {
+		return 0
+	}
+
+	var links int
+
+	for _, a := range elements(n) {
+		if a.DataAtom == atom.A {
+			links += utf8.RuneCountInString(innerText(a))
+		}
+	}
+
+	return float64(links) / float64(length)
+}
+
+// innerText is the text of n with its whitespace collapsed
+func innerText(n *html.Node) string {
+	var b strings.Builder
+
+	var walk func(*html.Node)
+
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	walk(n)
+
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// paragraphs is the text of n with a blank line between its blocks, the text of pre elements keeps its lines
+func paragraphs(n *html.Node) string {
+	var (
+		out     []string
+		current strings.Builder
+	)
+
+	flush := func() {
+		if text := strings.Join(strings.Fields(current.String()), " "); text != "" {
+			out = append(out, text)
+		}
+
+		current.Reset()
+	}
+
+	var walk func(*html.Node)
+
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			current.WriteString(n.Data)
+			return
+		case n.Type == html.ElementNode && n.DataAtom == atom.Pre:
+			flush()
+
+			if text := strings.Trim(innerTextRaw(n), "\n"); strings.TrimSpace(text) != "" {
+				out = append(out, text)
+			}
+
+			return
+		case n.Type == html.ElementNode && blocks[n.DataAtom]:
+			flush()
+
+			defer flush()
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	walk(n)
+
+	flush()
+
+	return strings.Join(out, "\n\n")
+}
+
+// innerTextRaw is the text of n as it is in the page
+func innerTextRaw(n *html.Node) string {
+	var b strings.Builder
+
+	var walk func(*html.Node)
+
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	walk(n)
+
+	return b.String()
+}
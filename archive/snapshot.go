@@ -0,0 +1,466 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxImportDepth is how deep stylesheets importing stylesheets are followed
+const maxImportDepth = 3
+
+var (
+	cssURL    = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)"'\s]*))\s*\)`)
+	cssImport = regexp.MustCompile(`@import\s+(?:url\(\s*)?(?:"([^"]*)"|'([^']*)'|([^)"'\s;]+))\s*\)?\s*([^;]*);`)
+)
+
+// removed are the elements a snapshot does without: scripts would change or break it once its assets are inlined,
+// embedded pages are not downloaded
+var removed = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Noscript: true,
+	atom.Iframe:   true,
+	atom.Frame:    true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Applet:   true,
+	atom.Base:     true,
+	atom.Template: true,
+}
+
+// snapshot inlines the assets of a page as data uris
+type snapshot struct {
+	ctx    context.Context
+	client *http.Client
+	// assets are the data uris of the assets downloaded so far, or their urls when they could not be
+	assets map[string]string
+	size   int
+}
+
+// Snapshot returns page, the html of the page at pageURL as rendered by the browser, as a single file. Stylesheets,
+// images and fonts are inlined, scripts and embedded pages are taken out and links made absolute. Assets that cannot
+// be downloaded are left linked to.
+func Snapshot(ctx context.Context, client *http.Client, pageURL string, page string) ([]byte, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := html.ParseWithOptions(strings.NewReader(page), html.ParseOptionEnableScripting(false))
+	if err != nil {
+		return nil, err
+	}
+
+	if href, ok := baseHref(doc); ok {
+		if u, err := base.Parse(href); err == nil {
+			base = u
+		}
+	}
+
+	s := &snapshot{
+		ctx:    ctx,
+		client: client,
+		assets: make(map[string]string),
+	}
+
+	for _, n := range elements(doc) {
+		// what was in a removed element is gone with it
+		if attached(n, doc) {
+			s.element(n, base)
+		}
+	}
+
+	setCharset(doc)
+
+	var b bytes.Buffer
+
+	if err := html.Render(&b, doc); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// elements lists the elements of n in document order, so they can be changed or removed while going through them
+func elements(n *html.Node) []*html.Node {
+	var nodes []*html.Node
+
+	var walk func(*html.Node)
+
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			nodes = append(nodes, n)
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+
+	walk(n)
+
+	return nodes
+}
+
+func attached(n, doc *html.Node) bool {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+
+	return n == doc
+}
+
+func baseHref(doc *html.Node) (string, bool) {
+	for _, n := range elements(doc) {
+		if n.DataAtom == atom.Base {
+			if href, ok := attr(n, "href"); ok {
+				return href, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+
+	return "", false
+}
+
+func setAttr(n *html.Node, key, val string) {
+	for i, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+func removeAttr(n *html.Node, keys ...string) {
+	attrs := n.Attr[:0]
+
+	for _, a := range n.Attr {
+		keep := true
+
+		for _, key := range keys {
+			if a.Key == key {
+				keep = false
+			}
+		}
+
+		if keep {
+			attrs = append(attrs, a)
+		}
+	}
+
+	n.Attr = attrs
+}
+
+func remove(n *html.Node) {
+	if n.Parent != nil {
+		n.Parent.RemoveChild(n)
+	}
+}
+
+func (s *snapshot) element(n *html.Node, base *url.URL) {
+	if removed[n.DataAtom] {
+		remove(n)
+		return
+	}
+
+	// event handlers are scripts too
+	var handlers []string
+
+	for _, a := range n.Attr {
+		if strings.HasPrefix(strings.ToLower(a.Key), "on") {
+			handlers = append(handlers, a.Key)
+		}
+	}
+
+	removeAttr(n, handlers...)
+
+	if style, ok := attr(n, "style"); ok {
+		setAttr(n, "style", s.css(style, base, 0))
+	}
+
+	switch n.DataAtom {
+	case atom.Meta:
+		equiv, _ := attr(n, "http-equiv")
+
+		// a refresh would navigate away from the snapshot and the page's policy would block the inlined assets
+		if strings.EqualFold(equiv, "refresh") || strings.EqualFold(equiv, "content-security-policy") {
+			remove(n)
+		}
+	case atom.Link:
+		s.link(n, base)
+	case atom.Style:
+		if n.FirstChild != nil && n.FirstChild.Type == html.TextNode {
+			n.FirstChild.Data = s.css(n.FirstChild.Data, base, 0)
+		}
+	case atom.Img:
+		src, _ := attr(n, "src")
+
+		// lazily loaded images that were never scrolled to only have a srcset
+		if srcset, ok := attr(n, "srcset"); ok && (src == "" || strings.HasPrefix(src, "data:")) {
+			if candidate := firstCandidate(srcset); candidate != "" {
+				src = candidate
+			}
+		}
+
+		removeAttr(n, "srcset", "sizes", "loading")
+
+		if src != "" {
+			setAttr(n, "src", s.asset(src, base))
+		}
+	case atom.Source:
+		// the img of a picture is kept, the sources would have to be downloaded in every size
+		if n.Parent.DataAtom == atom.Picture {
+			remove(n)
+			return
+		}
+
+		s.absolute(n, "src", base)
+	case atom.Video:
+		s.inline(n, "poster", base)
+		s.absolute(n, "src", base)
+	case atom.Audio, atom.Track:
+		s.absolute(n, "src", base)
+	case atom.Input:
+		s.inline(n, "src", base)
+	case atom.Image:
+		// svg images
+		s.inline(n, "href", base)
+
+		for i, a := range n.Attr {
+			if a.Namespace == "xlink" && a.Key == "href" {
+				n.Attr[i].Val = s.asset(a.Val, base)
+			}
+		}
+	case atom.A, atom.Area:
+		if href, ok := attr(n, "href"); ok && strings.HasPrefix(strings.ToLower(strings.TrimSpace(href)), "javascript:") {
+			removeAttr(n, "href")
+		}
+
+		s.absolute(n, "href", base)
+	case atom.Form:
+		s.absolute(n, "action", base)
+	}
+}
+
+// link inlines stylesheets and icons, links to what the browser would have fetched ahead of time are dropped
+func (s *snapshot) link(n *html.Node, base *url.URL) {
+	rel, _ := attr(n, "rel")
+	href, _ := attr(n, "href")
+
+	rels := strings.Fields(strings.ToLower(rel))
+
+	has := func(want string) bool {
+		for _, r := range rels {
+			if r == want {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch {
+	case has("stylesheet") && !has("alternate"):
+		u, err := base.Parse(strings.TrimSpace(href))
+		if err != nil || href == "" {
+			remove(n)
+			return
+		}
+
+		css, ok := s.stylesheet(u, 0)
+		if !ok {
+			s.absolute(n, "href", base)
+			return
+		}
+
+		style := &html.Node{Type: html.ElementNode, Data: "style", DataAtom: atom.Style}
+
+		if media, ok := attr(n, "media"); ok {
+			style.Attr = append(style.Attr, html.Attribute{Key: "media", Val: media})
+		}
+
+		// the stylesheet must not be able to end the element it is put in
+		style.AppendChild(&html.Node{Type: html.TextNode, Data: strings.ReplaceAll(css, "</", `<\/`)})
+
+		n.Parent.InsertBefore(style, n)
+
+		remove(n)
+	case has("icon") || has("apple-touch-icon"):
+		s.inline(n, "href", base)
+	case has("preload") || has("prefetch") || has("modulepreload") || has("preconnect") || has("dns-prefetch") || has("manifest"):
+		remove(n)
+	default:
+		s.absolute(n, "href", base)
+	}
+}
+
+// stylesheet downloads the stylesheet at u with what it imports and its assets inlined
+func (s *snapshot) stylesheet(u *url.URL, depth int) (string, bool) {
+	if depth > maxImportDepth || s.size >= maxSnapshotSize {
+		return "", false
+	}
+
+	body, _, err := fetch(s.ctx, s.client, u.String())
+	if err != nil {
+		return "", false
+	}
+
+	s.size += len(body)
+
+	return s.css(string(body), u, depth), true
+}
+
+// css inlines the assets of a stylesheet or style attribute, urls in it are relative to base
+func (s *snapshot) css(css string, base *url.URL, depth int) string {
+	css = cssImport.ReplaceAllStringFunc(css, func(rule string) string {
+		m := cssImport.FindStringSubmatch(rule)
+
+		ref := m[1] + m[2] + m[3]
+
+		u, err := base.Parse(strings.TrimSpace(ref))
+		if err != nil {
+			return rule
+		}
+
+		imported, ok := s.stylesheet(u, depth+1)
+		if !ok {
+			return strings.Replace(rule, ref, u.String(), 1)
+		}
+
+		if media := strings.TrimSpace(m[4]); media != "" {
+			return "@media " + media + " {\n" + imported + "\n}"
+		}
+
+		return imported
+	})
+
+	return cssURL.ReplaceAllStringFunc(css, func(ref string) string {
+		m := cssURL.FindStringSubmatch(ref)
+
+		value := m[1] + m[2] + m[3]
+
+		// references to svg elements in the page
+		if value == "" || strings.HasPrefix(value, "#") {
+			return ref
+		}
+
+		return `url("` + strings.ReplaceAll(s.asset(value, base), `"`, `%22`) + `")`
+	})
+}
+
+// asset returns the data uri of the asset at ref, or its absolute url when it cannot be downloaded
+func (s *snapshot) asset(ref string, base *url.URL) string {
+	ref = strings.TrimSpace(ref)
+
+	if strings.HasPrefix(ref, "data:") {
+		return ref
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ref
+	}
+
+	u.Fragment = ""
+
+	key := u.String()
+
+	if uri, ok := s.assets[key]; ok {
+		return uri
+	}
+
+	s.assets[key] = key
+
+	if s.size >= maxSnapshotSize {
+		return key
+	}
+
+	body, contentType, err := fetch(s.ctx, s.client, key)
+	if err != nil {
+		return key
+	}
+
+	s.size += len(body)
+
+	s.assets[key] = "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(body)
+
+	return s.assets[key]
+}
+
+func (s *snapshot) inline(n *html.Node, key string, base *url.URL) {
+	if ref, ok := attr(n, key); ok && ref != "" {
+		setAttr(n, key, s.asset(ref, base))
+	}
+}
+
+func (s *snapshot) absolute(n *html.Node, key string, base *url.URL) {
+	ref, ok := attr(n, key)
+	if !ok || ref == "" || strings.HasPrefix(ref, "#") {
+		return
+	}
+
+	if u, err := base.Parse(strings.TrimSpace(ref)); err == nil {
+		setAttr(n, key, u.String())
+	}
+}
+
+// firstCandidate is the url of the first image in a srcset
+func firstCandidate(srcset string) string {
+	first, _, _ := strings.Cut(srcset, ",")
+
+	fields := strings.Fields(first)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return fields[0]
+}
+
+// setCharset declares the snapshot utf-8, which is what html.Render writes whatever the page was in
+func setCharset(doc *html.Node) {
+	var head *html.Node
+
+	for _, n := range elements(doc) {
+		if n.DataAtom == atom.Head && head == nil {
+			head = n
+		}
+
+		if n.DataAtom != atom.Meta {
+			continue
+		}
+
+		_, hasCharset := attr(n, "charset")
+		equiv, _ := attr(n, "http-equiv")
+
+		if hasCharset || strings.EqualFold(equiv, "content-type") {
+			remove(n)
+		}
+	}
+
+	if head == nil {
+		return
+	}
+
+	meta := &html.Node{Type: html.ElementNode, Data: "meta", DataAtom: atom.Meta, Attr: []html.Attribute{{Key: "charset", Val: "utf-8"}}}
+
+	head.InsertBefore(meta, head.FirstChild)
+}
@@ -9,26 +9,45 @@ import (
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
+// TokenType tells access tokens from refresh tokens, each is only accepted where that type is expected
+type TokenType string
+
+const (
+	AccessToken  TokenType = "access"
+	RefreshToken TokenType = "refresh"
+)
+
+// ErrWrongTokenType means a token was presented where another type of token is expected, like a refresh token as
+// a bearer token
+var ErrWrongTokenType = errors.New("wrong type of token")
+
 type PayLoad struct {
 	ID        string    `json:"id"`
+	TokenType TokenType `json:"token_type"`
 	AccountID int64     `json:"account_id"`
+	SessionID string    `json:"session_id"`
 	IssuedAt  time.Time `json:"issued_at"`
 	Expiry    time.Time `json:"expiry"`
+	// only set for personal access tokens, which are never signed into a PASETO token
+	TokenID int64    `json:"-"`
+	Scopes  []string `json:"-"`
 }
 
-func newPayload(accountID int64, issuedAt, expiry time.Time) *PayLoad {
+func newPayload(tokenType TokenType, accountID int64, sessionID string, issuedAt, expiry time.Time) *PayLoad {
 	return &PayLoad{
 		ID:        uuid.NewString(),
+		TokenType: tokenType,
 		AccountID: accountID,
+		SessionID: sessionID,
 		IssuedAt:  issuedAt,
 		Expiry:    expiry,
 	}
 }
 
-func CreateToken(accountID int64, issuedAt time.Time, duration time.Duration) (string, *PayLoad, error) {
+func CreateToken(tokenType TokenType, accountID int64, sessionID string, issuedAt time.Time, duration time.Duration) (string, *PayLoad, error) {
 	expiry := time.Now().UTC().Add(duration)
 
-	payload := newPayload(accountID, issuedAt, expiry)
+	payload := newPayload(tokenType, accountID, sessionID, issuedAt, expiry)
 
 	token := paseto.NewToken()
 
@@ -49,7 +68,8 @@ func CreateToken(accountID int64, issuedAt time.Time, duration time.Duration) (s
 	return signed, payload, nil
 }
 
-func VerifyToken(signed string) (*PayLoad, error) {
+// VerifyToken verifies a token of type tokenType, tokens signed before tokens had a type are of no type and rejected
+func VerifyToken(signed string, tokenType TokenType) (*PayLoad, error) {
 	config, err := util.LoadConfig(".")
 	if err != nil {
 		return nil, nil
@@ -74,6 +94,10 @@ func VerifyToken(signed string) (*PayLoad, error) {
 		return nil, err
 	}
 
+	if payload.TokenType != tokenType {
+		return nil, ErrWrongTokenType
+	}
+
 	if time.Now().UTC().After(payload.Expiry) {
 		err := errors.New("token is expired")
 		return nil, err
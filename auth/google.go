@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+const defaultGoogleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+// clocks are never quite in sync with google's
+const googleClockSkew = time.Minute
+
+var (
+	googleKeys     *KeySet
+	googleKeysOnce sync.Once
+)
+
+type GoogleClaims struct {
+	Issuer        string   `json:"iss"`
+	Audience      string   `json:"aud"`
+	Subject       string   `json:"sub"`
+	Email         string   `json:"email"`
+	EmailVerified JSONBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	Picture       string   `json:"picture"`
+	IssuedAt      int64    `json:"iat"`
+	Expiry        int64    `json:"exp"`
+}
+
+// VerifyGoogleIDToken checks the id token google gave the frontend was signed by google, was issued for this app and
+// has not expired, and that google has verified the email address in it
+func VerifyGoogleIDToken(ctx context.Context, idToken string) (*GoogleClaims, error) {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		return nil, err
+	}
+
+	if config.GoogleClientID == "" {
+		return nil, errors.New("google client id is not configured")
+	}
+
+	googleKeysOnce.Do(func() {
+		source := config.GoogleJWKSURL
+		if source == "" {
+			source = defaultGoogleJWKSURL
+		}
+
+		googleKeys = NewKeySet(source)
+	})
+
+	return verifyGoogleIDToken(ctx, googleKeys, config.GoogleClientID, idToken, time.Now())
+}
+
+// verifyGoogleIDToken checks idToken was signed with one of keys for clientID and had not expired at now
+func verifyGoogleIDToken(ctx context.Context, keys *KeySet, clientID, idToken string, now time.Time) (*GoogleClaims, error) {
+	var claims GoogleClaims
+
+	if err := VerifyJWT(ctx, keys, idToken, &claims); err != nil {
+		return nil, err
+	}
+
+	if claims.Issuer != "accounts.google.com" && claims.Issuer != "https://accounts.google.com" {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidJWT, claims.Issuer)
+	}
+
+	if claims.Audience != clientID {
+		return nil, fmt.Errorf("%w: token was issued for another app", ErrInvalidJWT)
+	}
+
+	if !now.Add(-googleClockSkew).Before(time.Unix(claims.Expiry, 0)) {
+		return nil, fmt.Errorf("%w: token is expired", ErrInvalidJWT)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidJWT)
+	}
+
+	if claims.Email == "" || !bool(claims.EmailVerified) {
+		return nil, fmt.Errorf("%w: email address is not verified", ErrInvalidJWT)
+	}
+
+	return &claims, nil
+}
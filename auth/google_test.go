@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyGoogleIDToken(t *testing.T) {
+	const clientID = "client-id.apps.googleusercontent.com"
+
+	key, keys := newTestKeySet(t)
+
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	claims := func(change func(c map[string]interface{})) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":            "https://accounts.google.com",
+			"aud":            clientID,
+			"sub":            "110169484474386276334",
+			"email":          "jane@example.com",
+			"email_verified": true,
+			"name":           "Jane",
+			"iat":            now.Add(-time.Minute).Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+		}
+
+		if change != nil {
+			change(c)
+		}
+
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		claims  map[string]interface{}
+		wantErr bool
+	}{
+		{"valid", claims(nil), false},
+		{"issuer without scheme", claims(func(c map[string]interface{}) { c["iss"] = "accounts.google.com" }), false},
+		{"email verified as a string", claims(func(c map[string]interface{}) { c["email_verified"] = "true" }), false},
+		{"expired within the clock skew", claims(func(c map[string]interface{}) { c["exp"] = now.Add(-30 * time.Second).Unix() }), false},
+		{"expired beyond the clock skew", claims(func(c map[string]interface{}) { c["exp"] = now.Add(-2 * time.Minute).Unix() }), true},
+		{"wrong issuer", claims(func(c map[string]interface{}) { c["iss"] = "https://accounts.example.com" }), true},
+		{"wrong audience", claims(func(c map[string]interface{}) { c["aud"] = "another-app.apps.googleusercontent.com" }), true},
+		{"missing subject", claims(func(c map[string]interface{}) { delete(c, "sub") }), true},
+		{"email not verified", claims(func(c map[string]interface{}) { c["email_verified"] = false }), true},
+		{"email not verified as a string", claims(func(c map[string]interface{}) { c["email_verified"] = "false" }), true},
+		{"missing email", claims(func(c map[string]interface{}) { delete(c, "email") }), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifyGoogleIDToken(context.Background(), keys, clientID, signJWT(t, key, testKeyID, tt.claims), now)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidJWT) {
+					t.Errorf("verifyGoogleIDToken() = %v, want %v", err, ErrInvalidJWT)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("verifyGoogleIDToken() = %v, want nil", err)
+			}
+
+			if got.Subject != tt.claims["sub"] || got.Email != tt.claims["email"] {
+				t.Errorf("verifyGoogleIDToken() = %+v, want the claims of the token", got)
+			}
+		})
+	}
+
+	t.Run("not signed by google", func(t *testing.T) {
+		token := encodeSegment(t, map[string]string{"alg": "none", "kid": testKeyID}) + "." + encodeSegment(t, claims(nil)) + "."
+
+		if _, err := verifyGoogleIDToken(context.Background(), keys, clientID, token, now); !errors.Is(err, ErrInvalidJWT) {
+			t.Errorf("verifyGoogleIDToken() = %v, want %v", err, ErrInvalidJWT)
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultKeySetTTL = time.Hour
+	// unknown key ids make us refetch the key set, but not more often than this
+	minKeySetRefresh = time.Minute
+)
+
+var ErrInvalidJWT = errors.New("invalid token")
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// KeySet caches the RSA keys published at a JWKS url. The source can also be a path to a local JWKS file, which stands
+// in for the real identity provider in development and tests.
+type KeySet struct {
+	source string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	expiry    time.Time
+	fetchedAt time.Time
+}
+
+func NewKeySet(source string) *KeySet {
+	return &KeySet{
+		source: source,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (k *KeySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+
+	if key, ok := k.keys[kid]; ok && now.Before(k.expiry) {
+		return key, nil
+	}
+
+	// keys get rotated, so a kid we have not seen yet means it is time to look again
+	if k.keys == nil || !now.Before(k.expiry) || now.Sub(k.fetchedAt) >= minKeySetRefresh {
+		if err := k.refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidJWT, kid)
+	}
+
+	return key, nil
+}
+
+func (k *KeySet) refresh(ctx context.Context) error {
+	b, ttl, err := k.read(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch key set: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := json.Unmarshal(b, &set); err != nil {
+		return fmt.Errorf("could not decode key set: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			return fmt.Errorf("could not decode key %q: %w", jwk.Kid, err)
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	k.expiry = k.fetchedAt.Add(ttl)
+
+	return nil
+}
+
+func (k *KeySet) read(ctx context.Context) ([]byte, time.Duration, error) {
+	if !strings.HasPrefix(k.source, "https://") && !strings.HasPrefix(k.source, "http://") {
+		b, err := os.ReadFile(strings.TrimPrefix(k.source, "file://"))
+		return b, defaultKeySetTTL, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.source, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("received %d response code", resp.StatusCode)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return b, maxAge(resp.Header.Get("Cache-Control")), nil
+}
+
+// maxAge honours the max-age the provider sends with its keys
+func maxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		var seconds int
+
+		if _, err := fmt.Sscanf(strings.TrimSpace(directive), "max-age=%d", &seconds); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	return defaultKeySetTTL
+}
+
+func (j jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(j.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(j.E)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("exponent is too large")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+// VerifyJWT checks an RS256 signed JWT against the key set and decodes its claims. Checking what the claims say is up
+// to the caller.
+func VerifyJWT(ctx context.Context, keys *KeySet, token string, claims interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: malformed token", ErrInvalidJWT)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return err
+	}
+
+	// only accept the algorithm we expect, never whatever the token asks for
+	if header.Alg != "RS256" {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidJWT, header.Alg)
+	}
+
+	key, err := keys.key(ctx, header.Kid)
+	if err != nil {
+		return err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidJWT)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return fmt.Errorf("%w: bad signature", ErrInvalidJWT)
+	}
+
+	return decodeSegment(parts[1], claims)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidJWT)
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidJWT)
+	}
+
+	return nil
+}
+
+// JSONBool reads booleans that identity providers sometimes send as strings
+type JSONBool bool
+
+func (f *JSONBool) UnmarshalJSON(b []byte) error {
+	var v interface{}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case bool:
+		*f = JSONBool(v)
+	case string:
+		*f = v == "true"
+	default:
+		*f = false
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKeyID = "test-key"
+
+// newTestKeySet generates an RSA key and publishes it under testKeyID in a JWKS file, the way the identity provider
+// would at its JWKS url
+func newTestKeySet(t *testing.T) (*rsa.PrivateKey, *KeySet) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := map[string]interface{}{
+		"keys": []jsonWebKey{
+			{Kid: "encryption-key", Kty: "RSA", Use: "enc", N: "AQAB", E: "AQAB"},
+			{
+				Kid: testKeyID,
+				Kty: "RSA",
+				Alg: "RS256",
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			},
+		},
+	}
+
+	b, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return key, NewKeySet("file://" + path)
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// signJWT signs claims with key using RS256 under kid
+func signJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims interface{}) string {
+	t.Helper()
+
+	signed := encodeSegment(t, map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + encodeSegment(t, claims)
+
+	digest := sha256.Sum256([]byte(signed))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifyJWT(t *testing.T) {
+	key, keys := newTestKeySet(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := map[string]string{"sub": "1234"}
+
+	valid := signJWT(t, key, testKeyID, claims)
+
+	// HS256 signed with the public key, which a verifier trusting the header would take for the shared secret
+	hs256 := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT", "kid": testKeyID}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, x509.MarshalPKCS1PublicKey(&key.PublicKey))
+	mac.Write([]byte(hs256))
+	hs256 += "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"alg none", encodeSegment(t, map[string]string{"alg": "none", "kid": testKeyID}) + "." + parts[1] + "."},
+		{"HS256", hs256},
+		{"unknown kid", signJWT(t, key, "another-key", claims)},
+		{"key not for signing", signJWT(t, key, "encryption-key", claims)},
+		{"signed with another key", signJWT(t, otherKey, testKeyID, claims)},
+		{"claims changed after signing", parts[0] + "." + encodeSegment(t, map[string]string{"sub": "5678"}) + "." + parts[2]},
+		{"signature missing", parts[0] + "." + parts[1] + "."},
+		{"two segments", parts[0] + "." + parts[1]},
+		{"not base64", "!!!." + parts[1] + "." + parts[2]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+
+			if err := VerifyJWT(context.Background(), keys, tt.token, &got); !errors.Is(err, ErrInvalidJWT) {
+				t.Errorf("VerifyJWT() = %v, want %v", err, ErrInvalidJWT)
+			}
+		})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		var got map[string]string
+
+		if err := VerifyJWT(context.Background(), keys, valid, &got); err != nil {
+			t.Fatalf("VerifyJWT() = %v, want nil", err)
+		}
+
+		if got["sub"] != "1234" {
+			t.Errorf("sub = %q, want %q", got["sub"], "1234")
+		}
+	})
+}
+
+func TestJSONBool(t *testing.T) {
+	tests := []struct {
+		json string
+		want JSONBool
+	}{
+		{`true`, true},
+		{`false`, false},
+		{`"true"`, true},
+		{`"false"`, false},
+		{`"yes"`, false},
+		{`1`, false},
+		{`null`, false},
+	}
+
+	for _, tt := range tests {
+		var got JSONBool
+
+		if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+			t.Fatalf("Unmarshal(%s) = %v", tt.json, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.json, got, tt.want)
+		}
+	}
+}
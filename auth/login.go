@@ -2,66 +2,167 @@ package auth
 
 import (
 	"context"
+	"database/sql"
 	"errors"
-	"log"
+	"net"
 	"net/http"
 	"time"
 
-	"github.com/jackc/pgconn"
+	"github.com/google/uuid"
 	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
-func LoginUser(account sqlc.Account, q *sqlc.Queries, ctx context.Context) (string, string, http.Cookie) {
+type sessionTokens struct {
+	accessToken         string
+	refreshToken        string
+	refreshTokenPayload *PayLoad
+}
+
+func createSessionTokens(accountID int64, sessionID string) (*sessionTokens, error) {
 	config, err := util.LoadConfig(".")
 	if err != nil {
-		log.Fatalf("could not load config at login.go: %v", err)
-		return "", "", http.Cookie{}
+		return nil, err
 	}
 
-	accessToken, accessTokenPayload, err := CreateToken(account.ID, time.Now().UTC(), config.Access_Token_Duration)
+	accessToken, accessTokenPayload, err := CreateToken(AccessToken, accountID, sessionID, time.Now().UTC(), config.Access_Token_Duration)
 	if err != nil {
-		log.Fatalf("could not create access token at login.go: %v", err)
-		return "", "", http.Cookie{}
+		return nil, err
 	}
 
-	refreshToken, refreshTokenPayload, err := CreateToken(account.ID, accessTokenPayload.IssuedAt, config.Refresh_Token_Duration)
+	refreshToken, refreshTokenPayload, err := CreateToken(RefreshToken, accountID, sessionID, accessTokenPayload.IssuedAt, config.Refresh_Token_Duration)
 	if err != nil {
-		log.Fatalf("could not create refresh token at login.go: %v", err)
-		return "", "", http.Cookie{}
+		return nil, err
 	}
 
-	refreshTokenCookie := http.Cookie{
+	return &sessionTokens{
+		accessToken:         accessToken,
+		refreshToken:        refreshToken,
+		refreshTokenPayload: refreshTokenPayload,
+	}, nil
+}
+
+func (s *sessionTokens) cookie() http.Cookie {
+	return http.Cookie{
 		Name:     "refreshTokenCookie",
-		Value:    refreshToken,
+		Value:    s.refreshToken,
 		Path:     "/",
-		Expires:  refreshTokenPayload.Expiry,
+		Expires:  s.refreshTokenPayload.Expiry,
 		Secure:   true,
-		SameSite: http.SameSite(http.SameSiteStrictMode),
+		SameSite: http.SameSiteStrictMode,
 		HttpOnly: true,
 	}
+}
+
+// ErrAccountSuspended means an admin suspended the account, it cannot sign in until it is unsuspended
+var ErrAccountSuspended = errors.New("account has been suspended")
+
+// LoginUser starts a new session for the device the request came from, signing in on one device leaves the sessions
+// on every other device alone. It returns the access token, the refresh token and the refresh token cookie.
+func LoginUser(r *http.Request, q *sqlc.Queries, account sqlc.Account) (string, string, http.Cookie, error) {
+	if account.SuspendedAt.Valid {
+		return "", "", http.Cookie{}, ErrAccountSuspended
+	}
+
+	tokens, err := createSessionTokens(account.ID, uuid.NewString())
+	if err != nil {
+		return "", "", http.Cookie{}, err
+	}
 
-	createAccountSessionParams := sqlc.CreateAccountSessionParams{
-		RefreshTokenID: refreshTokenPayload.ID,
+	_, err = q.CreateAccountSession(r.Context(), sqlc.CreateAccountSessionParams{
+		SessionID:      tokens.refreshTokenPayload.SessionID,
+		RefreshTokenID: tokens.refreshTokenPayload.ID,
 		AccountID:      account.ID,
-		IssuedAt:       refreshTokenPayload.IssuedAt,
-		Expiry:         refreshTokenPayload.Expiry,
-		UserAgent:      "",
-		ClientIp:       "",
+		IssuedAt:       tokens.refreshTokenPayload.IssuedAt,
+		Expiry:         tokens.refreshTokenPayload.Expiry,
+		UserAgent:      r.UserAgent(),
+		ClientIp:       ClientIP(r),
+	})
+	if err != nil {
+		return "", "", http.Cookie{}, err
+	}
+
+	return tokens.accessToken, tokens.refreshToken, tokens.cookie(), nil
+}
+
+// ErrRefreshTokenReused means a refresh token was presented after it had already been swapped for a newer one. Either
+// the user or whoever stole the token is holding a stale copy, there is no telling which so the session has to go.
+var ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
+// RefreshSession swaps a session's refresh token for a new one, every refresh token can only be used once
+func RefreshSession(r *http.Request, q *sqlc.Queries, session sqlc.AccountSession, refreshTokenID string) (string, string, http.Cookie, error) {
+	if session.RefreshTokenID != refreshTokenID {
+		return "", "", http.Cookie{}, ErrRefreshTokenReused
 	}
 
-	_, err = q.CreateAccountSession(ctx, createAccountSessionParams)
+	tokens, err := createSessionTokens(session.AccountID, session.SessionID)
 	if err != nil {
-		var pgErr *pgconn.PgError
+		return "", "", http.Cookie{}, err
+	}
 
-		if errors.As(err, &pgErr) {
-			log.Fatalf("could not create account session at login.go: %v", pgErr)
-			return "", "", http.Cookie{}
+	_, err = q.RotateAccountSession(r.Context(), sqlc.RotateAccountSessionParams{
+		RefreshTokenID:   tokens.refreshTokenPayload.ID,
+		IssuedAt:         tokens.refreshTokenPayload.IssuedAt,
+		Expiry:           tokens.refreshTokenPayload.Expiry,
+		UserAgent:        r.UserAgent(),
+		ClientIp:         ClientIP(r),
+		SessionID:        session.SessionID,
+		RefreshTokenID_2: refreshTokenID,
+	})
+	if err != nil {
+		// another request rotated the token between reading the session and updating it
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", http.Cookie{}, ErrRefreshTokenReused
 		}
 
-		log.Fatalf("could not create account session at auth/login.go: %v", err)
-		return "", "", http.Cookie{}
+		return "", "", http.Cookie{}, err
+	}
+
+	return tokens.accessToken, tokens.refreshToken, tokens.cookie(), nil
+}
+
+// ClearRefreshTokenCookie overwrites the refresh token cookie with one the browser deletes straight away
+func ClearRefreshTokenCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refreshTokenCookie",
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		HttpOnly: true,
+	})
+}
+
+// ActiveSession returns the session a token was issued for, as long as it has not been revoked or expired
+func ActiveSession(ctx context.Context, q *sqlc.Queries, payload *PayLoad) (sqlc.AccountSession, bool, error) {
+	if payload.SessionID == "" {
+		return sqlc.AccountSession{}, false, nil
+	}
+
+	session, err := q.GetAccountSession(ctx, payload.SessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sqlc.AccountSession{}, false, nil
+		}
+
+		return sqlc.AccountSession{}, false, err
+	}
+
+	if session.AccountID != payload.AccountID || !time.Now().UTC().Before(session.Expiry) {
+		return sqlc.AccountSession{}, false, nil
+	}
+
+	return session, true, nil
+}
+
+// ClientIP is the address the request came from, the router's RealIP middleware has already applied the headers of
+// trusted proxies
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
 	}
 
-	return accessToken, refreshToken, refreshTokenCookie
+	return host
 }
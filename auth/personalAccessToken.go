@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, PASETO tokens start with v4.public.
+const PersonalAccessTokenPrefix = "lsp_"
+
+// Scopes are what a personal access token can be allowed to do, each resource has a read and a write scope
+var Scopes = []string{
+	"links:read",
+	"links:write",
+	"folders:read",
+	"folders:write",
+	"tags:read",
+	"tags:write",
+}
+
+var ErrInvalidPersonalAccessToken = errors.New("invalid personal access token")
+
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+func HashPersonalAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewPersonalAccessToken returns a new token and the start of it that is kept to tell tokens apart
+func NewPersonalAccessToken() (token, prefix string, err error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token = PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return token, token[:len(PersonalAccessTokenPrefix)+6], nil
+}
+
+// HasScope is always true for signed in sessions, they can do everything the account can
+func (p *PayLoad) HasScope(scope string) bool {
+	if p.TokenID == 0 {
+		return true
+	}
+
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// VerifyPersonalAccessToken looks a token up by its hash and returns a payload for the account it belongs to
+func VerifyPersonalAccessToken(ctx context.Context, q *sqlc.Queries, token string) (*PayLoad, error) {
+	pat, err := q.GetPersonalAccessTokenByHash(ctx, HashPersonalAccessToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidPersonalAccessToken
+		}
+
+		return nil, err
+	}
+
+	if pat.ExpiresAt.Valid && !time.Now().UTC().Before(pat.ExpiresAt.Time) {
+		return nil, errors.New("personal access token is expired")
+	}
+
+	if err := q.TouchPersonalAccessToken(ctx, pat.TokenID); err != nil {
+		return nil, err
+	}
+
+	return &PayLoad{
+		ID:        strconv.FormatInt(pat.TokenID, 10),
+		AccountID: pat.AccountID,
+		IssuedAt:  pat.TokenCreatedAt,
+		Expiry:    pat.ExpiresAt.Time,
+		TokenID:   pat.TokenID,
+		Scopes:    strings.Split(pat.Scopes, ","),
+	}, nil
+}
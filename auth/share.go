@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// SharePayload lets an anonymous visitor who knows the password of a public link browse it.
+// It is stored under its own claim so it can never be mistaken for a login token.
+type SharePayload struct {
+	ShareToken string    `json:"share_token"`
+	IssuedAt   time.Time `json:"issued_at"`
+	Expiry     time.Time `json:"expiry"`
+}
+
+func CreateShareAccessToken(shareToken string, expiry time.Time) (string, *SharePayload, error) {
+	payload := &SharePayload{
+		ShareToken: shareToken,
+		IssuedAt:   time.Now().UTC(),
+		Expiry:     expiry,
+	}
+
+	token := paseto.NewToken()
+
+	token.SetExpiration(expiry)
+	token.SetIssuedAt(payload.IssuedAt)
+
+	token.Set("share", payload)
+
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		return "", nil, err
+	}
+
+	secretKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(config.SecretKeyHex)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token.V4Sign(secretKey, nil), payload, nil
+}
+
+func VerifyShareAccessToken(signed string) (*SharePayload, error) {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := paseto.NewV4AsymmetricPublicKeyFromHex(config.PublicKeyHex)
+	if err != nil {
+		return nil, errors.New("something went wrong")
+	}
+
+	token, err := paseto.NewParser().ParseV4Public(publicKey, signed, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload SharePayload
+
+	if err := token.Get("share", &payload); err != nil {
+		return nil, err
+	}
+
+	if time.Now().UTC().After(payload.Expiry) {
+		return nil, errors.New("token is expired")
+	}
+
+	return &payload, nil
+}
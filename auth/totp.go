@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RFC 6238 defaults, they are what authenticator apps assume when the otpauth uri leaves them out
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	// codes from the step before and after the current one are accepted too, phone clocks drift
+	totpSkewSteps = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func NewTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI is what authenticator apps scan from the enrollment qr code
+func TOTPURI(issuer, accountName, secret string) string {
+	q := url.Values{}
+
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(totpPeriod))
+
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+accountName) + "?" + q.Encode()
+}
+
+// ValidateTOTP checks a code against the secret and returns the time step it was generated for. Callers must refuse
+// steps at or before the last one they accepted, otherwise a code could be used twice.
+func ValidateTOTP(secret, code string, now time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.ReplaceAll(code, " ", "")
+
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+
+	for step := current - totpSkewSteps; step <= current+totpSkewSteps; step++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// hotp is RFC 4226
+func hotp(key []byte, counter int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// NewRecoveryCode returns a code like "k3j9d-8x2mq", it is shown to the user once and only its hash is kept
+func NewRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+
+	return code[:5] + "-" + code[5:], nil
+}
+
+// HashRecoveryCode is sha256 rather than bcrypt, recovery codes are random so there is nothing to brute force and the
+// hash can be looked up directly
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of RFC 4226 and RFC 6238, "12345678901234567890", in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226 Appendix D
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, code := range want {
+		if got := hotp([]byte("12345678901234567890"), int64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	// RFC 6238 Appendix B, SHA1, the last six of the eight digits given there
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, v := range vectors {
+		now := time.Unix(v.unix, 0)
+
+		step, ok := ValidateTOTP(rfcSecret, v.code, now)
+		if !ok {
+			t.Errorf("ValidateTOTP(%s) at %d was refused", v.code, v.unix)
+			continue
+		}
+
+		if want := v.unix / totpPeriod; step != want {
+			t.Errorf("ValidateTOTP(%s) at %d = step %d, want %d", v.code, v.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	// the code of step 37037036, the one 1111111109 is in
+	const code = "081804"
+
+	at := time.Unix(1111111109, 0)
+
+	tests := []struct {
+		name  string
+		steps int
+		want  bool
+	}{
+		{"same step", 0, true},
+		{"one step later", 1, true},
+		{"one step earlier", -1, true},
+		{"two steps later", 2, false},
+		{"two steps earlier", -2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := at.Add(time.Duration(tt.steps*totpPeriod) * time.Second)
+
+			step, ok := ValidateTOTP(rfcSecret, code, now)
+			if ok != tt.want {
+				t.Fatalf("ValidateTOTP() at %+d steps = %v, want %v", tt.steps, ok, tt.want)
+			}
+
+			if ok && step != 1111111109/totpPeriod {
+				t.Errorf("ValidateTOTP() = step %d, want the step the code was made for", step)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		want   bool
+	}{
+		{"code with a space", rfcSecret, "287 082", true},
+		{"lower case secret", strings.ToLower(rfcSecret), "287082", true},
+		{"wrong code", rfcSecret, "287083", false},
+		{"eight digits", rfcSecret, "94287082", false},
+		{"too short", rfcSecret, "28708", false},
+		{"empty", rfcSecret, "", false},
+		{"secret not base32", "not base32!", "287082", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, now); ok != tt.want {
+				t.Errorf("ValidateTOTP(%q, %q) = %v, want %v", tt.secret, tt.code, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code, err := NewRecoveryCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(code) != 11 || code[5] != '-' || code != strings.ToLower(code) {
+		t.Errorf("NewRecoveryCode() = %q, want five lower case characters, a dash and five more", code)
+	}
+
+	if HashRecoveryCode(" "+strings.ToUpper(code)+" ") != HashRecoveryCode(code) {
+		t.Errorf("HashRecoveryCode() depends on case or surrounding spaces")
+	}
+
+	other, err := NewRecoveryCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if HashRecoveryCode(other) == HashRecoveryCode(code) {
+		t.Errorf("two recovery codes hash the same")
+	}
+}
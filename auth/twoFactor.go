@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// TwoFactorChallengeDuration is how long a user has to enter their code after getting their password right
+const TwoFactorChallengeDuration = 5 * time.Minute
+
+// TwoFactorChallengePayload proves the first factor of a sign in was passed. It is stored under its own claim so it
+// can never be mistaken for a login token.
+type TwoFactorChallengePayload struct {
+	AccountID int64     `json:"account_id"`
+	IssuedAt  time.Time `json:"issued_at"`
+	Expiry    time.Time `json:"expiry"`
+}
+
+func CreateTwoFactorChallengeToken(accountID int64) (string, *TwoFactorChallengePayload, error) {
+	payload := &TwoFactorChallengePayload{
+		AccountID: accountID,
+		IssuedAt:  time.Now().UTC(),
+		Expiry:    time.Now().UTC().Add(TwoFactorChallengeDuration),
+	}
+
+	token := paseto.NewToken()
+
+	token.SetExpiration(payload.Expiry)
+	token.SetIssuedAt(payload.IssuedAt)
+
+	token.Set("two_factor_challenge", payload)
+
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		return "", nil, err
+	}
+
+	secretKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(config.SecretKeyHex)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token.V4Sign(secretKey, nil), payload, nil
+}
+
+func VerifyTwoFactorChallengeToken(signed string) (*TwoFactorChallengePayload, error) {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := paseto.NewV4AsymmetricPublicKeyFromHex(config.PublicKeyHex)
+	if err != nil {
+		return nil, errors.New("something went wrong")
+	}
+
+	token, err := paseto.NewParser().ParseV4Public(publicKey, signed, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload TwoFactorChallengePayload
+
+	if err := token.Get("two_factor_challenge", &payload); err != nil {
+		return nil, err
+	}
+
+	if time.Now().UTC().After(payload.Expiry) {
+		return nil, errors.New("token is expired")
+	}
+
+	return &payload, nil
+}
@@ -0,0 +1,188 @@
+package dbtest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
+var (
+	folderColumns = []string{"folder_id", "account_id", "folder_name", "path", "label", "starred", "folder_created_at", "folder_updated_at", "subfolder_of", "folder_deleted_at", "textsearchable_index_col"}
+	linkColumns   = []string{"link_id", "link_title", "link_thumbnail", "link_favicon", "link_hostname", "link_url", "link_notes", "account_id", "folder_id", "added_at", "updated_at", "deleted_at", "metadata_status", "textsearchable_index_col"}
+)
+
+// Collections answers the queries roles are resolved with from folders, links and collection members
+type Collections struct {
+	Folders []sqlc.Folder
+	Links   []sqlc.Link
+	Members []sqlc.CollectionMember
+}
+
+// Queries are GetFolder, GetFolderAncestors, GetAccessLevelsOnFolderAndAncestors and GetLink
+func (c Collections) Queries() Queries {
+	return Queries{
+		"GetFolder": func(args []driver.Value) ([]string, [][]driver.Value, error) {
+			folder, ok := c.folder(func(f sqlc.Folder) bool { return f.FolderID == args[0] })
+			if !ok {
+				return folderColumns, nil, nil
+			}
+
+			return folderColumns, [][]driver.Value{folderRow(folder)}, nil
+		},
+		"GetFolderAncestors": func(args []driver.Value) ([]string, [][]driver.Value, error) {
+			folder, ok := c.folder(func(f sqlc.Folder) bool { return f.Label == args[0] })
+			if !ok {
+				return folderColumns, nil, nil
+			}
+
+			ancestors := c.ancestors(folder)
+
+			sort.Slice(ancestors, func(i, j int) bool { return ancestors[i].Path < ancestors[j].Path })
+
+			var result [][]driver.Value
+
+			for _, ancestor := range ancestors {
+				result = append(result, folderRow(ancestor))
+			}
+
+			return folderColumns, result, nil
+		},
+		"GetAccessLevelsOnFolderAndAncestors": func(args []driver.Value) ([]string, [][]driver.Value, error) {
+			folder, ok := c.folder(func(f sqlc.Folder) bool { return f.FolderID == args[1] })
+			if !ok {
+				return []string{"collection_access_level"}, nil, nil
+			}
+
+			var result [][]driver.Value
+
+			for _, ancestor := range c.ancestors(folder) {
+				for _, member := range c.Members {
+					if member.CollectionID == ancestor.FolderID && member.MemberID == args[0] {
+						result = append(result, []driver.Value{string(member.CollectionAccessLevel)})
+					}
+				}
+			}
+
+			return []string{"collection_access_level"}, result, nil
+		},
+		"GetLink": func(args []driver.Value) ([]string, [][]driver.Value, error) {
+			for _, link := range c.Links {
+				if link.LinkID == args[0] {
+					return linkColumns, [][]driver.Value{linkRow(link)}, nil
+				}
+			}
+
+			return linkColumns, nil, nil
+		},
+	}
+}
+
+// Postgres inserts the folders, links and members into a database from Postgres, with an account for every account
+// id they name, so the queries Queries stands in for can be checked against the real sql
+func (c Collections) Postgres(t *testing.T) *sql.Tx {
+	t.Helper()
+
+	tx := Postgres(t)
+
+	accounts := map[int64]bool{}
+
+	for _, f := range c.Folders {
+		accounts[f.AccountID] = true
+	}
+
+	for _, l := range c.Links {
+		accounts[l.AccountID] = true
+	}
+
+	for _, m := range c.Members {
+		accounts[m.MemberID] = true
+	}
+
+	for id := range accounts {
+		if _, err := tx.Exec("INSERT INTO account (id, fullname, email, account_password) VALUES ($1, '', $2, '')", id, fmt.Sprintf("account%d@example.com", id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// parents before their subfolders
+	folders := append([]sqlc.Folder(nil), c.Folders...)
+
+	sort.SliceStable(folders, func(i, j int) bool {
+		return strings.Count(folders[i].Path, ".") < strings.Count(folders[j].Path, ".")
+	})
+
+	for _, f := range folders {
+		if _, err := tx.Exec("INSERT INTO folder (folder_id, account_id, folder_name, path, label, subfolder_of, folder_deleted_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			f.FolderID, f.AccountID, f.FolderName, f.Path, f.Label, f.SubfolderOf, f.FolderDeletedAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, l := range c.Links {
+		if _, err := tx.Exec("INSERT INTO link (link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, deleted_at) VALUES ($1, $2, '', '', '', '', '', $3, $4, $5)",
+			l.LinkID, l.LinkTitle, l.AccountID, l.FolderID, l.DeletedAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, m := range c.Members {
+		if _, err := tx.Exec("INSERT INTO collection_member (collection_id, member_id, collection_access_level) VALUES ($1, $2, $3)",
+			m.CollectionID, m.MemberID, string(m.CollectionAccessLevel)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return tx
+}
+
+func (c Collections) folder(match func(sqlc.Folder) bool) (sqlc.Folder, bool) {
+	for _, folder := range c.Folders {
+		if match(folder) {
+			return folder, true
+		}
+	}
+
+	return sqlc.Folder{}, false
+}
+
+// ancestors are the folders whose path contains the path of folder, folder included
+func (c Collections) ancestors(folder sqlc.Folder) []sqlc.Folder {
+	var ancestors []sqlc.Folder
+
+	for _, f := range c.Folders {
+		if f.Path == folder.Path || strings.HasPrefix(folder.Path, f.Path+".") {
+			ancestors = append(ancestors, f)
+		}
+	}
+
+	return ancestors
+}
+
+func folderRow(f sqlc.Folder) []driver.Value {
+	return []driver.Value{f.FolderID, f.AccountID, f.FolderName, f.Path, f.Label, f.Starred, f.FolderCreatedAt, f.FolderUpdatedAt, nullString(f.SubfolderOf), nullTime(f.FolderDeletedAt), nil}
+}
+
+func linkRow(l sqlc.Link) []driver.Value {
+	return []driver.Value{l.LinkID, l.LinkTitle, l.LinkThumbnail, l.LinkFavicon, l.LinkHostname, l.LinkUrl, l.LinkNotes, l.AccountID, nullString(l.FolderID), l.AddedAt, l.UpdatedAt, nullTime(l.DeletedAt), string(l.MetadataStatus), nil}
+}
+
+func nullString(s sql.NullString) driver.Value {
+	if !s.Valid {
+		return nil
+	}
+
+	return s.String
+}
+
+func nullTime(t sql.NullTime) driver.Value {
+	if !t.Valid {
+		return nil
+	}
+
+	return t.Time
+}
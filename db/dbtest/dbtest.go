@@ -0,0 +1,119 @@
+// Package dbtest is a database/sql driver for tests. Instead of running sql it answers each sqlc query with the func
+// registered under the query's name, so code that takes a *sqlc.Queries can be tested without postgres. Postgres runs
+// the real sql instead for tests of the queries themselves.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// Query answers a query with the columns and rows of its result
+type Query func(args []driver.Value) (columns []string, rows [][]driver.Value, err error)
+
+// Queries are the queries a test database answers, keyed by their sqlc name
+type Queries map[string]Query
+
+var queryName = regexp.MustCompile(`^-- name: (\w+)`)
+
+// Open returns a database answering queries, any other query fails
+func Open(queries Queries) *sql.DB {
+	return sql.OpenDB(connector{queries: queries})
+}
+
+type connector struct {
+	queries Queries
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return conn(c), nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return nil
+}
+
+type conn struct {
+	queries Queries
+}
+
+func (c conn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("dbtest: prepared statements are not supported")
+}
+
+func (c conn) Close() error {
+	return nil
+}
+
+func (c conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("dbtest: transactions are not supported")
+}
+
+func (c conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, result, err := c.answer(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rows{columns: columns, rows: result}, nil
+}
+
+// ExecContext answers :exec queries, the rows answered are the rows affected
+func (c conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	_, result, err := c.answer(query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.RowsAffected(len(result)), nil
+}
+
+func (c conn) answer(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+	m := queryName.FindStringSubmatch(query)
+	if m == nil {
+		return nil, nil, fmt.Errorf("dbtest: query has no sqlc name: %s", query)
+	}
+
+	q, ok := c.queries[m[1]]
+	if !ok {
+		return nil, nil, fmt.Errorf("dbtest: unexpected query %s", m[1])
+	}
+
+	values := make([]driver.Value, len(args))
+
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	return q(values)
+}
+
+type rows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+
+	r.rows = r.rows[1:]
+
+	return nil
+}
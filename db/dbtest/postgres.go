@@ -0,0 +1,83 @@
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+// PostgresEnv names the database Postgres runs tests against, any database the test user may create schemas in
+const PostgresEnv = "TEST_DBSTRING"
+
+// Postgres returns a transaction on the database at PostgresEnv with every migration applied to a schema of its own,
+// so the sql of the queries runs for real. The transaction is rolled back when the test ends and leaves nothing
+// behind. Tests using it are skipped when PostgresEnv is not set.
+func Postgres(t *testing.T) *sql.Tx {
+	t.Helper()
+
+	dsn := os.Getenv(PostgresEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", PostgresEnv)
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { tx.Rollback() })
+
+	schema := fmt.Sprintf("dbtest_%d", time.Now().UnixNano())
+
+	if _, err := tx.Exec(fmt.Sprintf("CREATE SCHEMA %s; SET LOCAL search_path TO %s, public", schema, schema)); err != nil {
+		t.Fatal(err)
+	}
+
+	migrate(t, tx)
+
+	return tx
+}
+
+// migrate runs the up part of every migration in order, the way goose up would
+func migrate(t *testing.T, tx *sql.Tx) {
+	t.Helper()
+
+	_, file, _, _ := runtime.Caller(0)
+
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(file), "..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(files)
+
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		up, _, _ := strings.Cut(string(b), "-- +goose Down")
+
+		// without arguments the statements are sent as one simple query, so a migration can have many
+		if _, err := tx.Exec(up); err != nil {
+			t.Fatalf("could not run migration %s: %v", filepath.Base(f), err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+-- +goose Up
+DROP TABLE IF EXISTS folder_tag;
+DROP TABLE IF EXISTS link_tag;
+DROP TABLE IF EXISTS tag;
+
+CREATE TABLE tag (
+    tag_id BIGSERIAL PRIMARY KEY,
+    account_id BIGSERIAL NOT NULL,
+    tag_name TEXT NOT NULL,
+    tag_created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_account FOREIGN KEY (account_id) REFERENCES account (id) ON DELETE CASCADE,
+    UNIQUE (account_id, tag_name)
+);
+
+CREATE TABLE link_tag (
+    link_id TEXT NOT NULL,
+    tag_id BIGSERIAL NOT NULL,
+    tagged_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_link FOREIGN KEY (link_id) REFERENCES link (link_id) ON DELETE CASCADE,
+    CONSTRAINT fk_tag FOREIGN KEY (tag_id) REFERENCES tag (tag_id) ON DELETE CASCADE,
+    PRIMARY KEY (link_id, tag_id)
+);
+
+CREATE TABLE folder_tag (
+    folder_id TEXT NOT NULL,
+    tag_id BIGSERIAL NOT NULL,
+    tagged_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_folder FOREIGN KEY (folder_id) REFERENCES folder (folder_id) ON DELETE CASCADE,
+    CONSTRAINT fk_tag FOREIGN KEY (tag_id) REFERENCES tag (tag_id) ON DELETE CASCADE,
+    PRIMARY KEY (folder_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS link_tag_tag_id_idx ON link_tag (tag_id);
+CREATE INDEX IF NOT EXISTS folder_tag_tag_id_idx ON folder_tag (tag_id);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS folder_tag;
+DROP TABLE IF EXISTS link_tag;
+DROP TABLE IF EXISTS tag;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+DROP TYPE IF EXISTS link_metadata_status CASCADE;
+
+CREATE TYPE link_metadata_status AS ENUM ('pending', 'ready', 'failed');
+
+ALTER TABLE link ADD COLUMN metadata_status link_metadata_status NOT NULL DEFAULT 'pending';
+
+-- links added before the background workers existed already have their metadata
+UPDATE link SET metadata_status = 'ready';
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE IF EXISTS link DROP COLUMN IF EXISTS metadata_status;
+DROP TYPE IF EXISTS link_metadata_status;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
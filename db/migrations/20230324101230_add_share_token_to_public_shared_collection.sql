@@ -0,0 +1,47 @@
+-- +goose Up
+-- a collection can have several links, each with its own token. Links shared before tokens existed are given one and
+-- their six character passwords, kept in plain text until now, are hashed so they keep working.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+ALTER TABLE public_shared_collection ADD COLUMN share_token TEXT;
+
+UPDATE public_shared_collection SET share_token = gen_random_uuid()::text;
+
+ALTER TABLE public_shared_collection ALTER COLUMN share_token SET NOT NULL;
+
+ALTER TABLE public_shared_collection DROP CONSTRAINT IF EXISTS public_shared_collection_collection_id_key;
+
+ALTER TABLE public_shared_collection DROP CONSTRAINT public_shared_collection_pkey;
+
+ALTER TABLE public_shared_collection ADD PRIMARY KEY (share_token);
+
+ALTER TABLE public_shared_collection ALTER COLUMN collection_password DROP NOT NULL;
+
+ALTER TABLE public_shared_collection ALTER COLUMN collection_password TYPE TEXT USING crypt(trim(collection_password), gen_salt('bf', 10));
+
+ALTER TABLE public_shared_collection ALTER COLUMN collection_shared_by DROP DEFAULT;
+
+CREATE INDEX public_shared_collection_collection_id_idx ON public_shared_collection (collection_id);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS public_shared_collection_collection_id_idx;
+
+-- only the newest link of each collection fits the old table, and only links with a password. Passwords stay hashed.
+DELETE FROM public_shared_collection a USING public_shared_collection b
+WHERE a.collection_id = b.collection_id AND a.collection_shared_at < b.collection_shared_at;
+
+DELETE FROM public_shared_collection WHERE collection_password IS NULL;
+
+ALTER TABLE public_shared_collection ALTER COLUMN collection_password SET NOT NULL;
+
+ALTER TABLE public_shared_collection DROP CONSTRAINT public_shared_collection_pkey;
+
+ALTER TABLE public_shared_collection ADD PRIMARY KEY (collection_id);
+
+ALTER TABLE public_shared_collection DROP COLUMN share_token;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
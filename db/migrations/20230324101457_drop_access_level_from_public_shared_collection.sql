@@ -0,0 +1,12 @@
+-- +goose Up
+-- public links only ever give view access
+ALTER TABLE public_shared_collection DROP COLUMN IF EXISTS collection_access_level;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE public_shared_collection ADD COLUMN collection_access_level collection_access_level NOT NULL DEFAULT 'view';
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
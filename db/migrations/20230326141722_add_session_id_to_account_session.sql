@@ -0,0 +1,28 @@
+-- +goose Up
+-- sessions used to be one row per account, those rows cannot be told apart so everyone signs in again once
+DELETE FROM account_session;
+
+ALTER TABLE account_session DROP CONSTRAINT IF EXISTS account_session_account_id_key;
+
+ALTER TABLE account_session ADD COLUMN session_id TEXT PRIMARY KEY;
+
+ALTER TABLE account_session ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX account_session_account_id_idx ON account_session (account_id);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS account_session_account_id_idx;
+
+DELETE FROM account_session;
+
+ALTER TABLE account_session DROP COLUMN IF EXISTS created_at;
+
+ALTER TABLE account_session DROP COLUMN IF EXISTS session_id;
+
+ALTER TABLE account_session ADD CONSTRAINT account_session_account_id_key UNIQUE (account_id);
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
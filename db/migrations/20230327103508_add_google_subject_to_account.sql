@@ -0,0 +1,12 @@
+-- +goose Up
+-- the subject id google puts in id tokens never changes, unlike the email address on the google account
+ALTER TABLE account ADD COLUMN google_subject TEXT CONSTRAINT google_subject_must_be_unique UNIQUE;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE account DROP COLUMN IF EXISTS google_subject;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
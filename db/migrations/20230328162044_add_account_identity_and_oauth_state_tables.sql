@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE account_identity (
+    identity_id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    identity_email TEXT NOT NULL DEFAULT '',
+    linked_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT identity_subject_must_be_unique UNIQUE (provider, subject),
+    CONSTRAINT one_identity_per_provider UNIQUE (account_id, provider)
+);
+
+-- google sign ins used to be linked on the account itself
+INSERT INTO account_identity (account_id, provider, subject, identity_email)
+SELECT id, 'google', google_subject, email FROM account WHERE google_subject IS NOT NULL;
+
+ALTER TABLE account DROP COLUMN IF EXISTS google_subject;
+
+-- a row per sign in that has been sent off to a provider, the state in the callback has to match one of them
+CREATE TABLE oauth_state (
+    state TEXT PRIMARY KEY,
+    provider TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    -- set when a signed in user is linking a provider rather than signing in with it
+    link_account_id BIGINT REFERENCES account(id) ON DELETE CASCADE,
+    state_expiry TIMESTAMPTZ NOT NULL
+);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS oauth_state;
+
+ALTER TABLE account ADD COLUMN google_subject TEXT CONSTRAINT google_subject_must_be_unique UNIQUE;
+
+UPDATE account SET google_subject = account_identity.subject
+FROM account_identity
+WHERE account_identity.account_id = account.id AND account_identity.provider = 'google';
+
+DROP TABLE IF EXISTS account_identity;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
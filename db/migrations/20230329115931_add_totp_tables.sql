@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE account_totp (
+    account_id BIGINT PRIMARY KEY REFERENCES account(id) ON DELETE CASCADE,
+    totp_secret TEXT NOT NULL,
+    -- two factor authentication is only on once the first code has been confirmed
+    confirmed_at TIMESTAMPTZ,
+    -- codes are only accepted for time steps after this one so no code can be used twice
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    totp_created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE totp_recovery_code (
+    code_id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ
+);
+
+CREATE INDEX totp_recovery_code_account_id_idx ON totp_recovery_code (account_id);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS totp_recovery_code;
+
+DROP TABLE IF EXISTS account_totp;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE webauthn_credential (
+    -- base64url encoded raw credential id
+    credential_id TEXT PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+    -- COSE encoded public key
+    public_key BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    credential_name TEXT NOT NULL,
+    credential_created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX webauthn_credential_account_id_idx ON webauthn_credential (account_id);
+
+-- a row per registration or sign in that has been started, the signed client data has to carry one of them
+CREATE TABLE webauthn_challenge (
+    challenge TEXT PRIMARY KEY,
+    ceremony TEXT NOT NULL,
+    -- set when registering, sign ins do not know the account until the passkey tells us
+    account_id BIGINT REFERENCES account(id) ON DELETE CASCADE,
+    challenge_expiry TIMESTAMPTZ NOT NULL
+);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS webauthn_challenge;
+
+DROP TABLE IF EXISTS webauthn_credential;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
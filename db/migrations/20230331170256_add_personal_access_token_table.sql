@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE personal_access_token (
+    token_id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+    token_name TEXT NOT NULL,
+    -- sha256 of the token, the token itself is only shown once when it is created
+    token_hash TEXT NOT NULL CONSTRAINT token_hash_must_be_unique UNIQUE,
+    -- the start of the token so users can tell their tokens apart
+    token_prefix TEXT NOT NULL,
+    -- comma separated, eg links:read,links:write
+    scopes TEXT NOT NULL,
+    -- tokens without an expiry last until they are revoked
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    token_created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX personal_access_token_account_id_idx ON personal_access_token (account_id);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS personal_access_token;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
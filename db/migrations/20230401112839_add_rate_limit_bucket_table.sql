@@ -0,0 +1,20 @@
+-- +goose Up
+-- token buckets of the rate limiter when it is configured to share them between instances
+CREATE TABLE rate_limit_bucket (
+    bucket_key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL DEFAULT 0,
+    -- a bucket that was never updated is full
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT 'epoch',
+    expires_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX rate_limit_bucket_expires_at_idx ON rate_limit_bucket (expires_at);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS rate_limit_bucket;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
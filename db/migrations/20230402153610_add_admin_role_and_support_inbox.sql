@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE account ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- suspended accounts cannot sign in and their sessions and tokens stop working
+ALTER TABLE account ADD COLUMN suspended_at TIMESTAMPTZ;
+
+ALTER TABLE contact ADD COLUMN sent_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE contact ADD COLUMN resolved_at TIMESTAMPTZ;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE contact DROP COLUMN IF EXISTS resolved_at;
+
+ALTER TABLE contact DROP COLUMN IF EXISTS sent_at;
+
+ALTER TABLE account DROP COLUMN IF EXISTS suspended_at;
+
+ALTER TABLE account DROP COLUMN IF EXISTS is_admin;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
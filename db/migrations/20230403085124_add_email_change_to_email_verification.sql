@@ -0,0 +1,27 @@
+-- +goose Up
+-- codes with an account_id confirm an email change of that account, the others sign in or verify a new account
+ALTER TABLE email_verification ADD COLUMN account_id BIGINT REFERENCES account(id) ON DELETE CASCADE;
+
+ALTER TABLE email_verification DROP CONSTRAINT IF EXISTS email_verification_email_key;
+
+CREATE UNIQUE INDEX email_verification_email_key ON email_verification (email) WHERE account_id IS NULL;
+
+-- one pending email change per account, asking again replaces it
+CREATE UNIQUE INDEX email_verification_account_id_key ON email_verification (account_id) WHERE account_id IS NOT NULL;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DELETE FROM email_verification WHERE account_id IS NOT NULL;
+
+DROP INDEX IF EXISTS email_verification_account_id_key;
+
+DROP INDEX IF EXISTS email_verification_email_key;
+
+ALTER TABLE email_verification ADD CONSTRAINT email_verification_email_key UNIQUE (email);
+
+ALTER TABLE email_verification DROP COLUMN IF EXISTS account_id;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- a deletion is requested with a code sent by email, once confirmed the account is deleted after delete_after
+CREATE TABLE account_deletion (
+    account_id BIGINT PRIMARY KEY REFERENCES account(id) ON DELETE CASCADE,
+    code TEXT NOT NULL,
+    code_expiry TIMESTAMPTZ NOT NULL,
+    -- what happens to collections other accounts are members of, 'transfer' hands them to a member
+    shared_collections TEXT NOT NULL DEFAULT 'transfer' CHECK (shared_collections IN ('transfer', 'delete')),
+    delete_after TIMESTAMPTZ,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX account_deletion_delete_after_idx ON account_deletion (delete_after) WHERE delete_after IS NOT NULL;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS account_deletion;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
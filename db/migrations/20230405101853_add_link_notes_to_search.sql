@@ -0,0 +1,16 @@
+-- +goose Up
+-- a generated column cannot be altered, it is dropped and added again with the notes in it
+ALTER TABLE link DROP COLUMN IF EXISTS textsearchable_index_col;
+
+ALTER TABLE link ADD COLUMN textsearchable_index_col tsvector GENERATED ALWAYS AS (to_tsvector('english', link_title || ' ' || link_notes)) STORED;
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE link DROP COLUMN IF EXISTS textsearchable_index_col;
+
+ALTER TABLE link ADD COLUMN textsearchable_index_col tsvector GENERATED ALWAYS AS (to_tsvector('english', link_title)) STORED;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
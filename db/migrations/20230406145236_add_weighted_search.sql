@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- meant for link but created on folder, where folder_name_search_idx already covers the column
+DROP INDEX IF EXISTS link_title_search_idx;
+
+ALTER TABLE link DROP COLUMN IF EXISTS textsearchable_index_col;
+
+-- titles weigh the most, then the site, the rest of the url and the notes. Dots and slashes are taken out so the
+-- words in hosts and urls can be searched on their own.
+ALTER TABLE link ADD COLUMN textsearchable_index_col tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', link_title), 'A') ||
+    setweight(to_tsvector('english', replace(link_hostname, '.', ' ')), 'B') ||
+    setweight(to_tsvector('english', regexp_replace(link_url, '[^[:alnum:]]+', ' ', 'g')), 'C') ||
+    setweight(to_tsvector('english', link_notes), 'D')
+) STORED;
+
+CREATE INDEX link_search_idx ON link USING GIN (textsearchable_index_col);
+
+-- titles and folder names are also matched by similarity so typos still find them
+CREATE INDEX link_title_trgm_idx ON link USING GIN (link_title gin_trgm_ops);
+CREATE INDEX folder_name_trgm_idx ON folder USING GIN (folder_name gin_trgm_ops);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS folder_name_trgm_idx;
+DROP INDEX IF EXISTS link_title_trgm_idx;
+DROP INDEX IF EXISTS link_search_idx;
+
+ALTER TABLE link DROP COLUMN IF EXISTS textsearchable_index_col;
+
+ALTER TABLE link ADD COLUMN textsearchable_index_col tsvector GENERATED ALWAYS AS (to_tsvector('english', link_title || ' ' || link_notes)) STORED;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- a saved search listed next to the folders of its parent, or at the root when it has none. What is in it is found
+-- again every time it is opened.
+CREATE TABLE smart_folder (
+    smart_folder_id TEXT NOT NULL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+    folder_id TEXT REFERENCES folder(folder_id) ON DELETE CASCADE,
+    smart_folder_name TEXT NOT NULL,
+    search_query TEXT NOT NULL,
+    starred BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX smart_folder_account_id_idx ON smart_folder (account_id) WHERE folder_id IS NULL;
+CREATE INDEX smart_folder_folder_id_idx ON smart_folder (folder_id);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS smart_folder;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
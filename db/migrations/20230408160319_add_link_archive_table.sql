@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TYPE link_archive_status AS ENUM ('pending', 'archived', 'failed');
+
+-- the last snapshot taken of a link. Snapshots are kept when archiving the page again fails, archived_at is when the
+-- one kept was taken. The keys are private blobs served through the api.
+CREATE TABLE link_archive (
+    link_id TEXT NOT NULL PRIMARY KEY REFERENCES link(link_id) ON DELETE CASCADE,
+    archive_status link_archive_status NOT NULL DEFAULT 'pending',
+    snapshot_key TEXT NOT NULL DEFAULT '',
+    article_key TEXT NOT NULL DEFAULT '',
+    article_text TEXT NOT NULL DEFAULT '',
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    archived_at TIMESTAMPTZ,
+    -- weighs as much as link notes when searching links
+    textsearchable_index_col tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', article_text), 'D')) STORED
+);
+
+CREATE INDEX link_archive_search_idx ON link_archive USING GIN (textsearchable_index_col);
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS link_archive;
+
+DROP TYPE IF EXISTS link_archive_status;
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
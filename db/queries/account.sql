@@ -13,9 +13,6 @@ SELECT * FROM account
 WHERE email = $1
 LIMIT 1;
 
--- name: GetAllAccounts :many
-SELECT * FROM account;
-
 -- name: UpdateLastLogin :one
 UPDATE account
 SET last_login = $1
@@ -32,4 +29,41 @@ UPDATE account SET email_verified = 'TRUE' WHERE email = $1;
 SELECT EXISTS (SELECT * FROM account WHERE email = $1 LIMIT 1);
 
 -- name: GetAccountLastLogin :one
-SELECT Date(last_login) FROM account WHERE id = $1 LIMIT 1;
\ No newline at end of file
+SELECT Date(last_login) FROM account WHERE id = $1 LIMIT 1;
+
+-- name: NewVerifiedAccount :one
+INSERT INTO account (fullname, email, picture, account_password, email_verified)
+VALUES ($1, $2, $3, '', TRUE)
+RETURNING *;
+
+-- name: ListAccounts :many
+SELECT * FROM account
+WHERE sqlc.arg(search)::text = '' OR email ILIKE '%' || sqlc.arg(search)::text || '%' OR fullname ILIKE '%' || sqlc.arg(search)::text || '%'
+ORDER BY id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountAccounts :one
+SELECT COUNT(*) FROM account
+WHERE sqlc.arg(search)::text = '' OR email ILIKE '%' || sqlc.arg(search)::text || '%' OR fullname ILIKE '%' || sqlc.arg(search)::text || '%';
+
+-- name: SuspendAccount :one
+UPDATE account SET suspended_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING *;
+
+-- name: UnsuspendAccount :one
+UPDATE account SET suspended_at = NULL WHERE id = $1 RETURNING *;
+
+-- name: GetSystemStats :one
+SELECT
+    (SELECT COUNT(*) FROM account) AS accounts,
+    (SELECT COUNT(*) FROM account WHERE created_at > CURRENT_TIMESTAMP - INTERVAL '7 days') AS new_accounts,
+    (SELECT COUNT(*) FROM account WHERE suspended_at IS NOT NULL) AS suspended_accounts,
+    (SELECT COUNT(*) FROM account_session WHERE expiry > CURRENT_TIMESTAMP) AS active_sessions,
+    (SELECT COUNT(*) FROM link WHERE deleted_at IS NULL) AS links,
+    (SELECT COUNT(*) FROM folder WHERE folder_deleted_at IS NULL) AS folders,
+    (SELECT COUNT(*) FROM contact WHERE resolved_at IS NULL) AS open_messages;
+
+-- name: UpdateAccountEmail :one
+UPDATE account SET email = $1, email_verified = TRUE WHERE id = $2 RETURNING *;
+
+-- name: DeleteAccount :exec
+DELETE FROM account WHERE id = $1;
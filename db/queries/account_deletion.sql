@@ -0,0 +1,17 @@
+-- name: CreateAccountDeletion :one
+INSERT INTO account_deletion (account_id, code, code_expiry, shared_collections)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (account_id) DO UPDATE SET code = EXCLUDED.code, code_expiry = EXCLUDED.code_expiry, shared_collections = EXCLUDED.shared_collections, requested_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetAccountDeletion :one
+SELECT * FROM account_deletion WHERE account_id = $1 LIMIT 1;
+
+-- name: ScheduleAccountDeletion :one
+UPDATE account_deletion SET delete_after = $1 WHERE account_id = $2 RETURNING *;
+
+-- name: CancelAccountDeletion :execrows
+DELETE FROM account_deletion WHERE account_id = $1;
+
+-- name: GetDueAccountDeletions :many
+SELECT * FROM account_deletion WHERE delete_after <= CURRENT_TIMESTAMP ORDER BY delete_after;
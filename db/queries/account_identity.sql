@@ -0,0 +1,16 @@
+-- name: CreateAccountIdentity :one
+INSERT INTO account_identity (account_id, provider, subject, identity_email)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetAccountByIdentity :one
+SELECT account.* FROM account
+JOIN account_identity ON account_identity.account_id = account.id
+WHERE account_identity.provider = $1 AND account_identity.subject = $2
+LIMIT 1;
+
+-- name: GetAccountIdentities :many
+SELECT * FROM account_identity WHERE account_id = $1 ORDER BY linked_at;
+
+-- name: DeleteAccountIdentity :execrows
+DELETE FROM account_identity WHERE account_id = $1 AND provider = $2;
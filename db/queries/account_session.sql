@@ -1,5 +1,24 @@
 -- name: CreateAccountSession :one
-INSERT INTO account_session (refresh_token_id, account_id, issued_at, expiry, user_agent, client_ip)
-VALUES ($1, $2, $3, $4, $5, $6)
-ON CONFLICT (account_id) DO UPDATE SET refresh_token_id = EXCLUDED.refresh_token_id, issued_at = EXCLUDED.issued_at, expiry = EXCLUDED.expiry, user_agent = EXCLUDED.user_agent, client_ip = EXCLUDED.client_ip
-RETURNING *; 
\ No newline at end of file
+INSERT INTO account_session (session_id, refresh_token_id, account_id, issued_at, expiry, user_agent, client_ip)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetAccountSession :one
+SELECT * FROM account_session WHERE session_id = $1 LIMIT 1;
+
+-- name: GetAccountSessions :many
+SELECT * FROM account_session WHERE account_id = $1 AND expiry > CURRENT_TIMESTAMP ORDER BY issued_at DESC;
+
+-- name: RotateAccountSession :one
+UPDATE account_session SET refresh_token_id = $1, issued_at = $2, expiry = $3, user_agent = $4, client_ip = $5
+WHERE session_id = $6 AND refresh_token_id = $7
+RETURNING *;
+
+-- name: DeleteAccountSession :execrows
+DELETE FROM account_session WHERE session_id = $1 AND account_id = $2;
+
+-- name: DeleteOtherAccountSessions :execrows
+DELETE FROM account_session WHERE account_id = $1 AND session_id <> $2;
+
+-- name: DeleteAccountSessions :exec
+DELETE FROM account_session WHERE account_id = $1;
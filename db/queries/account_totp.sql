@@ -0,0 +1,17 @@
+-- name: UpsertAccountTotp :one
+INSERT INTO account_totp (account_id, totp_secret)
+VALUES ($1, $2)
+ON CONFLICT (account_id) DO UPDATE SET totp_secret = EXCLUDED.totp_secret, confirmed_at = NULL, last_used_step = 0, totp_created_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetAccountTotp :one
+SELECT * FROM account_totp WHERE account_id = $1 LIMIT 1;
+
+-- name: ConfirmAccountTotp :one
+UPDATE account_totp SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = $1 WHERE account_id = $2 RETURNING *;
+
+-- name: UseTotpStep :execrows
+UPDATE account_totp SET last_used_step = $1 WHERE account_id = $2 AND last_used_step < $1;
+
+-- name: DeleteAccountTotp :exec
+DELETE FROM account_totp WHERE account_id = $1;
@@ -8,4 +8,36 @@ SELECT * FROM collection_member WHERE collection_id = $1 AND member_id = $2 LIMI
 SELECT EXISTS (SELECT * FROM collection_member WHERE collection_id = $1 AND member_id = $2 LIMIT 1);
 
 -- name: GetCollectionsSharedWithUser :many
-SELECT * FROM collection_member WHERE member_id = $1;
\ No newline at end of file
+SELECT * FROM collection_member WHERE member_id = $1;
+
+-- name: GetAccessLevelsOnFolderAndAncestors :many
+SELECT collection_member.collection_access_level FROM collection_member
+JOIN folder ON folder.folder_id = collection_member.collection_id
+WHERE collection_member.member_id = $1 AND folder.path @> (SELECT f.path FROM folder AS f WHERE f.folder_id = $2);
+
+-- name: GetCollectionMembers :many
+SELECT collection_member.*, account.fullname, account.email FROM collection_member
+JOIN account ON account.id = collection_member.member_id
+WHERE collection_member.collection_id = $1
+ORDER BY collection_member.join_date;
+
+-- name: UpdateCollectionMemberAccessLevel :one
+UPDATE collection_member SET collection_access_level = $1 WHERE collection_id = $2 AND member_id = $3 RETURNING *;
+
+-- name: DeleteCollectionMember :execrows
+DELETE FROM collection_member WHERE collection_id = $1 AND member_id = $2;
+
+-- name: GetNewCollectionOwner :one
+SELECT collection_member.member_id FROM collection_member
+JOIN folder ON folder.folder_id = collection_member.collection_id
+JOIN account ON account.id = collection_member.member_id
+WHERE folder.path <@ (SELECT f.path FROM folder AS f WHERE f.folder_id = sqlc.arg(folder_id))
+AND collection_member.member_id <> sqlc.arg(account_id) AND account.suspended_at IS NULL
+ORDER BY collection_member.collection_access_level DESC, collection_member.join_date
+LIMIT 1;
+
+-- name: DeleteCollectionTreeMember :exec
+DELETE FROM collection_member
+WHERE member_id = sqlc.arg(member_id) AND collection_id IN (
+  SELECT folder.folder_id FROM folder WHERE folder.path <@ (SELECT f.path FROM folder AS f WHERE f.folder_id = sqlc.arg(folder_id))
+);
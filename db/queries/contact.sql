@@ -3,5 +3,21 @@ INSERT INTO contact (account, message_body)
 VALUES ($1, $2)
 RETURNING *;
 
--- name: GetAllMessages :many
-SELECT * FROM contact;
\ No newline at end of file
+-- name: GetSupportMessages :many
+SELECT contact.*, account.fullname, account.email FROM contact
+JOIN account ON account.id = contact.account
+WHERE (contact.resolved_at IS NOT NULL) = sqlc.arg(resolved)::boolean
+ORDER BY contact.sent_at DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: CountSupportMessages :one
+SELECT COUNT(*) FROM contact WHERE (resolved_at IS NOT NULL) = sqlc.arg(resolved)::boolean;
+
+-- name: ResolveSupportMessage :one
+UPDATE contact SET resolved_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING *;
+
+-- name: GetAccountSupportMessages :many
+SELECT * FROM contact WHERE account = $1 ORDER BY sent_at;
+
+-- name: DeleteAccountSupportMessages :exec
+DELETE FROM contact WHERE account = $1;
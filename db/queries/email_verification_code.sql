@@ -1,11 +1,23 @@
 -- name: NewEmailVerificationCode :one
 INSERT INTO email_verification (code, email, expiry)
 VALUES ($1, $2, $3)
-ON CONFLICT (email) DO UPDATE SET code = EXCLUDED.code, expiry = EXCLUDED.expiry
+ON CONFLICT (email) WHERE account_id IS NULL DO UPDATE SET code = EXCLUDED.code, expiry = EXCLUDED.expiry
 RETURNING *;
 
 -- name: DeleteEmailVerificationCode :exec
-DELETE FROM email_verification WHERE email = $1;
+DELETE FROM email_verification WHERE email = $1 AND account_id IS NULL;
 
 -- name: GetOtp :one
-SELECT * FROM email_verification WHERE email = $1 LIMIT 1;
\ No newline at end of file
+SELECT * FROM email_verification WHERE email = $1 AND account_id IS NULL LIMIT 1;
+
+-- name: NewEmailChangeCode :one
+INSERT INTO email_verification (code, email, expiry, account_id)
+VALUES (sqlc.arg(code), sqlc.arg(email), sqlc.arg(expiry), sqlc.arg(account_id)::bigint)
+ON CONFLICT (account_id) WHERE account_id IS NOT NULL DO UPDATE SET code = EXCLUDED.code, email = EXCLUDED.email, expiry = EXCLUDED.expiry
+RETURNING *;
+
+-- name: GetEmailChangeCode :one
+SELECT * FROM email_verification WHERE account_id = sqlc.arg(account_id)::bigint LIMIT 1;
+
+-- name: DeleteEmailChangeCode :exec
+DELETE FROM email_verification WHERE account_id = sqlc.arg(account_id)::bigint;
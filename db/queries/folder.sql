@@ -89,14 +89,27 @@ UPDATE folder SET folder_deleted_at = NULL WHERE folder_id = $1 RETURNING *;
 -- name: DeleteFolderForever :many
 DELETE FROM folder where path <@ (SELECT path FROM folder where folder.folder_id = $1) RETURNING *;
 
--- name: SearchFolders :many
-SELECT *
-FROM folder
-WHERE textsearchable_index_col @@ plainto_tsquery($1) AND account_id = $2 AND folder_deleted_at IS NULL
-ORDER BY folder_created_at DESC;
+-- name: GetAccountFoldersForExport :many
+SELECT * FROM folder WHERE account_id = $1 ORDER BY path;
 
--- name: SearchFolderz :many
-SELECT *
-FROM folder
-WHERE folder_name ILIKE $1 AND account_id = $2 AND folder_deleted_at IS NULL
-ORDER BY folder_created_at DESC;
\ No newline at end of file
+-- name: GetCollectionFoldersForExport :many
+SELECT * FROM folder
+WHERE path <@ (SELECT path FROM folder AS f WHERE f.folder_id = $1)
+ORDER BY path;
+
+-- name: GetSharedRootFolders :many
+SELECT * FROM folder
+WHERE folder.account_id = $1 AND folder.subfolder_of IS NULL AND EXISTS (
+  SELECT 1 FROM collection_member
+  JOIN folder AS f ON f.folder_id = collection_member.collection_id
+  WHERE f.path <@ folder.path
+);
+
+-- name: TransferCollection :exec
+UPDATE folder SET account_id = sqlc.arg(new_owner_id)
+WHERE account_id = sqlc.arg(old_owner_id) AND path <@ (SELECT f.path FROM folder AS f WHERE f.folder_id = sqlc.arg(folder_id));
+
+-- name: ReassignContributedFolders :exec
+UPDATE folder SET account_id = root.account_id
+FROM folder AS root
+WHERE folder.account_id = $1 AND root.path = SUBPATH(folder.path, 0, 1) AND root.account_id <> $1;
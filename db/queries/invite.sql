@@ -8,4 +8,36 @@ RETURNING *;
 SELECT * FROM member_invite WHERE invite_token = $1 LIMIT 1;
 
 -- name: DeleteInvite :exec
-DELETE FROM member_invite WHERE invite_token = $1;
\ No newline at end of file
+DELETE FROM member_invite WHERE invite_token = $1;
+
+-- name: GetInvitesByCollectionID :many
+SELECT * FROM member_invite WHERE shared_collection_id = $1 ORDER BY invite_expiry DESC;
+
+-- name: GetInvite :one
+SELECT * FROM member_invite WHERE invite_id = $1 LIMIT 1;
+
+-- name: RenewInvite :one
+UPDATE member_invite SET invite_token = $1, invite_expiry = $2 WHERE invite_id = $3 RETURNING *;
+
+-- name: DeleteInviteByID :exec
+DELETE FROM member_invite WHERE invite_id = $1;
+
+-- name: MoveInvitesToEmail :exec
+UPDATE member_invite SET collection_shared_with = sqlc.arg(new_email)::text
+WHERE collection_shared_with = sqlc.arg(old_email)::text
+AND shared_collection_id NOT IN (SELECT shared_collection_id FROM member_invite WHERE collection_shared_with = sqlc.arg(new_email)::text);
+
+-- name: DeleteInvitesForEmail :exec
+DELETE FROM member_invite WHERE collection_shared_with = $1;
+
+-- name: UpdateInviterEmail :exec
+UPDATE member_invite SET collection_shared_by_email = sqlc.arg(new_email)::text WHERE collection_shared_by_email = sqlc.arg(old_email)::text;
+
+-- name: GetInvitesSentByEmail :many
+SELECT * FROM member_invite WHERE collection_shared_by_email = $1 ORDER BY invite_expiry DESC;
+
+-- name: DeleteAccountInvites :exec
+DELETE FROM member_invite WHERE collection_shared_by_email = $1 OR collection_shared_with = $1;
+
+-- name: DeleteOrphanedInvites :exec
+DELETE FROM member_invite WHERE shared_collection_id NOT IN (SELECT folder_id FROM folder);
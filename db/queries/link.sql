@@ -10,7 +10,21 @@ SELECT * FROM link WHERE account_id = $1 AND folder_id IS NULL AND deleted_at IS
 SELECT * FROM link WHERE folder_id = $1 AND deleted_at IS NULL ORDER BY added_at DESC;
 
 -- name: RenameLink :one
-UPDATE link SET link_title = $1 WHERE link_id = $2 RETURNING *;
+UPDATE link SET link_title = $1, updated_at = CURRENT_TIMESTAMP WHERE link_id = $2 RETURNING *;
+
+-- name: UpdateLink :one
+UPDATE link
+SET link_title = $1,
+  link_notes = $2,
+  link_url = $3,
+  link_hostname = $4,
+  metadata_status = $5,
+  updated_at = CURRENT_TIMESTAMP
+WHERE link_id = $6
+RETURNING *;
+
+-- name: SetLinkThumbnail :one
+UPDATE link SET link_thumbnail = $1, updated_at = CURRENT_TIMESTAMP WHERE link_id = $2 RETURNING *;
 
 -- name: MoveLinkToFolder :one
 UPDATE link SET folder_id = $1 WHERE link_id = $2 RETURNING *;
@@ -30,22 +44,47 @@ SELECT * FROM link WHERE deleted_at IS NOT NULL AND account_id = $1 ORDER BY del
 -- name: DeleteLinkForever :one
 DELETE FROM link WHERE link_id = $1 RETURNING *;
 
--- name: SearchLinks :many
-SELECT *
-FROM link
-WHERE textsearchable_index_col @@ plainto_tsquery($1) AND account_id = $2 AND deleted_at IS NULL
-ORDER BY added_at DESC;
-
--- name: SearchLinkz :many
-SELECT *
-FROM link
-WHERE link_title ILIKE $1 AND account_id = $2 AND deleted_at IS NULL
-ORDER BY added_at DESC;
-
 -- name: GetLink :one
 SELECT * FROM link
 WHERE link_id = $1
 LIMIT 1;
 
--- name: GetLinksByUserID :many
-SELECT * FROM link WHERE account_id = $1;
\ No newline at end of file
+-- name: ImportLink :one
+INSERT INTO link (link_id, link_title, link_hostname, link_url, link_favicon, account_id, folder_id, link_thumbnail, link_notes, added_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: LinkExistsInFolder :one
+SELECT EXISTS (SELECT * FROM link WHERE account_id = $1 AND link_url = $2 AND COALESCE(folder_id, '') = sqlc.arg(folder_id)::text AND deleted_at IS NULL);
+
+-- name: GetAccountLinksForExport :many
+SELECT * FROM link WHERE account_id = $1 ORDER BY added_at;
+
+-- name: GetCollectionLinksForExport :many
+SELECT * FROM link
+WHERE folder_id IN (
+  SELECT folder_id FROM folder
+  WHERE path <@ (SELECT path FROM folder AS f WHERE f.folder_id = $1)
+)
+ORDER BY added_at;
+
+-- name: UpdateLinkMetadata :one
+UPDATE link
+SET link_title = CASE WHEN link_title = link_url THEN sqlc.arg(link_title)::text ELSE link_title END,
+  link_favicon = $2,
+  link_thumbnail = $3,
+  metadata_status = 'ready'
+WHERE link_id = $4 AND link_url = sqlc.arg(fetched_url) AND link_thumbnail = sqlc.arg(old_thumbnail) AND link_favicon = sqlc.arg(old_favicon)
+RETURNING *;
+
+-- name: SetLinkMetadataStatus :exec
+UPDATE link SET metadata_status = $1 WHERE link_id = $2;
+
+-- name: ReassignContributedLinks :exec
+UPDATE link SET account_id = folder.account_id
+FROM folder
+WHERE link.account_id = $1 AND link.folder_id = folder.folder_id AND folder.account_id <> $1;
+
+-- name: GetLinksDeletedWithAccount :many
+SELECT * FROM link
+WHERE account_id = $1 OR folder_id IN (SELECT folder_id FROM folder WHERE folder.account_id = $1);
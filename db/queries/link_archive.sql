@@ -0,0 +1,21 @@
+-- name: RequestLinkArchive :one
+INSERT INTO link_archive (link_id) VALUES ($1)
+ON CONFLICT (link_id) DO UPDATE SET archive_status = 'pending', requested_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetLinkArchive :one
+SELECT * FROM link_archive WHERE link_id = $1 LIMIT 1;
+
+-- name: SetLinkArchive :one
+UPDATE link_archive
+SET archive_status = 'archived', snapshot_key = $1, article_key = $2, article_text = $3, archived_at = CURRENT_TIMESTAMP
+WHERE link_id = $4
+RETURNING *;
+
+-- name: SetLinkArchiveStatus :exec
+UPDATE link_archive SET archive_status = $1 WHERE link_id = $2;
+
+-- name: GetLinkArchivesDeletedWithAccount :many
+SELECT link_archive.* FROM link_archive
+JOIN link ON link.link_id = link_archive.link_id
+WHERE link.account_id = $1 OR link.folder_id IN (SELECT folder_id FROM folder WHERE folder.account_id = $1);
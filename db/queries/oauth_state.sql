@@ -0,0 +1,10 @@
+-- name: CreateOauthState :one
+INSERT INTO oauth_state (state, provider, nonce, code_verifier, link_account_id, state_expiry)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ConsumeOauthState :one
+DELETE FROM oauth_state WHERE state = $1 RETURNING *;
+
+-- name: DeleteExpiredOauthStates :exec
+DELETE FROM oauth_state WHERE state_expiry < CURRENT_TIMESTAMP;
@@ -0,0 +1,18 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_token (account_id, token_name, token_hash, token_prefix, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetPersonalAccessTokenByHash :one
+SELECT * FROM personal_access_token
+WHERE token_hash = $1 AND account_id NOT IN (SELECT id FROM account WHERE suspended_at IS NOT NULL);
+
+-- name: GetPersonalAccessTokens :many
+SELECT * FROM personal_access_token WHERE account_id = $1 ORDER BY token_created_at DESC;
+
+-- name: TouchPersonalAccessToken :exec
+UPDATE personal_access_token SET last_used_at = CURRENT_TIMESTAMP
+WHERE token_id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute');
+
+-- name: DeletePersonalAccessToken :execrows
+DELETE FROM personal_access_token WHERE token_id = $1 AND account_id = $2;
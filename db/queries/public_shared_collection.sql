@@ -0,0 +1,19 @@
+-- name: ShareCollectionWithPublicLink :one
+INSERT INTO public_shared_collection (share_token, collection_id, collection_password, collection_shared_by, collection_share_expiry)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetPublicSharedCollection :one
+SELECT * FROM public_shared_collection WHERE share_token = $1 LIMIT 1;
+
+-- name: GetPublicSharedCollectionsByCollectionID :many
+SELECT * FROM public_shared_collection WHERE collection_id = $1 ORDER BY collection_shared_at DESC;
+
+-- name: RevokePublicSharedCollection :exec
+DELETE FROM public_shared_collection WHERE share_token = $1;
+
+-- name: TransferPublicLinks :exec
+UPDATE public_shared_collection SET collection_shared_by = sqlc.arg(new_owner_id)
+WHERE collection_shared_by = sqlc.arg(old_owner_id) AND collection_id IN (
+  SELECT folder.folder_id FROM folder WHERE folder.path <@ (SELECT f.path FROM folder AS f WHERE f.folder_id = sqlc.arg(folder_id))
+);
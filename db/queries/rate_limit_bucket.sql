@@ -0,0 +1,14 @@
+-- name: EnsureRateLimitBucket :exec
+INSERT INTO rate_limit_bucket (bucket_key) VALUES ($1) ON CONFLICT (bucket_key) DO NOTHING;
+
+-- name: GetRateLimitBucketForUpdate :one
+SELECT * FROM rate_limit_bucket WHERE bucket_key = $1 FOR UPDATE;
+
+-- name: UpdateRateLimitBucket :exec
+UPDATE rate_limit_bucket SET tokens = $1, updated_at = $2, expires_at = $3 WHERE bucket_key = $4;
+
+-- name: DeleteRateLimitBucket :exec
+DELETE FROM rate_limit_bucket WHERE bucket_key = $1;
+
+-- name: DeleteExpiredRateLimitBuckets :exec
+DELETE FROM rate_limit_bucket WHERE expires_at < CURRENT_TIMESTAMP;
@@ -0,0 +1,25 @@
+-- name: CreateSmartFolder :one
+INSERT INTO smart_folder (smart_folder_id, account_id, folder_id, smart_folder_name, search_query)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetSmartFolder :one
+SELECT * FROM smart_folder WHERE smart_folder_id = $1 LIMIT 1;
+
+-- name: GetRootSmartFolders :many
+SELECT * FROM smart_folder WHERE account_id = $1 AND folder_id IS NULL ORDER BY created_at DESC;
+
+-- name: GetFolderSmartFolders :many
+SELECT * FROM smart_folder WHERE folder_id = $1 ORDER BY created_at DESC;
+
+-- name: GetAccountSmartFolders :many
+SELECT * FROM smart_folder WHERE account_id = $1 ORDER BY created_at;
+
+-- name: UpdateSmartFolder :one
+UPDATE smart_folder
+SET smart_folder_name = $1, search_query = $2, starred = $3, updated_at = CURRENT_TIMESTAMP
+WHERE smart_folder_id = $4
+RETURNING *;
+
+-- name: DeleteSmartFolder :exec
+DELETE FROM smart_folder WHERE smart_folder_id = $1;
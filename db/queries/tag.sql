@@ -0,0 +1,78 @@
+-- name: CreateTag :one
+INSERT INTO tag (account_id, tag_name) VALUES ($1, $2) RETURNING *;
+
+-- name: GetTag :one
+SELECT * FROM tag WHERE tag_id = $1 LIMIT 1;
+
+-- name: TagNameExists :one
+SELECT EXISTS (SELECT * FROM tag WHERE account_id = $1 AND tag_name = $2 LIMIT 1);
+
+-- name: GetTagsByAccountID :many
+SELECT tag.*,
+  (SELECT COUNT(*) FROM link_tag
+    JOIN link ON link.link_id = link_tag.link_id
+    LEFT JOIN folder ON folder.folder_id = link.folder_id
+    WHERE link_tag.tag_id = tag.tag_id AND link.deleted_at IS NULL AND (
+      (link.folder_id IS NULL AND link.account_id = $1)
+      OR EXISTS (SELECT 1 FROM folder AS root WHERE root.path = subpath(folder.path, 0, 1) AND root.account_id = $1)
+      OR EXISTS (SELECT 1 FROM collection_member JOIN folder AS shared ON shared.folder_id = collection_member.collection_id WHERE collection_member.member_id = $1 AND shared.path @> folder.path)
+    )
+  ) AS link_count,
+  (SELECT COUNT(*) FROM folder_tag
+    JOIN folder ON folder.folder_id = folder_tag.folder_id
+    WHERE folder_tag.tag_id = tag.tag_id AND folder.folder_deleted_at IS NULL AND (
+      EXISTS (SELECT 1 FROM folder AS root WHERE root.path = subpath(folder.path, 0, 1) AND root.account_id = $1)
+      OR EXISTS (SELECT 1 FROM collection_member JOIN folder AS shared ON shared.folder_id = collection_member.collection_id WHERE collection_member.member_id = $1 AND shared.path @> folder.path)
+    )
+  ) AS folder_count
+FROM tag
+WHERE tag.account_id = $1
+ORDER BY tag.tag_name;
+
+-- name: RenameTag :one
+UPDATE tag SET tag_name = $1 WHERE tag_id = $2 RETURNING *;
+
+-- name: DeleteTag :one
+DELETE FROM tag WHERE tag_id = $1 RETURNING *;
+
+-- name: AddTagToLink :exec
+INSERT INTO link_tag (link_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;
+
+-- name: RemoveTagFromLink :exec
+DELETE FROM link_tag WHERE link_id = $1 AND tag_id = $2;
+
+-- name: AddTagToFolder :exec
+INSERT INTO folder_tag (folder_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;
+
+-- name: RemoveTagFromFolder :exec
+DELETE FROM folder_tag WHERE folder_id = $1 AND tag_id = $2;
+
+-- name: MergeLinkTags :exec
+INSERT INTO link_tag (link_id, tag_id)
+SELECT link_tag.link_id, sqlc.arg(target_tag_id)::bigint FROM link_tag WHERE link_tag.tag_id = sqlc.arg(source_tag_id)::bigint
+ON CONFLICT DO NOTHING;
+
+-- name: MergeFolderTags :exec
+INSERT INTO folder_tag (folder_id, tag_id)
+SELECT folder_tag.folder_id, sqlc.arg(target_tag_id)::bigint FROM folder_tag WHERE folder_tag.tag_id = sqlc.arg(source_tag_id)::bigint
+ON CONFLICT DO NOTHING;
+
+-- name: GetLinksByTag :many
+SELECT link.* FROM link
+JOIN link_tag ON link_tag.link_id = link.link_id
+LEFT JOIN folder ON folder.folder_id = link.folder_id
+WHERE link_tag.tag_id = $1 AND (link.deleted_at IS NULL OR sqlc.arg(include_trashed)::boolean) AND (
+  (link.folder_id IS NULL AND link.account_id = sqlc.arg(account_id))
+  OR EXISTS (SELECT 1 FROM folder AS root WHERE root.path = subpath(folder.path, 0, 1) AND root.account_id = sqlc.arg(account_id))
+  OR EXISTS (SELECT 1 FROM collection_member JOIN folder AS shared ON shared.folder_id = collection_member.collection_id WHERE collection_member.member_id = sqlc.arg(account_id) AND shared.path @> folder.path)
+)
+ORDER BY link.added_at DESC;
+
+-- name: GetFoldersByTag :many
+SELECT folder.* FROM folder
+JOIN folder_tag ON folder_tag.folder_id = folder.folder_id
+WHERE folder_tag.tag_id = $1 AND (folder.folder_deleted_at IS NULL OR sqlc.arg(include_trashed)::boolean) AND (
+  EXISTS (SELECT 1 FROM folder AS root WHERE root.path = subpath(folder.path, 0, 1) AND root.account_id = sqlc.arg(account_id))
+  OR EXISTS (SELECT 1 FROM collection_member JOIN folder AS shared ON shared.folder_id = collection_member.collection_id WHERE collection_member.member_id = sqlc.arg(account_id) AND shared.path @> folder.path)
+)
+ORDER BY folder.folder_created_at DESC;
\ No newline at end of file
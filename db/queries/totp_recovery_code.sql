@@ -0,0 +1,11 @@
+-- name: CreateTotpRecoveryCode :exec
+INSERT INTO totp_recovery_code (account_id, code_hash) VALUES ($1, $2);
+
+-- name: UseTotpRecoveryCode :execrows
+UPDATE totp_recovery_code SET used_at = CURRENT_TIMESTAMP WHERE account_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUnusedTotpRecoveryCodes :one
+SELECT COUNT(*) FROM totp_recovery_code WHERE account_id = $1 AND used_at IS NULL;
+
+-- name: DeleteTotpRecoveryCodes :exec
+DELETE FROM totp_recovery_code WHERE account_id = $1;
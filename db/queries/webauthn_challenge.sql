@@ -0,0 +1,10 @@
+-- name: CreateWebauthnChallenge :one
+INSERT INTO webauthn_challenge (challenge, ceremony, account_id, challenge_expiry)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ConsumeWebauthnChallenge :one
+DELETE FROM webauthn_challenge WHERE challenge = $1 AND ceremony = $2 RETURNING *;
+
+-- name: DeleteExpiredWebauthnChallenges :exec
+DELETE FROM webauthn_challenge WHERE challenge_expiry < CURRENT_TIMESTAMP;
@@ -0,0 +1,19 @@
+-- name: CreateWebauthnCredential :one
+INSERT INTO webauthn_credential (credential_id, account_id, public_key, sign_count, credential_name)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWebauthnCredential :one
+SELECT * FROM webauthn_credential WHERE credential_id = $1;
+
+-- name: GetWebauthnCredentials :many
+SELECT * FROM webauthn_credential WHERE account_id = $1 ORDER BY credential_created_at;
+
+-- name: UseWebauthnCredential :exec
+UPDATE webauthn_credential SET sign_count = $1, last_used_at = CURRENT_TIMESTAMP WHERE credential_id = $2;
+
+-- name: RenameWebauthnCredential :one
+UPDATE webauthn_credential SET credential_name = $1 WHERE credential_id = $2 AND account_id = $3 RETURNING *;
+
+-- name: DeleteWebauthnCredential :execrows
+DELETE FROM webauthn_credential WHERE credential_id = $1 AND account_id = $2;
@@ -10,8 +10,29 @@ import (
 	"time"
 )
 
+const countAccounts = `-- name: CountAccounts :one
+SELECT COUNT(*) FROM account
+WHERE $1::text = '' OR email ILIKE '%' || $1::text || '%' OR fullname ILIKE '%' || $1::text || '%'
+`
+
+func (q *Queries) CountAccounts(ctx context.Context, search string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAccounts, search)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteAccount = `-- name: DeleteAccount :exec
+DELETE FROM account WHERE id = $1
+`
+
+func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAccount, id)
+	return err
+}
+
 const emailExists = `-- name: EmailExists :one
-SELECT EXISTS (SELECT id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login FROM account WHERE email = $1 LIMIT 1)
+SELECT EXISTS (SELECT id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login, is_admin, suspended_at FROM account WHERE email = $1 LIMIT 1)
 `
 
 func (q *Queries) EmailExists(ctx context.Context, email string) (bool, error) {
@@ -22,7 +43,7 @@ func (q *Queries) EmailExists(ctx context.Context, email string) (bool, error) {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login FROM account
+SELECT id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login, is_admin, suspended_at FROM account
 WHERE id = $1
 LIMIT 1
 `
@@ -40,12 +61,14 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.CreatedAt,
 		&i.Intention,
 		&i.LastLogin,
+		&i.IsAdmin,
+		&i.SuspendedAt,
 	)
 	return i, err
 }
 
 const getAccountByEmail = `-- name: GetAccountByEmail :one
-SELECT id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login FROM account
+SELECT id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login, is_admin, suspended_at FROM account
 WHERE email = $1
 LIMIT 1
 `
@@ -63,6 +86,8 @@ func (q *Queries) GetAccountByEmail(ctx context.Context, email string) (Account,
 		&i.CreatedAt,
 		&i.Intention,
 		&i.LastLogin,
+		&i.IsAdmin,
+		&i.SuspendedAt,
 	)
 	return i, err
 }
@@ -78,12 +103,57 @@ func (q *Queries) GetAccountLastLogin(ctx context.Context, id int64) (time.Time,
 	return date, err
 }
 
-const getAllAccounts = `-- name: GetAllAccounts :many
-SELECT id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login FROM account
+const getSystemStats = `-- name: GetSystemStats :one
+SELECT
+    (SELECT COUNT(*) FROM account) AS accounts,
+    (SELECT COUNT(*) FROM account WHERE created_at > CURRENT_TIMESTAMP - INTERVAL '7 days') AS new_accounts,
+    (SELECT COUNT(*) FROM account WHERE suspended_at IS NOT NULL) AS suspended_accounts,
+    (SELECT COUNT(*) FROM account_session WHERE expiry > CURRENT_TIMESTAMP) AS active_sessions,
+    (SELECT COUNT(*) FROM link WHERE deleted_at IS NULL) AS links,
+    (SELECT COUNT(*) FROM folder WHERE folder_deleted_at IS NULL) AS folders,
+    (SELECT COUNT(*) FROM contact WHERE resolved_at IS NULL) AS open_messages
 `
 
-func (q *Queries) GetAllAccounts(ctx context.Context) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, getAllAccounts)
+type GetSystemStatsRow struct {
+	Accounts          int64 `json:"accounts"`
+	NewAccounts       int64 `json:"new_accounts"`
+	SuspendedAccounts int64 `json:"suspended_accounts"`
+	ActiveSessions    int64 `json:"active_sessions"`
+	Links             int64 `json:"links"`
+	Folders           int64 `json:"folders"`
+	OpenMessages      int64 `json:"open_messages"`
+}
+
+func (q *Queries) GetSystemStats(ctx context.Context) (GetSystemStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getSystemStats)
+	var i GetSystemStatsRow
+	err := row.Scan(
+		&i.Accounts,
+		&i.NewAccounts,
+		&i.SuspendedAccounts,
+		&i.ActiveSessions,
+		&i.Links,
+		&i.Folders,
+		&i.OpenMessages,
+	)
+	return i, err
+}
+
+const listAccounts = `-- name: ListAccounts :many
+SELECT id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login, is_admin, suspended_at FROM account
+WHERE $1::text = '' OR email ILIKE '%' || $1::text || '%' OR fullname ILIKE '%' || $1::text || '%'
+ORDER BY id
+LIMIT $2 OFFSET $3
+`
+
+type ListAccountsParams struct {
+	Search     string `json:"search"`
+	PageSize   int32  `json:"page_size"`
+	PageOffset int32  `json:"page_offset"`
+}
+
+func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Search, arg.PageSize, arg.PageOffset)
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +171,8 @@ func (q *Queries) GetAllAccounts(ctx context.Context) ([]Account, error) {
 			&i.CreatedAt,
 			&i.Intention,
 			&i.LastLogin,
+			&i.IsAdmin,
+			&i.SuspendedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -118,7 +190,7 @@ func (q *Queries) GetAllAccounts(ctx context.Context) ([]Account, error) {
 const newAccount = `-- name: NewAccount :one
 INSERT INTO account (fullname, email, account_password)
 VALUES ($1, $2, $3)
-RETURNING id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login
+RETURNING id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login, is_admin, suspended_at
 `
 
 type NewAccountParams struct {
@@ -140,6 +212,113 @@ func (q *Queries) NewAccount(ctx context.Context, arg NewAccountParams) (Account
 		&i.CreatedAt,
 		&i.Intention,
 		&i.LastLogin,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+	)
+	return i, err
+}
+
+const newVerifiedAccount = `-- name: NewVerifiedAccount :one
+INSERT INTO account (fullname, email, picture, account_password, email_verified)
+VALUES ($1, $2, $3, '', TRUE)
+RETURNING id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login, is_admin, suspended_at
+`
+
+type NewVerifiedAccountParams struct {
+	Fullname string `json:"fullname"`
+	Email    string `json:"email"`
+	Picture  string `json:"picture"`
+}
+
+func (q *Queries) NewVerifiedAccount(ctx context.Context, arg NewVerifiedAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, newVerifiedAccount, arg.Fullname, arg.Email, arg.Picture)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Fullname,
+		&i.Email,
+		&i.EmailVerified,
+		&i.Picture,
+		&i.AccountPassword,
+		&i.CreatedAt,
+		&i.Intention,
+		&i.LastLogin,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+	)
+	return i, err
+}
+
+const suspendAccount = `-- name: SuspendAccount :one
+UPDATE account SET suspended_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login, is_admin, suspended_at
+`
+
+func (q *Queries) SuspendAccount(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, suspendAccount, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Fullname,
+		&i.Email,
+		&i.EmailVerified,
+		&i.Picture,
+		&i.AccountPassword,
+		&i.CreatedAt,
+		&i.Intention,
+		&i.LastLogin,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+	)
+	return i, err
+}
+
+const unsuspendAccount = `-- name: UnsuspendAccount :one
+UPDATE account SET suspended_at = NULL WHERE id = $1 RETURNING id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login, is_admin, suspended_at
+`
+
+func (q *Queries) UnsuspendAccount(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, unsuspendAccount, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Fullname,
+		&i.Email,
+		&i.EmailVerified,
+		&i.Picture,
+		&i.AccountPassword,
+		&i.CreatedAt,
+		&i.Intention,
+		&i.LastLogin,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+	)
+	return i, err
+}
+
+const updateAccountEmail = `-- name: UpdateAccountEmail :one
+UPDATE account SET email = $1, email_verified = TRUE WHERE id = $2 RETURNING id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login, is_admin, suspended_at
+`
+
+type UpdateAccountEmailParams struct {
+	Email string `json:"email"`
+	ID    int64  `json:"id"`
+}
+
+func (q *Queries) UpdateAccountEmail(ctx context.Context, arg UpdateAccountEmailParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountEmail, arg.Email, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Fullname,
+		&i.Email,
+		&i.EmailVerified,
+		&i.Picture,
+		&i.AccountPassword,
+		&i.CreatedAt,
+		&i.Intention,
+		&i.LastLogin,
+		&i.IsAdmin,
+		&i.SuspendedAt,
 	)
 	return i, err
 }
@@ -157,7 +336,7 @@ const updateLastLogin = `-- name: UpdateLastLogin :one
 UPDATE account
 SET last_login = $1
 WHERE id = $2
-RETURNING id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login
+RETURNING id, fullname, email, email_verified, picture, account_password, created_at, intention, last_login, is_admin, suspended_at
 `
 
 type UpdateLastLoginParams struct {
@@ -178,6 +357,8 @@ func (q *Queries) UpdateLastLogin(ctx context.Context, arg UpdateLastLoginParams
 		&i.CreatedAt,
 		&i.Intention,
 		&i.LastLogin,
+		&i.IsAdmin,
+		&i.SuspendedAt,
 	)
 	return i, err
 }
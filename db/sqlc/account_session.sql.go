@@ -11,13 +11,13 @@ import (
 )
 
 const createAccountSession = `-- name: CreateAccountSession :one
-INSERT INTO account_session (refresh_token_id, account_id, issued_at, expiry, user_agent, client_ip)
-VALUES ($1, $2, $3, $4, $5, $6)
-ON CONFLICT (account_id) DO UPDATE SET refresh_token_id = EXCLUDED.refresh_token_id, issued_at = EXCLUDED.issued_at, expiry = EXCLUDED.expiry, user_agent = EXCLUDED.user_agent, client_ip = EXCLUDED.client_ip
-RETURNING refresh_token_id, account_id, issued_at, expiry, user_agent, client_ip
+INSERT INTO account_session (session_id, refresh_token_id, account_id, issued_at, expiry, user_agent, client_ip)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING refresh_token_id, account_id, issued_at, expiry, user_agent, client_ip, session_id, created_at
 `
 
 type CreateAccountSessionParams struct {
+	SessionID      string    `json:"session_id"`
 	RefreshTokenID string    `json:"refresh_token_id"`
 	AccountID      int64     `json:"account_id"`
 	IssuedAt       time.Time `json:"issued_at"`
@@ -28,6 +28,7 @@ type CreateAccountSessionParams struct {
 
 func (q *Queries) CreateAccountSession(ctx context.Context, arg CreateAccountSessionParams) (AccountSession, error) {
 	row := q.db.QueryRowContext(ctx, createAccountSession,
+		arg.SessionID,
 		arg.RefreshTokenID,
 		arg.AccountID,
 		arg.IssuedAt,
@@ -43,6 +44,147 @@ func (q *Queries) CreateAccountSession(ctx context.Context, arg CreateAccountSes
 		&i.Expiry,
 		&i.UserAgent,
 		&i.ClientIp,
+		&i.SessionID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteAccountSession = `-- name: DeleteAccountSession :execrows
+DELETE FROM account_session WHERE session_id = $1 AND account_id = $2
+`
+
+type DeleteAccountSessionParams struct {
+	SessionID string `json:"session_id"`
+	AccountID int64  `json:"account_id"`
+}
+
+func (q *Queries) DeleteAccountSession(ctx context.Context, arg DeleteAccountSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAccountSession, arg.SessionID, arg.AccountID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteAccountSessions = `-- name: DeleteAccountSessions :exec
+DELETE FROM account_session WHERE account_id = $1
+`
+
+func (q *Queries) DeleteAccountSessions(ctx context.Context, accountID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountSessions, accountID)
+	return err
+}
+
+const deleteOtherAccountSessions = `-- name: DeleteOtherAccountSessions :execrows
+DELETE FROM account_session WHERE account_id = $1 AND session_id <> $2
+`
+
+type DeleteOtherAccountSessionsParams struct {
+	AccountID int64  `json:"account_id"`
+	SessionID string `json:"session_id"`
+}
+
+func (q *Queries) DeleteOtherAccountSessions(ctx context.Context, arg DeleteOtherAccountSessionsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOtherAccountSessions, arg.AccountID, arg.SessionID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getAccountSession = `-- name: GetAccountSession :one
+SELECT refresh_token_id, account_id, issued_at, expiry, user_agent, client_ip, session_id, created_at FROM account_session WHERE session_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountSession(ctx context.Context, sessionID string) (AccountSession, error) {
+	row := q.db.QueryRowContext(ctx, getAccountSession, sessionID)
+	var i AccountSession
+	err := row.Scan(
+		&i.RefreshTokenID,
+		&i.AccountID,
+		&i.IssuedAt,
+		&i.Expiry,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.SessionID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAccountSessions = `-- name: GetAccountSessions :many
+SELECT refresh_token_id, account_id, issued_at, expiry, user_agent, client_ip, session_id, created_at FROM account_session WHERE account_id = $1 AND expiry > CURRENT_TIMESTAMP ORDER BY issued_at DESC
+`
+
+func (q *Queries) GetAccountSessions(ctx context.Context, accountID int64) ([]AccountSession, error) {
+	rows, err := q.db.QueryContext(ctx, getAccountSessions, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountSession
+	for rows.Next() {
+		var i AccountSession
+		if err := rows.Scan(
+			&i.RefreshTokenID,
+			&i.AccountID,
+			&i.IssuedAt,
+			&i.Expiry,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.SessionID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateAccountSession = `-- name: RotateAccountSession :one
+UPDATE account_session SET refresh_token_id = $1, issued_at = $2, expiry = $3, user_agent = $4, client_ip = $5
+WHERE session_id = $6 AND refresh_token_id = $7
+RETURNING refresh_token_id, account_id, issued_at, expiry, user_agent, client_ip, session_id, created_at
+`
+
+type RotateAccountSessionParams struct {
+	RefreshTokenID   string    `json:"refresh_token_id"`
+	IssuedAt         time.Time `json:"issued_at"`
+	Expiry           time.Time `json:"expiry"`
+	UserAgent        string    `json:"user_agent"`
+	ClientIp         string    `json:"client_ip"`
+	SessionID        string    `json:"session_id"`
+	RefreshTokenID_2 string    `json:"refresh_token_id_2"`
+}
+
+func (q *Queries) RotateAccountSession(ctx context.Context, arg RotateAccountSessionParams) (AccountSession, error) {
+	row := q.db.QueryRowContext(ctx, rotateAccountSession,
+		arg.RefreshTokenID,
+		arg.IssuedAt,
+		arg.Expiry,
+		arg.UserAgent,
+		arg.ClientIp,
+		arg.SessionID,
+		arg.RefreshTokenID_2,
+	)
+	var i AccountSession
+	err := row.Scan(
+		&i.RefreshTokenID,
+		&i.AccountID,
+		&i.IssuedAt,
+		&i.Expiry,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.SessionID,
+		&i.CreatedAt,
 	)
 	return i, err
 }
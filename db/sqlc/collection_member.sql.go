@@ -7,6 +7,7 @@ package sqlc
 
 import (
 	"context"
+	"time"
 )
 
 const addNewCollectionMember = `-- name: AddNewCollectionMember :one
@@ -47,6 +48,74 @@ func (q *Queries) CheckIfCollectionMemberWithCollectionAndMemberIDsExists(ctx co
 	return exists, err
 }
 
+const deleteCollectionMember = `-- name: DeleteCollectionMember :execrows
+DELETE FROM collection_member WHERE collection_id = $1 AND member_id = $2
+`
+
+type DeleteCollectionMemberParams struct {
+	CollectionID string `json:"collection_id"`
+	MemberID     int64  `json:"member_id"`
+}
+
+func (q *Queries) DeleteCollectionMember(ctx context.Context, arg DeleteCollectionMemberParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteCollectionMember, arg.CollectionID, arg.MemberID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteCollectionTreeMember = `-- name: DeleteCollectionTreeMember :exec
+DELETE FROM collection_member
+WHERE member_id = $1 AND collection_id IN (
+  SELECT folder.folder_id FROM folder WHERE folder.path <@ (SELECT f.path FROM folder AS f WHERE f.folder_id = $2)
+)
+`
+
+type DeleteCollectionTreeMemberParams struct {
+	MemberID int64  `json:"member_id"`
+	FolderID string `json:"folder_id"`
+}
+
+func (q *Queries) DeleteCollectionTreeMember(ctx context.Context, arg DeleteCollectionTreeMemberParams) error {
+	_, err := q.db.ExecContext(ctx, deleteCollectionTreeMember, arg.MemberID, arg.FolderID)
+	return err
+}
+
+const getAccessLevelsOnFolderAndAncestors = `-- name: GetAccessLevelsOnFolderAndAncestors :many
+SELECT collection_member.collection_access_level FROM collection_member
+JOIN folder ON folder.folder_id = collection_member.collection_id
+WHERE collection_member.member_id = $1 AND folder.path @> (SELECT f.path FROM folder AS f WHERE f.folder_id = $2)
+`
+
+type GetAccessLevelsOnFolderAndAncestorsParams struct {
+	MemberID int64  `json:"member_id"`
+	FolderID string `json:"folder_id"`
+}
+
+func (q *Queries) GetAccessLevelsOnFolderAndAncestors(ctx context.Context, arg GetAccessLevelsOnFolderAndAncestorsParams) ([]CollectionAccessLevel, error) {
+	rows, err := q.db.QueryContext(ctx, getAccessLevelsOnFolderAndAncestors, arg.MemberID, arg.FolderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CollectionAccessLevel
+	for rows.Next() {
+		var collection_access_level CollectionAccessLevel
+		if err := rows.Scan(&collection_access_level); err != nil {
+			return nil, err
+		}
+		items = append(items, collection_access_level)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getCollectionMemberByCollectionAndMemberIDs = `-- name: GetCollectionMemberByCollectionAndMemberIDs :one
 SELECT collection_id, member_id, join_date, collection_access_level FROM collection_member WHERE collection_id = $1 AND member_id = $2 LIMIT 1
 `
@@ -68,6 +137,52 @@ func (q *Queries) GetCollectionMemberByCollectionAndMemberIDs(ctx context.Contex
 	return i, err
 }
 
+const getCollectionMembers = `-- name: GetCollectionMembers :many
+SELECT collection_member.collection_id, collection_member.member_id, collection_member.join_date, collection_member.collection_access_level, account.fullname, account.email FROM collection_member
+JOIN account ON account.id = collection_member.member_id
+WHERE collection_member.collection_id = $1
+ORDER BY collection_member.join_date
+`
+
+type GetCollectionMembersRow struct {
+	CollectionID          string                `json:"collection_id"`
+	MemberID              int64                 `json:"member_id"`
+	JoinDate              time.Time             `json:"join_date"`
+	CollectionAccessLevel CollectionAccessLevel `json:"collection_access_level"`
+	Fullname              string                `json:"fullname"`
+	Email                 string                `json:"email"`
+}
+
+func (q *Queries) GetCollectionMembers(ctx context.Context, collectionID string) ([]GetCollectionMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCollectionMembers, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCollectionMembersRow
+	for rows.Next() {
+		var i GetCollectionMembersRow
+		if err := rows.Scan(
+			&i.CollectionID,
+			&i.MemberID,
+			&i.JoinDate,
+			&i.CollectionAccessLevel,
+			&i.Fullname,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getCollectionsSharedWithUser = `-- name: GetCollectionsSharedWithUser :many
 SELECT collection_id, member_id, join_date, collection_access_level FROM collection_member WHERE member_id = $1
 `
@@ -99,3 +214,47 @@ func (q *Queries) GetCollectionsSharedWithUser(ctx context.Context, memberID int
 	}
 	return items, nil
 }
+
+const getNewCollectionOwner = `-- name: GetNewCollectionOwner :one
+SELECT collection_member.member_id FROM collection_member
+JOIN folder ON folder.folder_id = collection_member.collection_id
+JOIN account ON account.id = collection_member.member_id
+WHERE folder.path <@ (SELECT f.path FROM folder AS f WHERE f.folder_id = $1)
+AND collection_member.member_id <> $2 AND account.suspended_at IS NULL
+ORDER BY collection_member.collection_access_level DESC, collection_member.join_date
+LIMIT 1
+`
+
+type GetNewCollectionOwnerParams struct {
+	FolderID  string `json:"folder_id"`
+	AccountID int64  `json:"account_id"`
+}
+
+func (q *Queries) GetNewCollectionOwner(ctx context.Context, arg GetNewCollectionOwnerParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getNewCollectionOwner, arg.FolderID, arg.AccountID)
+	var member_id int64
+	err := row.Scan(&member_id)
+	return member_id, err
+}
+
+const updateCollectionMemberAccessLevel = `-- name: UpdateCollectionMemberAccessLevel :one
+UPDATE collection_member SET collection_access_level = $1 WHERE collection_id = $2 AND member_id = $3 RETURNING collection_id, member_id, join_date, collection_access_level
+`
+
+type UpdateCollectionMemberAccessLevelParams struct {
+	CollectionAccessLevel CollectionAccessLevel `json:"collection_access_level"`
+	CollectionID          string                `json:"collection_id"`
+	MemberID              int64                 `json:"member_id"`
+}
+
+func (q *Queries) UpdateCollectionMemberAccessLevel(ctx context.Context, arg UpdateCollectionMemberAccessLevelParams) (CollectionMember, error) {
+	row := q.db.QueryRowContext(ctx, updateCollectionMemberAccessLevel, arg.CollectionAccessLevel, arg.CollectionID, arg.MemberID)
+	var i CollectionMember
+	err := row.Scan(
+		&i.CollectionID,
+		&i.MemberID,
+		&i.JoinDate,
+		&i.CollectionAccessLevel,
+	)
+	return i, err
+}
@@ -7,14 +7,36 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
-const getAllMessages = `-- name: GetAllMessages :many
-SELECT id, account, message_body FROM contact
+const countSupportMessages = `-- name: CountSupportMessages :one
+SELECT COUNT(*) FROM contact WHERE (resolved_at IS NOT NULL) = $1::boolean
 `
 
-func (q *Queries) GetAllMessages(ctx context.Context) ([]Contact, error) {
-	rows, err := q.db.QueryContext(ctx, getAllMessages)
+func (q *Queries) CountSupportMessages(ctx context.Context, resolved bool) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSupportMessages, resolved)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteAccountSupportMessages = `-- name: DeleteAccountSupportMessages :exec
+DELETE FROM contact WHERE account = $1
+`
+
+func (q *Queries) DeleteAccountSupportMessages(ctx context.Context, account int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountSupportMessages, account)
+	return err
+}
+
+const getAccountSupportMessages = `-- name: GetAccountSupportMessages :many
+SELECT id, account, message_body, sent_at, resolved_at FROM contact WHERE account = $1 ORDER BY sent_at
+`
+
+func (q *Queries) GetAccountSupportMessages(ctx context.Context, account int64) ([]Contact, error) {
+	rows, err := q.db.QueryContext(ctx, getAccountSupportMessages, account)
 	if err != nil {
 		return nil, err
 	}
@@ -22,7 +44,68 @@ func (q *Queries) GetAllMessages(ctx context.Context) ([]Contact, error) {
 	var items []Contact
 	for rows.Next() {
 		var i Contact
-		if err := rows.Scan(&i.ID, &i.Account, &i.MessageBody); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Account,
+			&i.MessageBody,
+			&i.SentAt,
+			&i.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSupportMessages = `-- name: GetSupportMessages :many
+SELECT contact.id, contact.account, contact.message_body, contact.sent_at, contact.resolved_at, account.fullname, account.email FROM contact
+JOIN account ON account.id = contact.account
+WHERE (contact.resolved_at IS NOT NULL) = $1::boolean
+ORDER BY contact.sent_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetSupportMessagesParams struct {
+	Resolved   bool  `json:"resolved"`
+	PageSize   int32 `json:"page_size"`
+	PageOffset int32 `json:"page_offset"`
+}
+
+type GetSupportMessagesRow struct {
+	ID          int64        `json:"id"`
+	Account     int64        `json:"account"`
+	MessageBody string       `json:"message_body"`
+	SentAt      time.Time    `json:"sent_at"`
+	ResolvedAt  sql.NullTime `json:"resolved_at"`
+	Fullname    string       `json:"fullname"`
+	Email       string       `json:"email"`
+}
+
+func (q *Queries) GetSupportMessages(ctx context.Context, arg GetSupportMessagesParams) ([]GetSupportMessagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSupportMessages, arg.Resolved, arg.PageSize, arg.PageOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSupportMessagesRow
+	for rows.Next() {
+		var i GetSupportMessagesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Account,
+			&i.MessageBody,
+			&i.SentAt,
+			&i.ResolvedAt,
+			&i.Fullname,
+			&i.Email,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -39,7 +122,7 @@ func (q *Queries) GetAllMessages(ctx context.Context) ([]Contact, error) {
 const newMessage = `-- name: NewMessage :one
 INSERT INTO contact (account, message_body)
 VALUES ($1, $2)
-RETURNING id, account, message_body
+RETURNING id, account, message_body, sent_at, resolved_at
 `
 
 type NewMessageParams struct {
@@ -50,6 +133,29 @@ type NewMessageParams struct {
 func (q *Queries) NewMessage(ctx context.Context, arg NewMessageParams) (Contact, error) {
 	row := q.db.QueryRowContext(ctx, newMessage, arg.Account, arg.MessageBody)
 	var i Contact
-	err := row.Scan(&i.ID, &i.Account, &i.MessageBody)
+	err := row.Scan(
+		&i.ID,
+		&i.Account,
+		&i.MessageBody,
+		&i.SentAt,
+		&i.ResolvedAt,
+	)
+	return i, err
+}
+
+const resolveSupportMessage = `-- name: ResolveSupportMessage :one
+UPDATE contact SET resolved_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING id, account, message_body, sent_at, resolved_at
+`
+
+func (q *Queries) ResolveSupportMessage(ctx context.Context, id int64) (Contact, error) {
+	row := q.db.QueryRowContext(ctx, resolveSupportMessage, id)
+	var i Contact
+	err := row.Scan(
+		&i.ID,
+		&i.Account,
+		&i.MessageBody,
+		&i.SentAt,
+		&i.ResolvedAt,
+	)
 	return i, err
 }
@@ -10,8 +10,17 @@ import (
 	"time"
 )
 
+const deleteEmailChangeCode = `-- name: DeleteEmailChangeCode :exec
+DELETE FROM email_verification WHERE account_id = $1::bigint
+`
+
+func (q *Queries) DeleteEmailChangeCode(ctx context.Context, accountID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteEmailChangeCode, accountID)
+	return err
+}
+
 const deleteEmailVerificationCode = `-- name: DeleteEmailVerificationCode :exec
-DELETE FROM email_verification WHERE email = $1
+DELETE FROM email_verification WHERE email = $1 AND account_id IS NULL
 `
 
 func (q *Queries) DeleteEmailVerificationCode(ctx context.Context, email string) error {
@@ -19,22 +28,74 @@ func (q *Queries) DeleteEmailVerificationCode(ctx context.Context, email string)
 	return err
 }
 
+const getEmailChangeCode = `-- name: GetEmailChangeCode :one
+SELECT code, email, expiry, account_id FROM email_verification WHERE account_id = $1::bigint LIMIT 1
+`
+
+func (q *Queries) GetEmailChangeCode(ctx context.Context, accountID int64) (EmailVerification, error) {
+	row := q.db.QueryRowContext(ctx, getEmailChangeCode, accountID)
+	var i EmailVerification
+	err := row.Scan(
+		&i.Code,
+		&i.Email,
+		&i.Expiry,
+		&i.AccountID,
+	)
+	return i, err
+}
+
 const getOtp = `-- name: GetOtp :one
-SELECT code, email, expiry FROM email_verification WHERE email = $1 LIMIT 1
+SELECT code, email, expiry, account_id FROM email_verification WHERE email = $1 AND account_id IS NULL LIMIT 1
 `
 
 func (q *Queries) GetOtp(ctx context.Context, email string) (EmailVerification, error) {
 	row := q.db.QueryRowContext(ctx, getOtp, email)
 	var i EmailVerification
-	err := row.Scan(&i.Code, &i.Email, &i.Expiry)
+	err := row.Scan(
+		&i.Code,
+		&i.Email,
+		&i.Expiry,
+		&i.AccountID,
+	)
+	return i, err
+}
+
+const newEmailChangeCode = `-- name: NewEmailChangeCode :one
+INSERT INTO email_verification (code, email, expiry, account_id)
+VALUES ($1, $2, $3, $4::bigint)
+ON CONFLICT (account_id) WHERE account_id IS NOT NULL DO UPDATE SET code = EXCLUDED.code, email = EXCLUDED.email, expiry = EXCLUDED.expiry
+RETURNING code, email, expiry, account_id
+`
+
+type NewEmailChangeCodeParams struct {
+	Code      string    `json:"code"`
+	Email     string    `json:"email"`
+	Expiry    time.Time `json:"expiry"`
+	AccountID int64     `json:"account_id"`
+}
+
+func (q *Queries) NewEmailChangeCode(ctx context.Context, arg NewEmailChangeCodeParams) (EmailVerification, error) {
+	row := q.db.QueryRowContext(ctx, newEmailChangeCode,
+		arg.Code,
+		arg.Email,
+		arg.Expiry,
+		arg.AccountID,
+	)
+	var i EmailVerification
+	err := row.Scan(
+		&i.Code,
+		&i.Email,
+		&i.Expiry,
+		&i.AccountID,
+	)
 	return i, err
 }
 
 const newEmailVerificationCode = `-- name: NewEmailVerificationCode :one
 INSERT INTO email_verification (code, email, expiry)
 VALUES ($1, $2, $3)
-ON CONFLICT (email) DO UPDATE SET code = EXCLUDED.code, expiry = EXCLUDED.expiry
-RETURNING code, email, expiry
+ON CONFLICT (email) WHERE account_id IS NULL DO UPDATE SET code = EXCLUDED.code, expiry = EXCLUDED.expiry
+RETURNING code, email, expiry, account_id
 `
 
 type NewEmailVerificationCodeParams struct {
@@ -46,6 +107,11 @@ type NewEmailVerificationCodeParams struct {
 func (q *Queries) NewEmailVerificationCode(ctx context.Context, arg NewEmailVerificationCodeParams) (EmailVerification, error) {
 	row := q.db.QueryRowContext(ctx, newEmailVerificationCode, arg.Code, arg.Email, arg.Expiry)
 	var i EmailVerification
-	err := row.Scan(&i.Code, &i.Email, &i.Expiry)
+	err := row.Scan(
+		&i.Code,
+		&i.Email,
+		&i.Expiry,
+		&i.AccountID,
+	)
 	return i, err
 }
@@ -90,6 +90,86 @@ func (q *Queries) DeleteFolderForever(ctx context.Context, folderID string) ([]F
 	return items, nil
 }
 
+const getAccountFoldersForExport = `-- name: GetAccountFoldersForExport :many
+SELECT folder_id, account_id, folder_name, path, label, starred, folder_created_at, folder_updated_at, subfolder_of, folder_deleted_at, textsearchable_index_col FROM folder WHERE account_id = $1 ORDER BY path
+`
+
+func (q *Queries) GetAccountFoldersForExport(ctx context.Context, accountID int64) ([]Folder, error) {
+	rows, err := q.db.QueryContext(ctx, getAccountFoldersForExport, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Folder
+	for rows.Next() {
+		var i Folder
+		if err := rows.Scan(
+			&i.FolderID,
+			&i.AccountID,
+			&i.FolderName,
+			&i.Path,
+			&i.Label,
+			&i.Starred,
+			&i.FolderCreatedAt,
+			&i.FolderUpdatedAt,
+			&i.SubfolderOf,
+			&i.FolderDeletedAt,
+			&i.TextsearchableIndexCol,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCollectionFoldersForExport = `-- name: GetCollectionFoldersForExport :many
+SELECT folder_id, account_id, folder_name, path, label, starred, folder_created_at, folder_updated_at, subfolder_of, folder_deleted_at, textsearchable_index_col FROM folder
+WHERE path <@ (SELECT path FROM folder AS f WHERE f.folder_id = $1)
+ORDER BY path
+`
+
+func (q *Queries) GetCollectionFoldersForExport(ctx context.Context, folderID string) ([]Folder, error) {
+	rows, err := q.db.QueryContext(ctx, getCollectionFoldersForExport, folderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Folder
+	for rows.Next() {
+		var i Folder
+		if err := rows.Scan(
+			&i.FolderID,
+			&i.AccountID,
+			&i.FolderName,
+			&i.Path,
+			&i.Label,
+			&i.Starred,
+			&i.FolderCreatedAt,
+			&i.FolderUpdatedAt,
+			&i.SubfolderOf,
+			&i.FolderDeletedAt,
+			&i.TextsearchableIndexCol,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFolder = `-- name: GetFolder :one
 SELECT folder_id, account_id, folder_name, path, label, starred, folder_created_at, folder_updated_at, subfolder_of, folder_deleted_at, textsearchable_index_col FROM folder
 WHERE folder_id = $1
@@ -349,6 +429,50 @@ func (q *Queries) GetRootNodes(ctx context.Context, accountID int64) ([]Folder,
 	return items, nil
 }
 
+const getSharedRootFolders = `-- name: GetSharedRootFolders :many
+SELECT folder_id, account_id, folder_name, path, label, starred, folder_created_at, folder_updated_at, subfolder_of, folder_deleted_at, textsearchable_index_col FROM folder
+WHERE folder.account_id = $1 AND folder.subfolder_of IS NULL AND EXISTS (
+  SELECT 1 FROM collection_member
+  JOIN folder AS f ON f.folder_id = collection_member.collection_id
+  WHERE f.path <@ folder.path
+)
+`
+
+func (q *Queries) GetSharedRootFolders(ctx context.Context, accountID int64) ([]Folder, error) {
+	rows, err := q.db.QueryContext(ctx, getSharedRootFolders, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Folder
+	for rows.Next() {
+		var i Folder
+		if err := rows.Scan(
+			&i.FolderID,
+			&i.AccountID,
+			&i.FolderName,
+			&i.Path,
+			&i.Label,
+			&i.Starred,
+			&i.FolderCreatedAt,
+			&i.FolderUpdatedAt,
+			&i.SubfolderOf,
+			&i.FolderDeletedAt,
+			&i.TextsearchableIndexCol,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const moveFolder = `-- name: MoveFolder :many
 UPDATE folder SET path = (SELECT path FROM folder WHERE folder.label = $1) || SUBPATH(path, NLEVEL((SELECT path FROM folder WHERE folder.label = $2))-1) WHERE path <@ (SELECT path FROM folder WHERE folder.label = $3) RETURNING folder_id, account_id, folder_name, path, label, starred, folder_created_at, folder_updated_at, subfolder_of, folder_deleted_at, textsearchable_index_col
 `
@@ -466,6 +590,17 @@ func (q *Queries) MoveFoldersToRoot(ctx context.Context, arg MoveFoldersToRootPa
 	return items, nil
 }
 
+const reassignContributedFolders = `-- name: ReassignContributedFolders :exec
+UPDATE folder SET account_id = root.account_id
+FROM folder AS root
+WHERE folder.account_id = $1 AND root.path = SUBPATH(folder.path, 0, 1) AND root.account_id <> $1
+`
+
+func (q *Queries) ReassignContributedFolders(ctx context.Context, accountID int64) error {
+	_, err := q.db.ExecContext(ctx, reassignContributedFolders, accountID)
+	return err
+}
+
 const renameFolder = `-- name: RenameFolder :one
 UPDATE folder
 SET folder_name = $1
@@ -520,100 +655,6 @@ func (q *Queries) RestoreFolderFromTrash(ctx context.Context, folderID string) (
 	return i, err
 }
 
-const searchFolders = `-- name: SearchFolders :many
-SELECT folder_id, account_id, folder_name, path, label, starred, folder_created_at, folder_updated_at, subfolder_of, folder_deleted_at, textsearchable_index_col
-FROM folder
-WHERE textsearchable_index_col @@ plainto_tsquery($1) AND account_id = $2 AND folder_deleted_at IS NULL
-ORDER BY folder_created_at DESC
-`
-
-type SearchFoldersParams struct {
-	PlaintoTsquery string `json:"plainto_tsquery"`
-	AccountID      int64  `json:"account_id"`
-}
-
-func (q *Queries) SearchFolders(ctx context.Context, arg SearchFoldersParams) ([]Folder, error) {
-	rows, err := q.db.QueryContext(ctx, searchFolders, arg.PlaintoTsquery, arg.AccountID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Folder
-	for rows.Next() {
-		var i Folder
-		if err := rows.Scan(
-			&i.FolderID,
-			&i.AccountID,
-			&i.FolderName,
-			&i.Path,
-			&i.Label,
-			&i.Starred,
-			&i.FolderCreatedAt,
-			&i.FolderUpdatedAt,
-			&i.SubfolderOf,
-			&i.FolderDeletedAt,
-			&i.TextsearchableIndexCol,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const searchFolderz = `-- name: SearchFolderz :many
-SELECT folder_id, account_id, folder_name, path, label, starred, folder_created_at, folder_updated_at, subfolder_of, folder_deleted_at, textsearchable_index_col
-FROM folder
-WHERE folder_name ILIKE $1 AND account_id = $2 AND folder_deleted_at IS NULL
-ORDER BY folder_created_at DESC
-`
-
-type SearchFolderzParams struct {
-	FolderName string `json:"folder_name"`
-	AccountID  int64  `json:"account_id"`
-}
-
-func (q *Queries) SearchFolderz(ctx context.Context, arg SearchFolderzParams) ([]Folder, error) {
-	rows, err := q.db.QueryContext(ctx, searchFolderz, arg.FolderName, arg.AccountID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Folder
-	for rows.Next() {
-		var i Folder
-		if err := rows.Scan(
-			&i.FolderID,
-			&i.AccountID,
-			&i.FolderName,
-			&i.Path,
-			&i.Label,
-			&i.Starred,
-			&i.FolderCreatedAt,
-			&i.FolderUpdatedAt,
-			&i.SubfolderOf,
-			&i.FolderDeletedAt,
-			&i.TextsearchableIndexCol,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
 const starFolder = `-- name: StarFolder :one
 UPDATE folder
 SET starred = 'true'
@@ -663,6 +704,22 @@ func (q *Queries) ToggleFolderStarred(ctx context.Context, folderID string) (Fol
 	return i, err
 }
 
+const transferCollection = `-- name: TransferCollection :exec
+UPDATE folder SET account_id = $1
+WHERE account_id = $2 AND path <@ (SELECT f.path FROM folder AS f WHERE f.folder_id = $3)
+`
+
+type TransferCollectionParams struct {
+	NewOwnerID int64  `json:"new_owner_id"`
+	OldOwnerID int64  `json:"old_owner_id"`
+	FolderID   string `json:"folder_id"`
+}
+
+func (q *Queries) TransferCollection(ctx context.Context, arg TransferCollectionParams) error {
+	_, err := q.db.ExecContext(ctx, transferCollection, arg.NewOwnerID, arg.OldOwnerID, arg.FolderID)
+	return err
+}
+
 const unstarFolder = `-- name: UnstarFolder :one
 UPDATE folder
 SET starred = 'false'
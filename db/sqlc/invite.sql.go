@@ -51,6 +51,15 @@ func (q *Queries) CreateInvite(ctx context.Context, arg CreateInviteParams) (Mem
 	return i, err
 }
 
+const deleteAccountInvites = `-- name: DeleteAccountInvites :exec
+DELETE FROM member_invite WHERE collection_shared_by_email = $1 OR collection_shared_with = $1
+`
+
+func (q *Queries) DeleteAccountInvites(ctx context.Context, collectionSharedByEmail string) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountInvites, collectionSharedByEmail)
+	return err
+}
+
 const deleteInvite = `-- name: DeleteInvite :exec
 DELETE FROM member_invite WHERE invite_token = $1
 `
@@ -60,6 +69,53 @@ func (q *Queries) DeleteInvite(ctx context.Context, inviteToken string) error {
 	return err
 }
 
+const deleteInviteByID = `-- name: DeleteInviteByID :exec
+DELETE FROM member_invite WHERE invite_id = $1
+`
+
+func (q *Queries) DeleteInviteByID(ctx context.Context, inviteID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteInviteByID, inviteID)
+	return err
+}
+
+const deleteInvitesForEmail = `-- name: DeleteInvitesForEmail :exec
+DELETE FROM member_invite WHERE collection_shared_with = $1
+`
+
+func (q *Queries) DeleteInvitesForEmail(ctx context.Context, collectionSharedWith string) error {
+	_, err := q.db.ExecContext(ctx, deleteInvitesForEmail, collectionSharedWith)
+	return err
+}
+
+const deleteOrphanedInvites = `-- name: DeleteOrphanedInvites :exec
+DELETE FROM member_invite WHERE shared_collection_id NOT IN (SELECT folder_id FROM folder)
+`
+
+func (q *Queries) DeleteOrphanedInvites(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteOrphanedInvites)
+	return err
+}
+
+const getInvite = `-- name: GetInvite :one
+SELECT invite_id, invite_token, shared_collection_id, collection_shared_by_name, collection_shared_by_email, collection_shared_with, invite_expiry, member_access_level FROM member_invite WHERE invite_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetInvite(ctx context.Context, inviteID int64) (MemberInvite, error) {
+	row := q.db.QueryRowContext(ctx, getInvite, inviteID)
+	var i MemberInvite
+	err := row.Scan(
+		&i.InviteID,
+		&i.InviteToken,
+		&i.SharedCollectionID,
+		&i.CollectionSharedByName,
+		&i.CollectionSharedByEmail,
+		&i.CollectionSharedWith,
+		&i.InviteExpiry,
+		&i.MemberAccessLevel,
+	)
+	return i, err
+}
+
 const getInviteByToken = `-- name: GetInviteByToken :one
 SELECT invite_id, invite_token, shared_collection_id, collection_shared_by_name, collection_shared_by_email, collection_shared_with, invite_expiry, member_access_level FROM member_invite WHERE invite_token = $1 LIMIT 1
 `
@@ -79,3 +135,131 @@ func (q *Queries) GetInviteByToken(ctx context.Context, inviteToken string) (Mem
 	)
 	return i, err
 }
+
+const getInvitesByCollectionID = `-- name: GetInvitesByCollectionID :many
+SELECT invite_id, invite_token, shared_collection_id, collection_shared_by_name, collection_shared_by_email, collection_shared_with, invite_expiry, member_access_level FROM member_invite WHERE shared_collection_id = $1 ORDER BY invite_expiry DESC
+`
+
+func (q *Queries) GetInvitesByCollectionID(ctx context.Context, sharedCollectionID string) ([]MemberInvite, error) {
+	rows, err := q.db.QueryContext(ctx, getInvitesByCollectionID, sharedCollectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MemberInvite
+	for rows.Next() {
+		var i MemberInvite
+		if err := rows.Scan(
+			&i.InviteID,
+			&i.InviteToken,
+			&i.SharedCollectionID,
+			&i.CollectionSharedByName,
+			&i.CollectionSharedByEmail,
+			&i.CollectionSharedWith,
+			&i.InviteExpiry,
+			&i.MemberAccessLevel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInvitesSentByEmail = `-- name: GetInvitesSentByEmail :many
+SELECT invite_id, invite_token, shared_collection_id, collection_shared_by_name, collection_shared_by_email, collection_shared_with, invite_expiry, member_access_level FROM member_invite WHERE collection_shared_by_email = $1 ORDER BY invite_expiry DESC
+`
+
+func (q *Queries) GetInvitesSentByEmail(ctx context.Context, collectionSharedByEmail string) ([]MemberInvite, error) {
+	rows, err := q.db.QueryContext(ctx, getInvitesSentByEmail, collectionSharedByEmail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MemberInvite
+	for rows.Next() {
+		var i MemberInvite
+		if err := rows.Scan(
+			&i.InviteID,
+			&i.InviteToken,
+			&i.SharedCollectionID,
+			&i.CollectionSharedByName,
+			&i.CollectionSharedByEmail,
+			&i.CollectionSharedWith,
+			&i.InviteExpiry,
+			&i.MemberAccessLevel,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveInvitesToEmail = `-- name: MoveInvitesToEmail :exec
+UPDATE member_invite SET collection_shared_with = $1::text
+WHERE collection_shared_with = $2::text
+AND shared_collection_id NOT IN (SELECT shared_collection_id FROM member_invite WHERE collection_shared_with = $1::text)
+`
+
+type MoveInvitesToEmailParams struct {
+	NewEmail string `json:"new_email"`
+	OldEmail string `json:"old_email"`
+}
+
+func (q *Queries) MoveInvitesToEmail(ctx context.Context, arg MoveInvitesToEmailParams) error {
+	_, err := q.db.ExecContext(ctx, moveInvitesToEmail, arg.NewEmail, arg.OldEmail)
+	return err
+}
+
+const renewInvite = `-- name: RenewInvite :one
+UPDATE member_invite SET invite_token = $1, invite_expiry = $2 WHERE invite_id = $3 RETURNING invite_id, invite_token, shared_collection_id, collection_shared_by_name, collection_shared_by_email, collection_shared_with, invite_expiry, member_access_level
+`
+
+type RenewInviteParams struct {
+	InviteToken  string    `json:"invite_token"`
+	InviteExpiry time.Time `json:"invite_expiry"`
+	InviteID     int64     `json:"invite_id"`
+}
+
+func (q *Queries) RenewInvite(ctx context.Context, arg RenewInviteParams) (MemberInvite, error) {
+	row := q.db.QueryRowContext(ctx, renewInvite, arg.InviteToken, arg.InviteExpiry, arg.InviteID)
+	var i MemberInvite
+	err := row.Scan(
+		&i.InviteID,
+		&i.InviteToken,
+		&i.SharedCollectionID,
+		&i.CollectionSharedByName,
+		&i.CollectionSharedByEmail,
+		&i.CollectionSharedWith,
+		&i.InviteExpiry,
+		&i.MemberAccessLevel,
+	)
+	return i, err
+}
+
+const updateInviterEmail = `-- name: UpdateInviterEmail :exec
+UPDATE member_invite SET collection_shared_by_email = $1::text WHERE collection_shared_by_email = $2::text
+`
+
+type UpdateInviterEmailParams struct {
+	NewEmail string `json:"new_email"`
+	OldEmail string `json:"old_email"`
+}
+
+func (q *Queries) UpdateInviterEmail(ctx context.Context, arg UpdateInviterEmailParams) error {
+	_, err := q.db.ExecContext(ctx, updateInviterEmail, arg.NewEmail, arg.OldEmail)
+	return err
+}
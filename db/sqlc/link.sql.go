@@ -8,12 +8,13 @@ package sqlc
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 const addLink = `-- name: AddLink :one
 INSERT INTO link (link_id, link_title, link_hostname, link_url, link_favicon, account_id, folder_id, link_thumbnail)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col
+RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
 `
 
 type AddLinkParams struct {
@@ -52,13 +53,14 @@ func (q *Queries) AddLink(ctx context.Context, arg AddLinkParams) (Link, error)
 		&i.AddedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.MetadataStatus,
 		&i.TextsearchableIndexCol,
 	)
 	return i, err
 }
 
 const deleteLinkForever = `-- name: DeleteLinkForever :one
-DELETE FROM link WHERE link_id = $1 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col
+DELETE FROM link WHERE link_id = $1 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
 `
 
 func (q *Queries) DeleteLinkForever(ctx context.Context, linkID string) (Link, error) {
@@ -77,13 +79,103 @@ func (q *Queries) DeleteLinkForever(ctx context.Context, linkID string) (Link, e
 		&i.AddedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.MetadataStatus,
 		&i.TextsearchableIndexCol,
 	)
 	return i, err
 }
 
+const getAccountLinksForExport = `-- name: GetAccountLinksForExport :many
+SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col FROM link WHERE account_id = $1 ORDER BY added_at
+`
+
+func (q *Queries) GetAccountLinksForExport(ctx context.Context, accountID int64) ([]Link, error) {
+	rows, err := q.db.QueryContext(ctx, getAccountLinksForExport, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Link
+	for rows.Next() {
+		var i Link
+		if err := rows.Scan(
+			&i.LinkID,
+			&i.LinkTitle,
+			&i.LinkThumbnail,
+			&i.LinkFavicon,
+			&i.LinkHostname,
+			&i.LinkUrl,
+			&i.LinkNotes,
+			&i.AccountID,
+			&i.FolderID,
+			&i.AddedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.MetadataStatus,
+			&i.TextsearchableIndexCol,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCollectionLinksForExport = `-- name: GetCollectionLinksForExport :many
+SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col FROM link
+WHERE folder_id IN (
+  SELECT folder_id FROM folder
+  WHERE path <@ (SELECT path FROM folder AS f WHERE f.folder_id = $1)
+)
+ORDER BY added_at
+`
+
+func (q *Queries) GetCollectionLinksForExport(ctx context.Context, folderID string) ([]Link, error) {
+	rows, err := q.db.QueryContext(ctx, getCollectionLinksForExport, folderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Link
+	for rows.Next() {
+		var i Link
+		if err := rows.Scan(
+			&i.LinkID,
+			&i.LinkTitle,
+			&i.LinkThumbnail,
+			&i.LinkFavicon,
+			&i.LinkHostname,
+			&i.LinkUrl,
+			&i.LinkNotes,
+			&i.AccountID,
+			&i.FolderID,
+			&i.AddedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.MetadataStatus,
+			&i.TextsearchableIndexCol,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFolderLinks = `-- name: GetFolderLinks :many
-SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col FROM link WHERE folder_id = $1 AND deleted_at IS NULL ORDER BY added_at DESC
+SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col FROM link WHERE folder_id = $1 AND deleted_at IS NULL ORDER BY added_at DESC
 `
 
 func (q *Queries) GetFolderLinks(ctx context.Context, folderID sql.NullString) ([]Link, error) {
@@ -108,6 +200,7 @@ func (q *Queries) GetFolderLinks(ctx context.Context, folderID sql.NullString) (
 			&i.AddedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.MetadataStatus,
 			&i.TextsearchableIndexCol,
 		); err != nil {
 			return nil, err
@@ -124,7 +217,7 @@ func (q *Queries) GetFolderLinks(ctx context.Context, folderID sql.NullString) (
 }
 
 const getLink = `-- name: GetLink :one
-SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col FROM link
+SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col FROM link
 WHERE link_id = $1
 LIMIT 1
 `
@@ -145,17 +238,19 @@ func (q *Queries) GetLink(ctx context.Context, linkID string) (Link, error) {
 		&i.AddedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.MetadataStatus,
 		&i.TextsearchableIndexCol,
 	)
 	return i, err
 }
 
-const getLinksByUserID = `-- name: GetLinksByUserID :many
-SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col FROM link WHERE account_id = $1
+const getLinksDeletedWithAccount = `-- name: GetLinksDeletedWithAccount :many
+SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col FROM link
+WHERE account_id = $1 OR folder_id IN (SELECT folder_id FROM folder WHERE folder.account_id = $1)
 `
 
-func (q *Queries) GetLinksByUserID(ctx context.Context, accountID int64) ([]Link, error) {
-	rows, err := q.db.QueryContext(ctx, getLinksByUserID, accountID)
+func (q *Queries) GetLinksDeletedWithAccount(ctx context.Context, accountID int64) ([]Link, error) {
+	rows, err := q.db.QueryContext(ctx, getLinksDeletedWithAccount, accountID)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +271,7 @@ func (q *Queries) GetLinksByUserID(ctx context.Context, accountID int64) ([]Link
 			&i.AddedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.MetadataStatus,
 			&i.TextsearchableIndexCol,
 		); err != nil {
 			return nil, err
@@ -192,7 +288,7 @@ func (q *Queries) GetLinksByUserID(ctx context.Context, accountID int64) ([]Link
 }
 
 const getLinksMovedToTrash = `-- name: GetLinksMovedToTrash :many
-SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col FROM link WHERE deleted_at IS NOT NULL AND account_id = $1 ORDER BY deleted_at DESC
+SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col FROM link WHERE deleted_at IS NOT NULL AND account_id = $1 ORDER BY deleted_at DESC
 `
 
 func (q *Queries) GetLinksMovedToTrash(ctx context.Context, accountID int64) ([]Link, error) {
@@ -217,6 +313,7 @@ func (q *Queries) GetLinksMovedToTrash(ctx context.Context, accountID int64) ([]
 			&i.AddedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.MetadataStatus,
 			&i.TextsearchableIndexCol,
 		); err != nil {
 			return nil, err
@@ -233,7 +330,7 @@ func (q *Queries) GetLinksMovedToTrash(ctx context.Context, accountID int64) ([]
 }
 
 const getRootLinks = `-- name: GetRootLinks :many
-SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col FROM link WHERE account_id = $1 AND folder_id IS NULL AND deleted_at IS NULL ORDER BY added_at DESC
+SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col FROM link WHERE account_id = $1 AND folder_id IS NULL AND deleted_at IS NULL ORDER BY added_at DESC
 `
 
 func (q *Queries) GetRootLinks(ctx context.Context, accountID int64) ([]Link, error) {
@@ -258,6 +355,7 @@ func (q *Queries) GetRootLinks(ctx context.Context, accountID int64) ([]Link, er
 			&i.AddedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.MetadataStatus,
 			&i.TextsearchableIndexCol,
 		); err != nil {
 			return nil, err
@@ -273,8 +371,77 @@ func (q *Queries) GetRootLinks(ctx context.Context, accountID int64) ([]Link, er
 	return items, nil
 }
 
+const importLink = `-- name: ImportLink :one
+INSERT INTO link (link_id, link_title, link_hostname, link_url, link_favicon, account_id, folder_id, link_thumbnail, link_notes, added_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
+`
+
+type ImportLinkParams struct {
+	LinkID        string         `json:"link_id"`
+	LinkTitle     string         `json:"link_title"`
+	LinkHostname  string         `json:"link_hostname"`
+	LinkUrl       string         `json:"link_url"`
+	LinkFavicon   string         `json:"link_favicon"`
+	AccountID     int64          `json:"account_id"`
+	FolderID      sql.NullString `json:"folder_id"`
+	LinkThumbnail string         `json:"link_thumbnail"`
+	LinkNotes     string         `json:"link_notes"`
+	AddedAt       time.Time      `json:"added_at"`
+}
+
+func (q *Queries) ImportLink(ctx context.Context, arg ImportLinkParams) (Link, error) {
+	row := q.db.QueryRowContext(ctx, importLink,
+		arg.LinkID,
+		arg.LinkTitle,
+		arg.LinkHostname,
+		arg.LinkUrl,
+		arg.LinkFavicon,
+		arg.AccountID,
+		arg.FolderID,
+		arg.LinkThumbnail,
+		arg.LinkNotes,
+		arg.AddedAt,
+	)
+	var i Link
+	err := row.Scan(
+		&i.LinkID,
+		&i.LinkTitle,
+		&i.LinkThumbnail,
+		&i.LinkFavicon,
+		&i.LinkHostname,
+		&i.LinkUrl,
+		&i.LinkNotes,
+		&i.AccountID,
+		&i.FolderID,
+		&i.AddedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.MetadataStatus,
+		&i.TextsearchableIndexCol,
+	)
+	return i, err
+}
+
+const linkExistsInFolder = `-- name: LinkExistsInFolder :one
+SELECT EXISTS (SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col FROM link WHERE account_id = $1 AND link_url = $2 AND COALESCE(folder_id, '') = $3::text AND deleted_at IS NULL)
+`
+
+type LinkExistsInFolderParams struct {
+	AccountID int64  `json:"account_id"`
+	LinkUrl   string `json:"link_url"`
+	FolderID  string `json:"folder_id"`
+}
+
+func (q *Queries) LinkExistsInFolder(ctx context.Context, arg LinkExistsInFolderParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, linkExistsInFolder, arg.AccountID, arg.LinkUrl, arg.FolderID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const moveLinkToFolder = `-- name: MoveLinkToFolder :one
-UPDATE link SET folder_id = $1 WHERE link_id = $2 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col
+UPDATE link SET folder_id = $1 WHERE link_id = $2 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
 `
 
 type MoveLinkToFolderParams struct {
@@ -298,13 +465,14 @@ func (q *Queries) MoveLinkToFolder(ctx context.Context, arg MoveLinkToFolderPara
 		&i.AddedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.MetadataStatus,
 		&i.TextsearchableIndexCol,
 	)
 	return i, err
 }
 
 const moveLinkToRoot = `-- name: MoveLinkToRoot :one
-UPDATE link SET folder_id = NULL WHERE link_id = $1 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col
+UPDATE link SET folder_id = NULL WHERE link_id = $1 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
 `
 
 func (q *Queries) MoveLinkToRoot(ctx context.Context, linkID string) (Link, error) {
@@ -323,13 +491,14 @@ func (q *Queries) MoveLinkToRoot(ctx context.Context, linkID string) (Link, erro
 		&i.AddedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.MetadataStatus,
 		&i.TextsearchableIndexCol,
 	)
 	return i, err
 }
 
 const moveLinkToTrash = `-- name: MoveLinkToTrash :one
-UPDATE link SET deleted_at = CURRENT_TIMESTAMP WHERE link_id = $1 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col
+UPDATE link SET deleted_at = CURRENT_TIMESTAMP WHERE link_id = $1 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
 `
 
 func (q *Queries) MoveLinkToTrash(ctx context.Context, linkID string) (Link, error) {
@@ -348,13 +517,25 @@ func (q *Queries) MoveLinkToTrash(ctx context.Context, linkID string) (Link, err
 		&i.AddedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.MetadataStatus,
 		&i.TextsearchableIndexCol,
 	)
 	return i, err
 }
 
+const reassignContributedLinks = `-- name: ReassignContributedLinks :exec
+UPDATE link SET account_id = folder.account_id
+FROM folder
+WHERE link.account_id = $1 AND link.folder_id = folder.folder_id AND folder.account_id <> $1
+`
+
+func (q *Queries) ReassignContributedLinks(ctx context.Context, accountID int64) error {
+	_, err := q.db.ExecContext(ctx, reassignContributedLinks, accountID)
+	return err
+}
+
 const renameLink = `-- name: RenameLink :one
-UPDATE link SET link_title = $1 WHERE link_id = $2 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col
+UPDATE link SET link_title = $1, updated_at = CURRENT_TIMESTAMP WHERE link_id = $2 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
 `
 
 type RenameLinkParams struct {
@@ -378,13 +559,14 @@ func (q *Queries) RenameLink(ctx context.Context, arg RenameLinkParams) (Link, e
 		&i.AddedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.MetadataStatus,
 		&i.TextsearchableIndexCol,
 	)
 	return i, err
 }
 
 const restoreLinkFromTrash = `-- name: RestoreLinkFromTrash :one
-UPDATE link SET deleted_at = NULL WHERE link_id = $1 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col
+UPDATE link SET deleted_at = NULL WHERE link_id = $1 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
 `
 
 func (q *Queries) RestoreLinkFromTrash(ctx context.Context, linkID string) (Link, error) {
@@ -403,105 +585,153 @@ func (q *Queries) RestoreLinkFromTrash(ctx context.Context, linkID string) (Link
 		&i.AddedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.MetadataStatus,
 		&i.TextsearchableIndexCol,
 	)
 	return i, err
 }
 
-const searchLinks = `-- name: SearchLinks :many
-SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col
-FROM link
-WHERE textsearchable_index_col @@ plainto_tsquery($1) AND account_id = $2 AND deleted_at IS NULL
-ORDER BY added_at DESC
+const setLinkMetadataStatus = `-- name: SetLinkMetadataStatus :exec
+UPDATE link SET metadata_status = $1 WHERE link_id = $2
 `
 
-type SearchLinksParams struct {
-	PlaintoTsquery string `json:"plainto_tsquery"`
-	AccountID      int64  `json:"account_id"`
+type SetLinkMetadataStatusParams struct {
+	MetadataStatus LinkMetadataStatus `json:"metadata_status"`
+	LinkID         string             `json:"link_id"`
 }
 
-func (q *Queries) SearchLinks(ctx context.Context, arg SearchLinksParams) ([]Link, error) {
-	rows, err := q.db.QueryContext(ctx, searchLinks, arg.PlaintoTsquery, arg.AccountID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Link
-	for rows.Next() {
-		var i Link
-		if err := rows.Scan(
-			&i.LinkID,
-			&i.LinkTitle,
-			&i.LinkThumbnail,
-			&i.LinkFavicon,
-			&i.LinkHostname,
-			&i.LinkUrl,
-			&i.LinkNotes,
-			&i.AccountID,
-			&i.FolderID,
-			&i.AddedAt,
-			&i.UpdatedAt,
-			&i.DeletedAt,
-			&i.TextsearchableIndexCol,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) SetLinkMetadataStatus(ctx context.Context, arg SetLinkMetadataStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setLinkMetadataStatus, arg.MetadataStatus, arg.LinkID)
+	return err
 }
 
-const searchLinkz = `-- name: SearchLinkz :many
-SELECT link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, textsearchable_index_col
-FROM link
-WHERE link_title ILIKE $1 AND account_id = $2 AND deleted_at IS NULL
-ORDER BY added_at DESC
+const setLinkThumbnail = `-- name: SetLinkThumbnail :one
+UPDATE link SET link_thumbnail = $1, updated_at = CURRENT_TIMESTAMP WHERE link_id = $2 RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
 `
 
-type SearchLinkzParams struct {
-	LinkTitle string `json:"link_title"`
-	AccountID int64  `json:"account_id"`
+type SetLinkThumbnailParams struct {
+	LinkThumbnail string `json:"link_thumbnail"`
+	LinkID        string `json:"link_id"`
 }
 
-func (q *Queries) SearchLinkz(ctx context.Context, arg SearchLinkzParams) ([]Link, error) {
-	rows, err := q.db.QueryContext(ctx, searchLinkz, arg.LinkTitle, arg.AccountID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Link
-	for rows.Next() {
-		var i Link
-		if err := rows.Scan(
-			&i.LinkID,
-			&i.LinkTitle,
-			&i.LinkThumbnail,
-			&i.LinkFavicon,
-			&i.LinkHostname,
-			&i.LinkUrl,
-			&i.LinkNotes,
-			&i.AccountID,
-			&i.FolderID,
-			&i.AddedAt,
-			&i.UpdatedAt,
-			&i.DeletedAt,
-			&i.TextsearchableIndexCol,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) SetLinkThumbnail(ctx context.Context, arg SetLinkThumbnailParams) (Link, error) {
+	row := q.db.QueryRowContext(ctx, setLinkThumbnail, arg.LinkThumbnail, arg.LinkID)
+	var i Link
+	err := row.Scan(
+		&i.LinkID,
+		&i.LinkTitle,
+		&i.LinkThumbnail,
+		&i.LinkFavicon,
+		&i.LinkHostname,
+		&i.LinkUrl,
+		&i.LinkNotes,
+		&i.AccountID,
+		&i.FolderID,
+		&i.AddedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.MetadataStatus,
+		&i.TextsearchableIndexCol,
+	)
+	return i, err
+}
+
+const updateLink = `-- name: UpdateLink :one
+UPDATE link
+SET link_title = $1,
+  link_notes = $2,
+  link_url = $3,
+  link_hostname = $4,
+  metadata_status = $5,
+  updated_at = CURRENT_TIMESTAMP
+WHERE link_id = $6
+RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
+`
+
+type UpdateLinkParams struct {
+	LinkTitle      string             `json:"link_title"`
+	LinkNotes      string             `json:"link_notes"`
+	LinkUrl        string             `json:"link_url"`
+	LinkHostname   string             `json:"link_hostname"`
+	MetadataStatus LinkMetadataStatus `json:"metadata_status"`
+	LinkID         string             `json:"link_id"`
+}
+
+func (q *Queries) UpdateLink(ctx context.Context, arg UpdateLinkParams) (Link, error) {
+	row := q.db.QueryRowContext(ctx, updateLink,
+		arg.LinkTitle,
+		arg.LinkNotes,
+		arg.LinkUrl,
+		arg.LinkHostname,
+		arg.MetadataStatus,
+		arg.LinkID,
+	)
+	var i Link
+	err := row.Scan(
+		&i.LinkID,
+		&i.LinkTitle,
+		&i.LinkThumbnail,
+		&i.LinkFavicon,
+		&i.LinkHostname,
+		&i.LinkUrl,
+		&i.LinkNotes,
+		&i.AccountID,
+		&i.FolderID,
+		&i.AddedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.MetadataStatus,
+		&i.TextsearchableIndexCol,
+	)
+	return i, err
+}
+
+const updateLinkMetadata = `-- name: UpdateLinkMetadata :one
+UPDATE link
+SET link_title = CASE WHEN link_title = link_url THEN $1::text ELSE link_title END,
+  link_favicon = $2,
+  link_thumbnail = $3,
+  metadata_status = 'ready'
+WHERE link_id = $4 AND link_url = $5 AND link_thumbnail = $6 AND link_favicon = $7
+RETURNING link_id, link_title, link_thumbnail, link_favicon, link_hostname, link_url, link_notes, account_id, folder_id, added_at, updated_at, deleted_at, metadata_status, textsearchable_index_col
+`
+
+type UpdateLinkMetadataParams struct {
+	LinkTitle     string `json:"link_title"`
+	LinkFavicon   string `json:"link_favicon"`
+	LinkThumbnail string `json:"link_thumbnail"`
+	LinkID        string `json:"link_id"`
+	FetchedUrl    string `json:"fetched_url"`
+	OldThumbnail  string `json:"old_thumbnail"`
+	OldFavicon    string `json:"old_favicon"`
+}
+
+func (q *Queries) UpdateLinkMetadata(ctx context.Context, arg UpdateLinkMetadataParams) (Link, error) {
+	row := q.db.QueryRowContext(ctx, updateLinkMetadata,
+		arg.LinkTitle,
+		arg.LinkFavicon,
+		arg.LinkThumbnail,
+		arg.LinkID,
+		arg.FetchedUrl,
+		arg.OldThumbnail,
+		arg.OldFavicon,
+	)
+	var i Link
+	err := row.Scan(
+		&i.LinkID,
+		&i.LinkTitle,
+		&i.LinkThumbnail,
+		&i.LinkFavicon,
+		&i.LinkHostname,
+		&i.LinkUrl,
+		&i.LinkNotes,
+		&i.AccountID,
+		&i.FolderID,
+		&i.AddedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.MetadataStatus,
+		&i.TextsearchableIndexCol,
+	)
+	return i, err
 }
@@ -97,16 +97,122 @@ func (ns NullCollectionAccessLevel) Value() (driver.Value, error) {
 	return string(ns.CollectionAccessLevel), nil
 }
 
+type LinkArchiveStatus string
+
+const (
+	LinkArchiveStatusPending  LinkArchiveStatus = "pending"
+	LinkArchiveStatusArchived LinkArchiveStatus = "archived"
+	LinkArchiveStatusFailed   LinkArchiveStatus = "failed"
+)
+
+func (e *LinkArchiveStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LinkArchiveStatus(s)
+	case string:
+		*e = LinkArchiveStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LinkArchiveStatus: %T", src)
+	}
+	return nil
+}
+
+type NullLinkArchiveStatus struct {
+	LinkArchiveStatus LinkArchiveStatus
+	Valid             bool // Valid is true if LinkArchiveStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLinkArchiveStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.LinkArchiveStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LinkArchiveStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLinkArchiveStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LinkArchiveStatus), nil
+}
+
+type LinkMetadataStatus string
+
+const (
+	LinkMetadataStatusPending LinkMetadataStatus = "pending"
+	LinkMetadataStatusReady   LinkMetadataStatus = "ready"
+	LinkMetadataStatusFailed  LinkMetadataStatus = "failed"
+)
+
+func (e *LinkMetadataStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = LinkMetadataStatus(s)
+	case string:
+		*e = LinkMetadataStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for LinkMetadataStatus: %T", src)
+	}
+	return nil
+}
+
+type NullLinkMetadataStatus struct {
+	LinkMetadataStatus LinkMetadataStatus
+	Valid              bool // Valid is true if LinkMetadataStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullLinkMetadataStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.LinkMetadataStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.LinkMetadataStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullLinkMetadataStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.LinkMetadataStatus), nil
+}
+
 type Account struct {
 	ID              int64          `json:"id"`
 	Fullname        string         `json:"fullname"`
 	Email           string         `json:"email"`
 	EmailVerified   bool           `json:"email_verified"`
 	Picture         string         `json:"picture"`
-	AccountPassword string         `json:"account_password"`
+	AccountPassword string         `json:"-"`
 	CreatedAt       time.Time      `json:"created_at"`
 	Intention       sql.NullString `json:"intention"`
 	LastLogin       time.Time      `json:"last_login"`
+	IsAdmin         bool           `json:"is_admin"`
+	SuspendedAt     sql.NullTime   `json:"suspended_at"`
+}
+
+type AccountDeletion struct {
+	AccountID         int64        `json:"account_id"`
+	Code              string       `json:"code"`
+	CodeExpiry        time.Time    `json:"code_expiry"`
+	SharedCollections string       `json:"shared_collections"`
+	DeleteAfter       sql.NullTime `json:"delete_after"`
+	RequestedAt       time.Time    `json:"requested_at"`
+}
+
+type AccountIdentity struct {
+	IdentityID    int64     `json:"identity_id"`
+	AccountID     int64     `json:"account_id"`
+	Provider      string    `json:"provider"`
+	Subject       string    `json:"subject"`
+	IdentityEmail string    `json:"identity_email"`
+	LinkedAt      time.Time `json:"linked_at"`
 }
 
 type AccountSession struct {
@@ -116,6 +222,16 @@ type AccountSession struct {
 	Expiry         time.Time `json:"expiry"`
 	UserAgent      string    `json:"user_agent"`
 	ClientIp       string    `json:"client_ip"`
+	SessionID      string    `json:"session_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type AccountTotp struct {
+	AccountID     int64        `json:"account_id"`
+	TotpSecret    string       `json:"totp_secret"`
+	ConfirmedAt   sql.NullTime `json:"confirmed_at"`
+	LastUsedStep  int64        `json:"last_used_step"`
+	TotpCreatedAt time.Time    `json:"totp_created_at"`
 }
 
 type CollectionMember struct {
@@ -126,15 +242,18 @@ type CollectionMember struct {
 }
 
 type Contact struct {
-	ID          int64  `json:"id"`
-	Account     int64  `json:"account"`
-	MessageBody string `json:"message_body"`
+	ID          int64        `json:"id"`
+	Account     int64        `json:"account"`
+	MessageBody string       `json:"message_body"`
+	SentAt      time.Time    `json:"sent_at"`
+	ResolvedAt  sql.NullTime `json:"resolved_at"`
 }
 
 type EmailVerification struct {
-	Code   string    `json:"code"`
-	Email  string    `json:"email"`
-	Expiry time.Time `json:"expiry"`
+	Code      string        `json:"code"`
+	Email     string        `json:"email"`
+	Expiry    time.Time     `json:"expiry"`
+	AccountID sql.NullInt64 `json:"account_id"`
 }
 
 type Folder struct {
@@ -148,23 +267,47 @@ type Folder struct {
 	FolderUpdatedAt        time.Time      `json:"folder_updated_at"`
 	SubfolderOf            sql.NullString `json:"subfolder_of"`
 	FolderDeletedAt        sql.NullTime   `json:"folder_deleted_at"`
-	TextsearchableIndexCol interface{}    `json:"textsearchable_index_col"`
+	TextsearchableIndexCol interface{}    `json:"-"`
+}
+
+type FolderTag struct {
+	FolderID string    `json:"folder_id"`
+	TagID    int64     `json:"tag_id"`
+	TaggedAt time.Time `json:"tagged_at"`
 }
 
 type Link struct {
-	LinkID                 string         `json:"link_id"`
-	LinkTitle              string         `json:"link_title"`
-	LinkThumbnail          string         `json:"link_thumbnail"`
-	LinkFavicon            string         `json:"link_favicon"`
-	LinkHostname           string         `json:"link_hostname"`
-	LinkUrl                string         `json:"link_url"`
-	LinkNotes              string         `json:"link_notes"`
-	AccountID              int64          `json:"account_id"`
-	FolderID               sql.NullString `json:"folder_id"`
-	AddedAt                time.Time      `json:"added_at"`
-	UpdatedAt              time.Time      `json:"updated_at"`
-	DeletedAt              sql.NullTime   `json:"deleted_at"`
-	TextsearchableIndexCol interface{}    `json:"textsearchable_index_col"`
+	LinkID                 string             `json:"link_id"`
+	LinkTitle              string             `json:"link_title"`
+	LinkThumbnail          string             `json:"link_thumbnail"`
+	LinkFavicon            string             `json:"link_favicon"`
+	LinkHostname           string             `json:"link_hostname"`
+	LinkUrl                string             `json:"link_url"`
+	LinkNotes              string             `json:"link_notes"`
+	AccountID              int64              `json:"account_id"`
+	FolderID               sql.NullString     `json:"folder_id"`
+	AddedAt                time.Time          `json:"added_at"`
+	UpdatedAt              time.Time          `json:"updated_at"`
+	DeletedAt              sql.NullTime       `json:"deleted_at"`
+	MetadataStatus         LinkMetadataStatus `json:"metadata_status"`
+	TextsearchableIndexCol interface{}        `json:"-"`
+}
+
+type LinkArchive struct {
+	LinkID                 string            `json:"link_id"`
+	ArchiveStatus          LinkArchiveStatus `json:"archive_status"`
+	SnapshotKey            string            `json:"snapshot_key"`
+	ArticleKey             string            `json:"article_key"`
+	ArticleText            string            `json:"article_text"`
+	RequestedAt            time.Time         `json:"requested_at"`
+	ArchivedAt             sql.NullTime      `json:"archived_at"`
+	TextsearchableIndexCol interface{}       `json:"-"`
+}
+
+type LinkTag struct {
+	LinkID   string    `json:"link_id"`
+	TagID    int64     `json:"tag_id"`
+	TaggedAt time.Time `json:"tagged_at"`
 }
 
 type MemberInvite struct {
@@ -178,6 +321,15 @@ type MemberInvite struct {
 	MemberAccessLevel       AccessLevel `json:"member_access_level"`
 }
 
+type OauthState struct {
+	State         string        `json:"state"`
+	Provider      string        `json:"provider"`
+	Nonce         string        `json:"nonce"`
+	CodeVerifier  string        `json:"code_verifier"`
+	LinkAccountID sql.NullInt64 `json:"link_account_id"`
+	StateExpiry   time.Time     `json:"state_expiry"`
+}
+
 type PasswordResetToken struct {
 	ID          sql.NullInt64 `json:"id"`
 	AccountID   int64         `json:"account_id"`
@@ -185,11 +337,72 @@ type PasswordResetToken struct {
 	TokenExpiry time.Time     `json:"token_expiry"`
 }
 
+type PersonalAccessToken struct {
+	TokenID        int64        `json:"token_id"`
+	AccountID      int64        `json:"account_id"`
+	TokenName      string       `json:"token_name"`
+	TokenHash      string       `json:"token_hash"`
+	TokenPrefix    string       `json:"token_prefix"`
+	Scopes         string       `json:"scopes"`
+	ExpiresAt      sql.NullTime `json:"expires_at"`
+	LastUsedAt     sql.NullTime `json:"last_used_at"`
+	TokenCreatedAt time.Time    `json:"token_created_at"`
+}
+
 type PublicSharedCollection struct {
-	CollectionID          string                `json:"collection_id"`
-	CollectionPassword    string                `json:"collection_password"`
-	CollectionSharedBy    int64                 `json:"collection_shared_by"`
-	CollectionSharedAt    time.Time             `json:"collection_shared_at"`
-	CollectionShareExpiry sql.NullTime          `json:"collection_share_expiry"`
-	CollectionAccessLevel CollectionAccessLevel `json:"collection_access_level"`
+	ShareToken            string         `json:"share_token"`
+	CollectionID          string         `json:"collection_id"`
+	CollectionPassword    sql.NullString `json:"collection_password"`
+	CollectionSharedBy    int64          `json:"collection_shared_by"`
+	CollectionSharedAt    time.Time      `json:"collection_shared_at"`
+	CollectionShareExpiry sql.NullTime   `json:"collection_share_expiry"`
+}
+
+type RateLimitBucket struct {
+	BucketKey string    `json:"bucket_key"`
+	Tokens    float64   `json:"tokens"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type SmartFolder struct {
+	SmartFolderID   string         `json:"smart_folder_id"`
+	AccountID       int64          `json:"account_id"`
+	FolderID        sql.NullString `json:"folder_id"`
+	SmartFolderName string         `json:"smart_folder_name"`
+	SearchQuery     string         `json:"search_query"`
+	Starred         bool           `json:"starred"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+type Tag struct {
+	TagID        int64     `json:"tag_id"`
+	AccountID    int64     `json:"account_id"`
+	TagName      string    `json:"tag_name"`
+	TagCreatedAt time.Time `json:"tag_created_at"`
+}
+
+type TotpRecoveryCode struct {
+	CodeID    int64        `json:"code_id"`
+	AccountID int64        `json:"account_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+}
+
+type WebauthnChallenge struct {
+	Challenge       string        `json:"challenge"`
+	Ceremony        string        `json:"ceremony"`
+	AccountID       sql.NullInt64 `json:"account_id"`
+	ChallengeExpiry time.Time     `json:"challenge_expiry"`
+}
+
+type WebauthnCredential struct {
+	CredentialID        string       `json:"credential_id"`
+	AccountID           int64        `json:"account_id"`
+	PublicKey           []byte       `json:"public_key"`
+	SignCount           int64        `json:"sign_count"`
+	CredentialName      string       `json:"credential_name"`
+	CredentialCreatedAt time.Time    `json:"credential_created_at"`
+	LastUsedAt          sql.NullTime `json:"last_used_at"`
 }
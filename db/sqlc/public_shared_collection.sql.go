@@ -10,36 +10,115 @@ import (
 	"database/sql"
 )
 
+const getPublicSharedCollection = `-- name: GetPublicSharedCollection :one
+SELECT share_token, collection_id, collection_password, collection_shared_by, collection_shared_at, collection_share_expiry FROM public_shared_collection WHERE share_token = $1 LIMIT 1
+`
+
+func (q *Queries) GetPublicSharedCollection(ctx context.Context, shareToken string) (PublicSharedCollection, error) {
+	row := q.db.QueryRowContext(ctx, getPublicSharedCollection, shareToken)
+	var i PublicSharedCollection
+	err := row.Scan(
+		&i.ShareToken,
+		&i.CollectionID,
+		&i.CollectionPassword,
+		&i.CollectionSharedBy,
+		&i.CollectionSharedAt,
+		&i.CollectionShareExpiry,
+	)
+	return i, err
+}
+
+const getPublicSharedCollectionsByCollectionID = `-- name: GetPublicSharedCollectionsByCollectionID :many
+SELECT share_token, collection_id, collection_password, collection_shared_by, collection_shared_at, collection_share_expiry FROM public_shared_collection WHERE collection_id = $1 ORDER BY collection_shared_at DESC
+`
+
+func (q *Queries) GetPublicSharedCollectionsByCollectionID(ctx context.Context, collectionID string) ([]PublicSharedCollection, error) {
+	rows, err := q.db.QueryContext(ctx, getPublicSharedCollectionsByCollectionID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PublicSharedCollection
+	for rows.Next() {
+		var i PublicSharedCollection
+		if err := rows.Scan(
+			&i.ShareToken,
+			&i.CollectionID,
+			&i.CollectionPassword,
+			&i.CollectionSharedBy,
+			&i.CollectionSharedAt,
+			&i.CollectionShareExpiry,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokePublicSharedCollection = `-- name: RevokePublicSharedCollection :exec
+DELETE FROM public_shared_collection WHERE share_token = $1
+`
+
+func (q *Queries) RevokePublicSharedCollection(ctx context.Context, shareToken string) error {
+	_, err := q.db.ExecContext(ctx, revokePublicSharedCollection, shareToken)
+	return err
+}
+
 const shareCollectionWithPublicLink = `-- name: ShareCollectionWithPublicLink :one
-INSERT INTO public_shared_collection (collection_id, collection_password, collection_shared_by, collection_share_expiry, collection_access_level)
+INSERT INTO public_shared_collection (share_token, collection_id, collection_password, collection_shared_by, collection_share_expiry)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING collection_id, collection_password, collection_shared_by, collection_shared_at, collection_share_expiry, collection_access_level
+RETURNING share_token, collection_id, collection_password, collection_shared_by, collection_shared_at, collection_share_expiry
 `
 
 type ShareCollectionWithPublicLinkParams struct {
-	CollectionID          string                `json:"collection_id"`
-	CollectionPassword    string                `json:"collection_password"`
-	CollectionSharedBy    int64                 `json:"collection_shared_by"`
-	CollectionShareExpiry sql.NullTime          `json:"collection_share_expiry"`
-	CollectionAccessLevel CollectionAccessLevel `json:"collection_access_level"`
+	ShareToken            string         `json:"share_token"`
+	CollectionID          string         `json:"collection_id"`
+	CollectionPassword    sql.NullString `json:"collection_password"`
+	CollectionSharedBy    int64          `json:"collection_shared_by"`
+	CollectionShareExpiry sql.NullTime   `json:"collection_share_expiry"`
 }
 
 func (q *Queries) ShareCollectionWithPublicLink(ctx context.Context, arg ShareCollectionWithPublicLinkParams) (PublicSharedCollection, error) {
 	row := q.db.QueryRowContext(ctx, shareCollectionWithPublicLink,
+		arg.ShareToken,
 		arg.CollectionID,
 		arg.CollectionPassword,
 		arg.CollectionSharedBy,
 		arg.CollectionShareExpiry,
-		arg.CollectionAccessLevel,
 	)
 	var i PublicSharedCollection
 	err := row.Scan(
+		&i.ShareToken,
 		&i.CollectionID,
 		&i.CollectionPassword,
 		&i.CollectionSharedBy,
 		&i.CollectionSharedAt,
 		&i.CollectionShareExpiry,
-		&i.CollectionAccessLevel,
 	)
 	return i, err
 }
+
+const transferPublicLinks = `-- name: TransferPublicLinks :exec
+UPDATE public_shared_collection SET collection_shared_by = $1
+WHERE collection_shared_by = $2 AND collection_id IN (
+  SELECT folder.folder_id FROM folder WHERE folder.path <@ (SELECT f.path FROM folder AS f WHERE f.folder_id = $3)
+)
+`
+
+type TransferPublicLinksParams struct {
+	NewOwnerID int64  `json:"new_owner_id"`
+	OldOwnerID int64  `json:"old_owner_id"`
+	FolderID   string `json:"folder_id"`
+}
+
+func (q *Queries) TransferPublicLinks(ctx context.Context, arg TransferPublicLinksParams) error {
+	_, err := q.db.ExecContext(ctx, transferPublicLinks, arg.NewOwnerID, arg.OldOwnerID, arg.FolderID)
+	return err
+}
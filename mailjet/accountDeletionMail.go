@@ -0,0 +1,58 @@
+package mailjet
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+	"github.com/mailjet/mailjet-apiv3-go/v4"
+)
+
+type accountDeletionMail struct {
+	Email       string
+	Name        string
+	Code        string
+	GracePeriod int
+}
+
+// NewAccountDeletionMail carries the code that confirms an account deletion, gracePeriod is in days
+func NewAccountDeletionMail(email, name, code string, gracePeriod int) *accountDeletionMail {
+	return &accountDeletionMail{
+		Email:       email,
+		Name:        name,
+		Code:        code,
+		GracePeriod: gracePeriod,
+	}
+}
+
+func (a accountDeletionMail) SendAccountDeletionMail() {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		panic(err)
+	}
+
+	client := mailjet.NewMailjetClient(config.MailJetApiKey, config.MailJetSecretKey)
+
+	messagesInfo := []mailjet.InfoMessagesV31{
+		{
+			From: &mailjet.RecipientV31{
+				Email: "accounts@linkspace.space",
+				Name:  "Linkspace",
+			},
+			To: &mailjet.RecipientsV31{
+				mailjet.RecipientV31{
+					Email: a.Email,
+					Name:  a.Name,
+				},
+			},
+			Subject:  "Confirm the deletion of your account",
+			HTMLPart: fmt.Sprintf(`<p>Hey %s</p><p>Your code to confirm the deletion of your Linkspace account is <b>%s</b>.</p><p>Once confirmed, your account and everything in it is deleted after %d days. You can cancel the deletion until then.</p><p>If this was not you, sign in and change your password straight away.</p><p>Regards,</P><p><a href="beta.linkspace.space">Linkspace</a> Team.</p>`, a.Name, a.Code, a.GracePeriod),
+		},
+	}
+	messages := mailjet.MessagesV31{Info: messagesInfo}
+
+	_, err = client.SendMailV31(&messages)
+	if err != nil {
+		log.Panicf("could not send account deletion email: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package mailjet
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+	"github.com/mailjet/mailjet-apiv3-go/v4"
+)
+
+type emailChangeNotice struct {
+	OldEmail string
+	NewEmail string
+	Name     string
+}
+
+// NewEmailChangeNotice tells the address an account is moving away from, so the owner notices a change they did not ask for
+func NewEmailChangeNotice(oldEmail, newEmail, name string) *emailChangeNotice {
+	return &emailChangeNotice{
+		OldEmail: oldEmail,
+		NewEmail: newEmail,
+		Name:     name,
+	}
+}
+
+func (e emailChangeNotice) SendEmailChangeNotice() {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		panic(err)
+	}
+
+	client := mailjet.NewMailjetClient(config.MailJetApiKey, config.MailJetSecretKey)
+
+	messagesInfo := []mailjet.InfoMessagesV31{
+		{
+			From: &mailjet.RecipientV31{
+				Email: "accounts@linkspace.space",
+				Name:  "Linkspace",
+			},
+			To: &mailjet.RecipientsV31{
+				mailjet.RecipientV31{
+					Email: e.OldEmail,
+					Name:  e.Name,
+				},
+			},
+			Subject:  "Your email address is being changed",
+			HTMLPart: fmt.Sprintf(`<p>Hey %s</p><p>Someone asked to change the email address of your Linkspace account to %s. The change happens once the code we sent there is confirmed.</p><p>If this was not you, sign in and change your password straight away.</p><p>Regards,</P><p><a href="beta.linkspace.space">Linkspace</a> Team.</p>`, e.Name, e.NewEmail),
+		},
+	}
+	messages := mailjet.MessagesV31{Info: messagesInfo}
+
+	_, err = client.SendMailV31(&messages)
+	if err != nil {
+		log.Panicf("could not send email change notice: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package mailjet
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+	"github.com/mailjet/mailjet-apiv3-go/v4"
+)
+
+type removedFromCollection struct {
+	EmailRemoved     string
+	NameOfRemover    string
+	EmailOfRemover   string
+	NameOfCollection string
+}
+
+func NewRemovedFromCollection(emailRemoved, nameOfRemover, emailOfRemover, nameOfCollection string) *removedFromCollection {
+	return &removedFromCollection{
+		EmailRemoved:     emailRemoved,
+		NameOfRemover:    nameOfRemover,
+		EmailOfRemover:   emailOfRemover,
+		NameOfCollection: nameOfCollection,
+	}
+}
+
+func (r removedFromCollection) SendRemovedFromCollectionMail() {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		panic(err)
+	}
+
+	client := mailjet.NewMailjetClient(config.MailJetApiKey, config.MailJetSecretKey)
+
+	messagesInfo := []mailjet.InfoMessagesV31{
+		{
+			From: &mailjet.RecipientV31{
+				Email: "accounts@linkspace.space",
+				Name:  "Linkspace",
+			},
+			To: &mailjet.RecipientsV31{
+				mailjet.RecipientV31{
+					Email: r.EmailRemoved,
+					Name:  strings.Split(r.EmailRemoved, "@")[0],
+				},
+			},
+			Subject:  fmt.Sprintf(`You no longer have access to %s`, r.NameOfCollection),
+			HTMLPart: fmt.Sprintf(`<p>Hey %s</p><p><span style="text-transform: capitalize;">%s</span>(%s) has removed you from the links collection (%s). You can no longer view or edit it.</p><p>Regards,</P><p><a href="beta.linkspace.space">Linkspace</a> Team.</p>`, strings.Split(r.EmailRemoved, "@")[0], r.NameOfRemover, r.EmailOfRemover, r.NameOfCollection),
+		},
+	}
+	messages := mailjet.MessagesV31{Info: messagesInfo}
+
+	_, err = client.SendMailV31(&messages)
+	if err != nil {
+		log.Panicf("could not send removed from collection email: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// RequireAdmin lets through signed in sessions of admin accounts. The role is read from the database on every request
+// so taking it away works straight away, and personal access tokens are never good enough.
+func RequireAdmin(db *sql.DB) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			payload := r.Context().Value("payload").(*auth.PayLoad)
+
+			if payload.TokenID != 0 {
+				util.Response(w, "personal access tokens cannot be used here", http.StatusForbidden)
+				return
+			}
+
+			account, err := sqlc.New(db).GetAccount(r.Context(), payload.AccountID)
+			if err != nil {
+				log.Printf("could not get account at admin.go: %v", err)
+				util.Response(w, "something went wrong", http.StatusInternalServerError)
+				return
+			}
+
+			if !account.IsAdmin || account.SuspendedAt.Valid {
+				log.Printf("account %d is not an admin", payload.AccountID)
+				util.Response(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
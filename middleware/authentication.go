@@ -2,13 +2,14 @@ package middleware
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/kwandapchumba/go-bookmark-manager/auth"
-	"github.com/kwandapchumba/go-bookmark-manager/db"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
@@ -16,10 +17,14 @@ import (
 Middleware performs some specific function on the HTTP request or response at a specific stage in the HTTP pipeline before or after the user defined controller. Middleware is a design pattern to eloquently add cross cutting concerns like logging, handling authentication without having many code contact points.
 */
 
-func AuthenticateRequest() func(next http.Handler) http.Handler {
+// AuthenticateRequest accepts access tokens of a signed in session and personal access tokens. Routes personal access
+// tokens may use have to say so with RequireScope, everything else is kept from them by RequireSession.
+func AuthenticateRequest(db *sql.DB) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
-			payload, err := getAndVerifyToken(r)
+			q := sqlc.New(db)
+
+			payload, err := getAndVerifyToken(r, q)
 			if err != nil {
 				log.Println(err)
 				util.Response(w, err.Error(), http.StatusUnauthorized)
@@ -27,19 +32,21 @@ func AuthenticateRequest() func(next http.Handler) http.Handler {
 			}
 
 			if payload != nil {
-
-				account, err := db.ReturnAccount(r.Context(), payload.AccountID)
-				if err != nil {
-					log.Println(err)
-					util.Response(w, errors.New("unauthorized").Error(), http.StatusUnauthorized)
-					return
-				}
-
-				if payload.IssuedAt.Unix() != account.LastLogin.Unix() {
-					err := errors.New("invalid token")
-					log.Println(err)
-					util.Response(w, err.Error(), http.StatusUnauthorized)
-					return
+				// the token is only good for as long as the session it was issued for
+				if payload.TokenID == 0 {
+					_, active, err := auth.ActiveSession(r.Context(), q, payload)
+					if err != nil {
+						log.Println(err)
+						util.Response(w, errors.New("unauthorized").Error(), http.StatusUnauthorized)
+						return
+					}
+
+					if !active {
+						err := errors.New("invalid token")
+						log.Println(err)
+						util.Response(w, err.Error(), http.StatusUnauthorized)
+						return
+					}
 				}
 
 				ctx := context.WithValue(r.Context(), "payload", payload)
@@ -52,7 +59,7 @@ func AuthenticateRequest() func(next http.Handler) http.Handler {
 	}
 }
 
-func getAndVerifyToken(r *http.Request) (*auth.PayLoad, error) {
+func getAndVerifyToken(r *http.Request, q *sqlc.Queries) (*auth.PayLoad, error) {
 	token := r.Header.Get("authorization")
 
 	if token == "" {
@@ -70,7 +77,11 @@ func getAndVerifyToken(r *http.Request) (*auth.PayLoad, error) {
 
 	token = splitToken[1]
 
-	payload, err := auth.VerifyToken(token)
+	if t := strings.TrimSpace(token); auth.IsPersonalAccessToken(t) {
+		return auth.VerifyPersonalAccessToken(r.Context(), q, t)
+	}
+
+	payload, err := auth.VerifyToken(token, auth.AccessToken)
 	if err != nil {
 		return nil, err
 	}
@@ -1,3 +1,107 @@
 package middleware
 
+import (
+	"context"
+	"strings"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
 // user must own the folder, or have edit access to folder, in order to add, delete, rename, share folder etc
+
+// Role is what a user may do with a folder or link, every role can do everything the roles before it can
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleView
+	RoleEdit
+	RoleAdmin
+	RoleOwner
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleView:
+		return "view"
+	case RoleEdit:
+		return "edit"
+	case RoleAdmin:
+		return "admin"
+	case RoleOwner:
+		return "owner"
+	default:
+		return "none"
+	}
+}
+
+func roleFromAccessLevel(level sqlc.CollectionAccessLevel) Role {
+	switch level {
+	case sqlc.CollectionAccessLevelView:
+		return RoleView
+	case sqlc.CollectionAccessLevelEdit:
+		return RoleEdit
+	case sqlc.CollectionAccessLevelAdmin:
+		return RoleAdmin
+	default:
+		return RoleNone
+	}
+}
+
+// FolderRole resolves a user's effective role on a folder. Whoever owns the top level folder owns everything below it,
+// anyone else gets the highest access level they were given on the folder or any of its ancestors.
+func FolderRole(ctx context.Context, q *sqlc.Queries, folder sqlc.Folder, accountID int64) (Role, error) {
+	ancestors, err := q.GetFolderAncestors(ctx, folder.Label)
+	if err != nil {
+		return RoleNone, err
+	}
+
+	// ancestors are ordered by path so the top level folder comes first
+	if len(ancestors) > 0 && ancestors[0].AccountID == accountID {
+		return RoleOwner, nil
+	}
+
+	levels, err := q.GetAccessLevelsOnFolderAndAncestors(ctx, sqlc.GetAccessLevelsOnFolderAndAncestorsParams{
+		MemberID: accountID,
+		FolderID: folder.FolderID,
+	})
+	if err != nil {
+		return RoleNone, err
+	}
+
+	role := RoleNone
+
+	for _, level := range levels {
+		if r := roleFromAccessLevel(level); r > role {
+			role = r
+		}
+	}
+
+	return role, nil
+}
+
+// LinkRole is the role on the folder a link is in, links at the root only belong to the account that added them
+func LinkRole(ctx context.Context, q *sqlc.Queries, link sqlc.Link, accountID int64) (Role, error) {
+	if !link.FolderID.Valid {
+		if link.AccountID == accountID {
+			return RoleOwner, nil
+		}
+
+		return RoleNone, nil
+	}
+
+	folder, err := q.GetFolder(ctx, link.FolderID.String)
+	if err != nil {
+		return RoleNone, err
+	}
+
+	return FolderRole(ctx, q, folder, accountID)
+}
+
+// SameCollection reports whether two folders are below the same top level folder, whose owner owns them both
+func SameCollection(a, b sqlc.Folder) bool {
+	rootA, _, _ := strings.Cut(a.Path, ".")
+	rootB, _, _ := strings.Cut(b.Path, ".")
+
+	return rootA == rootB
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/dbtest"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
+const (
+	owner int64 = iota + 1
+	editor
+	viewer
+	subfolderAdmin
+	mixed
+	stranger
+)
+
+// collections is a collection owned by owner with a subfolder and a subfolder of that, and a second collection
+var collections = dbtest.Collections{
+	Folders: []sqlc.Folder{
+		{FolderID: "root", AccountID: owner, Path: "root", Label: "root"},
+		// made by editor, which does not make them its owner
+		{FolderID: "child", AccountID: editor, Path: "root.child", Label: "child", SubfolderOf: sql.NullString{String: "root", Valid: true}},
+		{FolderID: "grandchild", AccountID: owner, Path: "root.child.grandchild", Label: "grandchild", SubfolderOf: sql.NullString{String: "child", Valid: true}},
+		{FolderID: "other", AccountID: stranger, Path: "other", Label: "other"},
+	},
+	Links: []sqlc.Link{
+		{LinkID: "root-link", AccountID: owner},
+		{LinkID: "grandchild-link", AccountID: editor, FolderID: sql.NullString{String: "grandchild", Valid: true}},
+	},
+	Members: []sqlc.CollectionMember{
+		{CollectionID: "root", MemberID: editor, CollectionAccessLevel: sqlc.CollectionAccessLevelEdit},
+		{CollectionID: "child", MemberID: viewer, CollectionAccessLevel: sqlc.CollectionAccessLevelView},
+		{CollectionID: "grandchild", MemberID: subfolderAdmin, CollectionAccessLevel: sqlc.CollectionAccessLevelAdmin},
+		{CollectionID: "root", MemberID: mixed, CollectionAccessLevel: sqlc.CollectionAccessLevelView},
+		{CollectionID: "child", MemberID: mixed, CollectionAccessLevel: sqlc.CollectionAccessLevelEdit},
+	},
+}
+
+func TestFolderRole(t *testing.T) {
+	testFolderRole(t, sqlc.New(dbtest.Open(collections.Queries())))
+}
+
+// TestFolderRolePostgres runs the ltree queries roles are inherited with
+func TestFolderRolePostgres(t *testing.T) {
+	testFolderRole(t, sqlc.New(collections.Postgres(t)))
+}
+
+func testFolderRole(t *testing.T, q *sqlc.Queries) {
+	tests := []struct {
+		name      string
+		folderID  string
+		accountID int64
+		want      Role
+	}{
+		{"owner of the collection", "root", owner, RoleOwner},
+		{"owner of the collection on a subfolder", "grandchild", owner, RoleOwner},
+		{"editor of the collection", "root", editor, RoleEdit},
+		{"editor inherits down", "grandchild", editor, RoleEdit},
+		{"making a subfolder does not make its owner", "child", editor, RoleEdit},
+		{"viewer of a subfolder", "child", viewer, RoleView},
+		{"viewer inherits down", "grandchild", viewer, RoleView},
+		{"viewer of a subfolder has nothing on its parent", "root", viewer, RoleNone},
+		{"admin of the deepest subfolder", "grandchild", subfolderAdmin, RoleAdmin},
+		{"admin of the deepest subfolder has nothing above it", "child", subfolderAdmin, RoleNone},
+		{"highest access level wins", "grandchild", mixed, RoleEdit},
+		{"lower access level on the ancestor", "root", mixed, RoleView},
+		{"stranger", "root", stranger, RoleNone},
+		{"owner of another collection", "other", owner, RoleNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder, err := q.GetFolder(context.Background(), tt.folderID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := FolderRole(context.Background(), q, folder, tt.accountID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("FolderRole(%s, %d) = %v, want %v", tt.folderID, tt.accountID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkRole(t *testing.T) {
+	testLinkRole(t, sqlc.New(dbtest.Open(collections.Queries())))
+}
+
+func TestLinkRolePostgres(t *testing.T) {
+	testLinkRole(t, sqlc.New(collections.Postgres(t)))
+}
+
+func testLinkRole(t *testing.T, q *sqlc.Queries) {
+	tests := []struct {
+		name      string
+		linkID    string
+		accountID int64
+		want      Role
+	}{
+		{"root link of its account", "root-link", owner, RoleOwner},
+		{"root link of another account", "root-link", editor, RoleNone},
+		{"owner of the collection the link is in", "grandchild-link", owner, RoleOwner},
+		{"adding a link does not make its owner", "grandchild-link", editor, RoleEdit},
+		{"viewer of an ancestor of the folder", "grandchild-link", viewer, RoleView},
+		{"admin of the folder", "grandchild-link", subfolderAdmin, RoleAdmin},
+		{"stranger", "grandchild-link", stranger, RoleNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, err := q.GetLink(context.Background(), tt.linkID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LinkRole(context.Background(), q, link, tt.accountID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("LinkRole(%s, %d) = %v, want %v", tt.linkID, tt.accountID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameCollection(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"root", "root", true},
+		{"root", "root.child", true},
+		{"root.child", "root.other.grandchild", true},
+		{"root", "other", false},
+		{"root.child", "rootish.child", false},
+	}
+
+	for _, tt := range tests {
+		got := SameCollection(sqlc.Folder{Path: tt.a}, sqlc.Folder{Path: tt.b})
+		if got != tt.want {
+			t.Errorf("SameCollection(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
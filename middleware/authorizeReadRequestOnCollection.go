@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/kwandapchumba/go-bookmark-manager/auth"
 	"github.com/kwandapchumba/go-bookmark-manager/db"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
@@ -29,7 +30,7 @@ func newReadRequestOnCollectionDetails(folderid string, accountid int64, payload
 	}
 }
 
-func AuthorizeReadRequestOnCollection() func(next http.Handler) http.Handler {
+func AuthorizeReadRequestOnCollection(conn *sql.DB) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			payload := r.Context().Value("payload").(*auth.PayLoad)
@@ -86,90 +87,21 @@ func AuthorizeReadRequestOnCollection() func(next http.Handler) http.Handler {
 				return
 			}
 
-			// check if collection belongs to user
-			if collection.AccountID == payload.AccountID {
-				// collection belongs to user hence user is allowed to read from it hence no further check hence pass request details to context
-				body := newReadRequestOnCollectionDetails(folderID, int64(account_id), *payload)
-
-				ctx := context.WithValue(r.Context(), "readRequestOnCollectionDetails", body)
-
-				next.ServeHTTP(w, r.WithContext(ctx))
-
+			// owners and members of the collection or any of its ancestors may read from it
+			role, err := FolderRole(r.Context(), sqlc.New(conn), *collection, payload.AccountID)
+			if err != nil {
+				log.Printf("could not resolve role on collection at authorizeReadRequestOnCollection.go: %v", err)
+				util.Response(w, "something went wrong", http.StatusInternalServerError)
 				return
 			}
 
-			// user does not own folder hence check if folder has been shared with them
-			collectionMember, err := db.ReturnCollectionMemberByCollectionAndMemberIDs(r.Context(), collection.FolderID, payload.AccountID)
-			if err != nil {
-				if errors.Is(err, sql.ErrNoRows) {
-
-					// this specific folder has not been shared with them
-					// check if any of it's ancestors has been shared with used
-					ancestorsOfFolder, err := db.ReturnAncestorsOfFolder(r.Context(), collection.FolderID)
-					if err != nil {
-						if errors.Is(err, sql.ErrNoRows) {
-							log.Printf("authorizeReadRequestOnCollection: found no ancestors of folder: %v", err)
-							util.Response(w, "no ancestors of folder found", http.StatusNoContent)
-							return
-						}
-
-						var pgErr *pgconn.PgError
-
-						if errors.As(err, &pgErr) {
-							log.Printf("authorizeReadRequestOnCollection: could not get ancestors of folder: pgErr: %v", pgErr)
-							util.Response(w, "something went wrong", http.StatusInternalServerError)
-							return
-						}
-
-						log.Printf("authorizeReadRequestOnCollection: could not get ancestors of folder: err: %v", err)
-						util.Response(w, "something went wrong", http.StatusInternalServerError)
-						return
-					}
-
-					for _, ancestorOfFolder := range ancestorsOfFolder {
-						val, err := db.CheckIfCollectionMemberExists(r.Context(), ancestorOfFolder.FolderID, payload.AccountID)
-						if err != nil {
-							var pgErr *pgconn.PgError
-
-							if errors.As(err, &pgErr) {
-								log.Printf("authorizeReadRequestOnCollecton.go: could not check of collection member exists: %v", pgErr)
-								util.Response(w, "something went wrong", http.StatusInternalServerError)
-								return
-							}
-							log.Printf("authorizeReadRequestOnCollecton.go: could not check of collection member exists: %v", err)
-							util.Response(w, "something went wrong", http.StatusInternalServerError)
-							return
-						}
-
-						if val {
-							body := newReadRequestOnCollectionDetails(collection.FolderID, int64(account_id), *payload)
-
-							ctx := context.WithValue(r.Context(), "readRequestOnCollectionDetails", body)
-
-							next.ServeHTTP(w, r.WithContext(ctx))
-
-							return
-						}
-					}
-
-					return
-				}
-
-				var pgErr *pgconn.PgError
-
-				if errors.As(err, &pgErr) {
-					log.Printf("could not get collection member in authorizeReadRequestOnCollection.go... pgErr: %v", pgErr)
-					util.Response(w, "something went wrong", http.StatusInternalServerError)
-					return
-				}
-
-				log.Printf("could not get collection member in authorizeReadRequestOnCollection.go... err: %v", err)
-				util.Response(w, "something went wrong", http.StatusInternalServerError)
+			if role < RoleView {
+				log.Println("collection has not been shared with user")
+				util.Response(w, "collection has not been shared with you", http.StatusUnauthorized)
 				return
 			}
 
-			// collection has been shared with user hence pass request details to context
-			body := newReadRequestOnCollectionDetails(collectionMember.CollectionID, int64(account_id), *payload)
+			body := newReadRequestOnCollectionDetails(folderID, int64(account_id), *payload)
 
 			ctx := context.WithValue(r.Context(), "readRequestOnCollectionDetails", body)
 
@@ -13,6 +13,7 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/kwandapchumba/go-bookmark-manager/auth"
 	"github.com/kwandapchumba/go-bookmark-manager/db"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
@@ -39,7 +40,7 @@ func newCreateFolderRequestBody(p auth.PayLoad, b createFolderRequest) *CreateFo
 	}
 }
 
-func AuthorizeCreateFolderRequest() func(next http.Handler) http.Handler {
+func AuthorizeCreateFolderRequest(conn *sql.DB) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			// get request body/content
@@ -105,54 +106,31 @@ func AuthorizeCreateFolderRequest() func(next http.Handler) http.Handler {
 				return
 			}
 
-			// check if user owns parent folder
-			if folder.AccountID == payload.AccountID {
-				rB := newCreateFolderRequestBody(*payload, req)
-
-				ctx := context.WithValue(r.Context(), "createFolderRequest", rB)
-
-				next.ServeHTTP(w, r.WithContext(ctx))
-
+			// owners, editors and admins of the parent folder or one of its ancestors may create folders in it
+			role, err := FolderRole(r.Context(), sqlc.New(conn), *folder, payload.AccountID)
+			if err != nil {
+				log.Printf("could not resolve role on folder in createFolderAuthorization.go: %v", err)
+				util.Response(w, "something went wrong", http.StatusInternalServerError)
 				return
 			}
 
-			// check if folder has been shared with user
-			collectionMember, err := db.ReturnCollectionMemberByCollectionAndMemberIDs(r.Context(), folder.FolderID, payload.AccountID)
-			if err != nil {
-				if errors.Is(err, sql.ErrNoRows) {
-					log.Printf("could not find collection member instance in createFolderAuthorization.go: %v", err)
-					util.Response(w, "collection has not been shared with you", http.StatusUnauthorized)
-					return
-				}
-
-				var pgErr *pgconn.PgError
-
-				if errors.As(err, &pgErr) {
-					log.Printf("could not get collection member in createFolderAuthorization.go... pgErr: %v", pgErr)
-					util.Response(w, "something went wrong", http.StatusInternalServerError)
-					return
-				}
-
-				log.Printf("could not get collection member in createFolderAuthorization.go... err: %v", err)
-				util.Response(w, "something went wrong", http.StatusInternalServerError)
+			if role == RoleNone {
+				log.Println("collection has not been shared with user")
+				util.Response(w, "collection has not been shared with you", http.StatusUnauthorized)
 				return
 			}
 
-			// check if user has admin rights
-			if collectionMember.CollectionAccessLevel != "admin" {
-				log.Printf(`user is not allowed to create edit this collection: user access level is not "admin" but "%v"`, collectionMember.CollectionAccessLevel)
+			if role < RoleEdit {
+				log.Printf(`user is not allowed to edit this collection: user role is "%v"`, role)
 				util.Response(w, "access denied due to insufficient access level", http.StatusUnauthorized)
 				return
 			}
 
-			// user has admin rights hence allowed to edit this collection
 			rB := newCreateFolderRequestBody(*payload, req)
 
-			ctx := context.WithValue(r.Context(), "myValues", rB)
+			ctx := context.WithValue(r.Context(), "createFolderRequest", rB)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
-
-			return
 		}
 
 		return http.HandlerFunc(fn)
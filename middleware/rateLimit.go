@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
+)
+
+// bodies of the routes that are rate limited are small json objects, nothing bigger is read to find the email
+const maxRateLimitedBody = 1 << 20
+
+// RateLimit throttles a route per client IP and, when the request body has an email, per email address. Emails that
+// are locked out after too many failed sign ins are turned away as well. A perEmail limit with no burst only limits
+// by IP. When the limiter's store fails requests are let through rather than locking everyone out.
+func RateLimit(limiter *ratelimit.Limiter, route string, perIP, perEmail ratelimit.Limit) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			wait, err := limiter.Allow(r.Context(), route+":ip:"+auth.ClientIP(r), perIP)
+			if err != nil {
+				log.Printf("could not rate limit request at rateLimit.go: %v", err)
+			}
+
+			if wait == 0 && perEmail.Burst > 0 {
+				if email := requestEmail(r); email != "" {
+					wait, err = limiter.Locked(r.Context(), ratelimit.EmailKey(email))
+					if err != nil {
+						log.Printf("could not check lockout at rateLimit.go: %v", err)
+					}
+
+					if wait == 0 {
+						wait, err = limiter.Allow(r.Context(), route+":"+ratelimit.EmailKey(email), perEmail)
+						if err != nil {
+							log.Printf("could not rate limit request at rateLimit.go: %v", err)
+						}
+					}
+				}
+			}
+
+			if wait > 0 {
+				ratelimit.TooManyRequests(w, wait)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// requestEmail reads the email out of a json body and puts the body back for the handler
+func requestEmail(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRateLimitedBody))
+	if err != nil {
+		return ""
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+
+	return req.Email
+}
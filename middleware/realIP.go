@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// RealIP replaces the remote address of requests that came through one of the proxies in trustedProxies, a comma
+// separated list of CIDRs, with the client address the proxy forwarded. Anyone can send X-Forwarded-For and X-Real-IP,
+// so requests from anywhere else keep the address they came from and cannot dodge rate limits by making one up.
+func RealIP() func(next http.Handler) http.Handler {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Panicf("could not load config file: %v", err)
+	}
+
+	proxies, err := parseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		log.Panicf("invalid trusted proxies: %v", err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			r.RemoteAddr = clientAddr(r, proxies)
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func parseTrustedProxies(s string) ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+
+	for _, cidr := range strings.Split(s, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		_, proxy, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+
+		proxies = append(proxies, proxy)
+	}
+
+	return proxies, nil
+}
+
+func trusted(proxies []*net.IPNet, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientAddr is the remote address of r, or the address a trusted proxy forwarded it for. Proxies append to
+// X-Forwarded-For, so the client is the last address that was not added by one of ours.
+func clientAddr(r *http.Request, proxies []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !trusted(proxies, host) {
+		return r.RemoteAddr
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		addrs := strings.Split(forwarded, ",")
+
+		for i := len(addrs) - 1; i >= 0; i-- {
+			addr := strings.TrimSpace(addrs[i])
+
+			if net.ParseIP(addr) == nil {
+				break
+			}
+
+			if !trusted(proxies, addr) || i == 0 {
+				return addr
+			}
+		}
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+
+	return r.RemoteAddr
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientAddr(t *testing.T) {
+	proxies, err := parseTrustedProxies("10.0.0.0/8, 192.168.1.1/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"client straight to the api", "203.0.113.7:5000", "", "", "203.0.113.7:5000"},
+		{"client making up headers", "203.0.113.7:5000", "198.51.100.1", "198.51.100.2", "203.0.113.7:5000"},
+		{"through a proxy", "10.0.0.2:5000", "203.0.113.7", "", "203.0.113.7"},
+		{"through two proxies", "10.0.0.2:5000", "203.0.113.7, 192.168.1.1", "", "203.0.113.7"},
+		{"client prepending to the forwarded header", "10.0.0.2:5000", "198.51.100.1, 203.0.113.7", "", "203.0.113.7"},
+		{"only proxies forwarded", "10.0.0.2:5000", "10.0.0.3", "", "10.0.0.3"},
+		{"real ip header from a proxy", "192.168.1.1:5000", "", "203.0.113.7", "203.0.113.7"},
+		{"garbage from a proxy", "10.0.0.2:5000", "not an ip", "", "10.0.0.2:5000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := clientAddr(r, proxies); got != tt.want {
+				t.Errorf("clientAddr() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Error("parseTrustedProxies accepted an invalid cidr")
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// RequireScope lets personal access tokens through when they have a scope for every resource a route touches, the
+// read scope for GET requests and the write scope for anything else. Signed in sessions always get through.
+func RequireScope(resources ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			payload := r.Context().Value("payload").(*auth.PayLoad)
+
+			access := "write"
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				access = "read"
+			}
+
+			for _, resource := range resources {
+				scope := resource + ":" + access
+
+				if !payload.HasScope(scope) {
+					log.Printf("personal access token %d is missing scope %s", payload.TokenID, scope)
+					util.Response(w, fmt.Sprintf("token is missing the %s scope", scope), http.StatusForbidden)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RequireSession keeps personal access tokens away from routes no scope covers, like account settings and managing
+// the tokens themselves
+func RequireSession() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			payload := r.Context().Value("payload").(*auth.PayLoad)
+
+			if payload.TokenID != 0 {
+				util.Response(w, "personal access tokens cannot be used here", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/jackc/pgconn"
 	"github.com/kwandapchumba/go-bookmark-manager/auth"
 	"github.com/kwandapchumba/go-bookmark-manager/db"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
 	"github.com/kwandapchumba/go-bookmark-manager/util"
 )
 
@@ -45,7 +46,7 @@ func newShareCollectionRequestBody(p auth.PayLoad, b shareCollectionRequest, col
 	}
 }
 
-func AuthorizeShareCollectionRequest() func(next http.Handler) http.Handler {
+func AuthorizeShareCollectionRequest(conn *sql.DB) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			body := json.NewDecoder(r.Body)
@@ -97,42 +98,22 @@ func AuthorizeShareCollectionRequest() func(next http.Handler) http.Handler {
 
 			payload := r.Context().Value("payload").(*auth.PayLoad)
 
-			if folder.AccountID == payload.AccountID {
-
-				rB := newShareCollectionRequestBody(*payload, req, folder.FolderName)
-
-				ctx := context.WithValue(r.Context(), "shareCollectionRequest", rB)
-
-				next.ServeHTTP(w, r.WithContext(ctx))
-
+			// only owners and admins of the collection or one of its parents may invite people to it
+			role, err := FolderRole(r.Context(), sqlc.New(conn), *folder, payload.AccountID)
+			if err != nil {
+				log.Printf("could not resolve role on collection in shareCollectionAuthorization.go: %v", err)
+				util.Response(w, "something went wrong", http.StatusInternalServerError)
 				return
 			}
 
-			// check if folder has been shared with user
-			collectionMember, err := db.ReturnCollectionMemberByCollectionAndMemberIDs(r.Context(), folder.FolderID, payload.AccountID)
-			if err != nil {
-				if errors.Is(err, sql.ErrNoRows) {
-					log.Printf("could not find collection member instance in createFolderAuthorization.go: %v", err)
-					util.Response(w, "collection has not been shared with you", http.StatusUnauthorized)
-					return
-				}
-
-				var pgErr *pgconn.PgError
-
-				if errors.As(err, &pgErr) {
-					log.Printf("could not get collection member in createFolderAuthorization.go... pgErr: %v", pgErr)
-					util.Response(w, "something went wrong", http.StatusInternalServerError)
-					return
-				}
-
-				log.Printf("could not get collection member in createFolderAuthorization.go... err: %v", err)
-				util.Response(w, "something went wrong", http.StatusInternalServerError)
+			if role == RoleNone {
+				log.Println("collection has not been shared with user")
+				util.Response(w, "collection has not been shared with you", http.StatusUnauthorized)
 				return
 			}
 
-			// check if user has admin rights
-			if collectionMember.CollectionAccessLevel != "admin" {
-				log.Printf(`user is not allowed to create edit this collection: user access level is not "admin" but "%v"`, collectionMember.CollectionAccessLevel)
+			if role < RoleAdmin {
+				log.Printf(`user is not allowed to share this collection: user role is not "admin" but "%v"`, role)
 				util.Response(w, "access denied due to insufficient access level", http.StatusUnauthorized)
 				return
 			}
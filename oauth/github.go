@@ -0,0 +1,84 @@
+package oauth
+
+import (
+	"context"
+	"strconv"
+)
+
+const (
+	gitHubAuthorizeURL = "https://github.com/login/oauth/authorize"
+	gitHubTokenURL     = "https://github.com/login/oauth/access_token"
+	gitHubUserURL      = "https://api.github.com/user"
+	gitHubEmailsURL    = "https://api.github.com/user/emails"
+)
+
+// gitHubProvider signs in with github's oauth apps, github does not speak OpenID Connect so there is no id token and
+// the user is looked up with the access token instead
+type gitHubProvider struct {
+	clientID     string
+	clientSecret string
+}
+
+func newGitHubProvider(clientID, clientSecret string) *gitHubProvider {
+	return &gitHubProvider{clientID: clientID, clientSecret: clientSecret}
+}
+
+func (g *gitHubProvider) Name() string {
+	return "github"
+}
+
+func (g *gitHubProvider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	return authCodeURL(gitHubAuthorizeURL, g.clientID, []string{"read:user", "user:email"}, req, false)
+}
+
+type gitHubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type gitHubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func (g *gitHubProvider) Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error) {
+	token, err := exchangeCode(ctx, gitHubTokenURL, g.clientID, g.clientSecret, code, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var user gitHubUser
+
+	if err := getJSON(ctx, gitHubUserURL, token.AccessToken, &user); err != nil {
+		return nil, err
+	}
+
+	var emails []gitHubEmail
+
+	if err := getJSON(ctx, gitHubEmailsURL, token.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: g.Name(),
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Name,
+		Picture:  user.AvatarURL,
+	}
+
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,221 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+var ErrExchangeFailed = errors.New("could not sign in with provider")
+
+// Identity is who a provider says the signed in user is. Subject never changes for a user of a provider, everything
+// else can.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// AuthRequest is what one sign in attempt sends to a provider and has to present again when exchanging the code
+type AuthRequest struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+	RedirectURI  string
+}
+
+// Provider signs users in with the authorization code flow and PKCE
+type Provider interface {
+	Name() string
+	// AuthCodeURL is where the user is sent to sign in
+	AuthCodeURL(ctx context.Context, req AuthRequest) (string, error)
+	// Exchange swaps the code the provider sent back to the callback for the signed in user's identity
+	Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error)
+}
+
+// Registry holds the providers that have credentials configured
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry() *Registry {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Panicf("could not load config file: %v", err)
+	}
+
+	r := &Registry{providers: make(map[string]Provider)}
+
+	if config.GoogleClientID != "" && config.GoogleClientSecret != "" {
+		r.register(newOIDCProvider("google", "https://accounts.google.com", config.GoogleClientID, config.GoogleClientSecret, "accounts.google.com"))
+	}
+
+	if config.KeycloakIssuerURL != "" && config.KeycloakClientID != "" {
+		r.register(newOIDCProvider("keycloak", strings.TrimSuffix(config.KeycloakIssuerURL, "/"), config.KeycloakClientID, config.KeycloakClientSecret))
+	}
+
+	if config.GitHubClientID != "" && config.GitHubClientSecret != "" {
+		r.register(newGitHubProvider(config.GitHubClientID, config.GitHubClientSecret))
+	}
+
+	return r
+}
+
+func (r *Registry) register(p Provider) {
+	r.providers[p.Name()] = p
+}
+
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+func (r *Registry) Names() []string {
+	names := []string{}
+
+	for name := range r.providers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// RandomToken is used for states, nonces and PKCE code verifiers
+func RandomToken() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallenge is the S256 PKCE challenge for a code verifier
+func codeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func authCodeURL(endpoint, clientID string, scopes []string, req AuthRequest, nonce bool) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+
+	q.Set("response_type", "code")
+	q.Set("client_id", clientID)
+	q.Set("redirect_uri", req.RedirectURI)
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("state", req.State)
+	q.Set("code_challenge", codeChallenge(req.CodeVerifier))
+	q.Set("code_challenge_method", "S256")
+
+	if nonce {
+		q.Set("nonce", req.Nonce)
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+func exchangeCode(ctx context.Context, tokenURL, clientID, clientSecret, code string, req AuthRequest) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {req.RedirectURI},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"code_verifier": {req.CodeVerifier},
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+
+	if err := doJSON(r, &token); err != nil {
+		return nil, err
+	}
+
+	if token.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrExchangeFailed, token.Error, token.ErrorDescription)
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%w: no access token in response", ErrExchangeFailed)
+	}
+
+	return &token, nil
+}
+
+func getJSON(ctx context.Context, rawURL, accessToken string, v interface{}) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if accessToken != "" {
+		r.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	return doJSON(r, v)
+}
+
+func doJSON(r *http.Request, v interface{}) error {
+	resp, err := httpClient.Do(r)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	// token endpoints put oauth errors in 400 responses, let the caller read them
+	if resp.StatusCode >= 300 && !(resp.StatusCode == http.StatusBadRequest && json.Valid(b)) {
+		return fmt.Errorf("%w: %s responded with %d", ErrExchangeFailed, r.URL.Host, resp.StatusCode)
+	}
+
+	return json.Unmarshal(b, v)
+}
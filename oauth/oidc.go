@@ -0,0 +1,199 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+)
+
+const clockSkew = time.Minute
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcProvider works with any OpenID Connect provider that publishes a discovery document, e.g. google and keycloak
+type oidcProvider struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	// other values of iss the provider uses, google sometimes leaves out the scheme
+	altIssuers []string
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      *auth.KeySet
+}
+
+func newOIDCProvider(name, issuer, clientID, clientSecret string, altIssuers ...string) *oidcProvider {
+	return &oidcProvider{
+		name:         name,
+		issuer:       issuer,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		altIssuers:   altIssuers,
+	}
+}
+
+func (o *oidcProvider) Name() string {
+	return o.name
+}
+
+// discover fetches the provider's endpoints the first time they are needed, a failed fetch is retried next time
+func (o *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, *auth.KeySet, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.discovery != nil {
+		return o.discovery, o.keys, nil
+	}
+
+	var d oidcDiscovery
+
+	if err := getJSON(ctx, o.issuer+"/.well-known/openid-configuration", "", &d); err != nil {
+		return nil, nil, fmt.Errorf("could not discover %s endpoints: %w", o.name, err)
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("%s discovery document is missing endpoints", o.name)
+	}
+
+	o.discovery = &d
+	o.keys = auth.NewKeySet(d.JWKSURI)
+
+	return o.discovery, o.keys, nil
+}
+
+func (o *oidcProvider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	d, _, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return authCodeURL(d.AuthorizationEndpoint, o.clientID, []string{"openid", "email", "profile"}, req, true)
+}
+
+// audience is a single string or a list of them
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var many []string
+
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+
+	*a = many
+
+	return nil
+}
+
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+
+	return false
+}
+
+type idTokenClaims struct {
+	Issuer            string        `json:"iss"`
+	Audience          audience      `json:"aud"`
+	AuthorizedParty   string        `json:"azp"`
+	Subject           string        `json:"sub"`
+	Expiry            int64         `json:"exp"`
+	Nonce             string        `json:"nonce"`
+	Email             string        `json:"email"`
+	EmailVerified     auth.JSONBool `json:"email_verified"`
+	Name              string        `json:"name"`
+	PreferredUsername string        `json:"preferred_username"`
+	Picture           string        `json:"picture"`
+}
+
+func (o *oidcProvider) validIssuer(iss string) bool {
+	if iss == o.issuer {
+		return true
+	}
+
+	for _, alt := range o.altIssuers {
+		if iss == alt {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *oidcProvider) Exchange(ctx context.Context, code string, req AuthRequest) (*Identity, error) {
+	d, keys, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := exchangeCode(ctx, d.TokenEndpoint, o.clientID, o.clientSecret, code, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id token in response", ErrExchangeFailed)
+	}
+
+	var claims idTokenClaims
+
+	if err := auth.VerifyJWT(ctx, keys, token.IDToken, &claims); err != nil {
+		return nil, err
+	}
+
+	if !o.validIssuer(claims.Issuer) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", auth.ErrInvalidJWT, claims.Issuer)
+	}
+
+	if !claims.Audience.contains(o.clientID) || (len(claims.Audience) > 1 && claims.AuthorizedParty != o.clientID) {
+		return nil, fmt.Errorf("%w: token was issued for another app", auth.ErrInvalidJWT)
+	}
+
+	if !time.Now().Add(-clockSkew).Before(time.Unix(claims.Expiry, 0)) {
+		return nil, fmt.Errorf("%w: token is expired", auth.ErrInvalidJWT)
+	}
+
+	// the nonce ties the id token to the sign in that was started here
+	if claims.Nonce != req.Nonce {
+		return nil, fmt.Errorf("%w: nonce does not match", auth.ErrInvalidJWT)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", auth.ErrInvalidJWT)
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = claims.PreferredUsername
+	}
+
+	return &Identity{
+		Provider:      o.name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          name,
+		Picture:       claims.Picture,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// how often buckets that are full again are thrown away
+const sweepInterval = time.Minute
+
+// memoryStore keeps buckets in process, every instance of the api counts requests on its own
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*Bucket
+	lastSweep time.Time
+	// now is the clock buckets expire by, tests set their own
+	now func() time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{buckets: make(map[string]*Bucket), now: time.Now}
+}
+
+func (s *memoryStore) Update(ctx context.Context, key string, fn func(b *Bucket)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	if now.Sub(s.lastSweep) > sweepInterval {
+		for k, b := range s.buckets {
+			if now.After(b.ExpiresAt) {
+				delete(s.buckets, k)
+			}
+		}
+
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &Bucket{}
+		s.buckets[key] = b
+	}
+
+	fn(b)
+
+	return nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.buckets, key)
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
+// postgresStore shares buckets between every instance of the api
+type postgresStore struct {
+	db *sql.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func newPostgresStore(db *sql.DB) *postgresStore {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) sweep(ctx context.Context, q *sqlc.Queries) {
+	s.mu.Lock()
+	due := time.Since(s.lastSweep) > sweepInterval
+	if due {
+		s.lastSweep = time.Now()
+	}
+	s.mu.Unlock()
+
+	if !due {
+		return
+	}
+
+	if err := q.DeleteExpiredRateLimitBuckets(ctx); err != nil {
+		log.Printf("could not delete expired rate limit buckets at postgres.go: %v", err)
+	}
+}
+
+func (s *postgresStore) Update(ctx context.Context, key string, fn func(b *Bucket)) error {
+	q := sqlc.New(s.db)
+
+	s.sweep(ctx, q)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	qtx := q.WithTx(tx)
+
+	// the row has to exist before it can be locked, a new row is a full bucket
+	if err := qtx.EnsureRateLimitBucket(ctx, key); err != nil {
+		return err
+	}
+
+	row, err := qtx.GetRateLimitBucketForUpdate(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	b := Bucket{
+		Tokens:    row.Tokens,
+		UpdatedAt: row.UpdatedAt,
+		ExpiresAt: row.ExpiresAt,
+	}
+
+	fn(&b)
+
+	if err := qtx.UpdateRateLimitBucket(ctx, sqlc.UpdateRateLimitBucketParams{
+		Tokens:    b.Tokens,
+		UpdatedAt: b.UpdatedAt,
+		ExpiresAt: b.ExpiresAt,
+		BucketKey: key,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *postgresStore) Delete(ctx context.Context, key string) error {
+	return sqlc.New(s.db).DeleteRateLimitBucket(ctx, key)
+}
@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// failed sign ins are let through until an account has used up failureLimit, then it is locked for lockoutDuration
+var (
+	failureLimit    = Limit{Burst: 5, Every: 3 * time.Minute}
+	lockoutDuration = 15 * time.Minute
+)
+
+// Limit is a token bucket, Burst requests can be made at once and one more every Every after that
+type Limit struct {
+	Burst int
+	Every time.Duration
+}
+
+// refillDuration is how long an empty bucket takes to fill up again, after that it is no different from a new one
+func (l Limit) refillDuration() time.Duration {
+	return time.Duration(l.Burst) * l.Every
+}
+
+// Bucket is the state of one token bucket. A bucket that was never updated is full.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+	// the bucket is full again by then and can be thrown away
+	ExpiresAt time.Time
+}
+
+type Store interface {
+	// Update runs fn on the bucket stored under key and saves it, no other update of the same key runs in between
+	Update(ctx context.Context, key string, fn func(b *Bucket)) error
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore picks the store set by rateLimitStore ("memory" or "postgres"). Buckets in memory are per instance, so
+// deployments with more than one instance should keep them in postgres.
+func NewStore(db *sql.DB) Store {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Panicf("could not load config file: %v", err)
+	}
+
+	switch config.RateLimitStore {
+	case "", "memory":
+		return newMemoryStore()
+	case "postgres":
+		return newPostgresStore(db)
+	default:
+		log.Panicf("unknown rate limit store: %s", config.RateLimitStore)
+	}
+
+	return nil
+}
+
+type Limiter struct {
+	store Store
+	// now is the clock buckets are refilled by, tests set their own
+	now func() time.Time
+}
+
+func New(store Store) *Limiter {
+	return &Limiter{store: store, now: time.Now}
+}
+
+// take removes n tokens from the bucket under key, n of 0 only looks. It returns how long to wait until the request
+// would be allowed, 0 when it was, and the tokens left.
+func (l *Limiter) take(ctx context.Context, key string, limit Limit, n float64) (time.Duration, float64, error) {
+	var wait time.Duration
+	var left float64
+
+	err := l.store.Update(ctx, key, func(b *Bucket) {
+		now := l.now()
+
+		tokens := math.Min(float64(limit.Burst), b.Tokens+float64(now.Sub(b.UpdatedAt))/float64(limit.Every))
+		if b.UpdatedAt.IsZero() {
+			tokens = float64(limit.Burst)
+		}
+
+		if need := math.Max(n, 1); tokens < need {
+			wait = time.Duration((need - tokens) * float64(limit.Every))
+		} else {
+			tokens -= n
+		}
+
+		left = tokens
+
+		b.Tokens = tokens
+		b.UpdatedAt = now
+		b.ExpiresAt = now.Add(limit.refillDuration())
+	})
+
+	return wait, left, err
+}
+
+// Allow takes one request from the bucket under key. It returns how long to wait when the bucket is empty, 0 when the
+// request is allowed.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (time.Duration, error) {
+	wait, _, err := l.take(ctx, key, limit, 1)
+	return wait, err
+}
+
+// Locked returns how long key is still locked out for after too many failures, 0 when it is not
+func (l *Limiter) Locked(ctx context.Context, key string) (time.Duration, error) {
+	wait, _, err := l.take(ctx, "lock:"+key, Limit{Burst: 1, Every: lockoutDuration}, 0)
+	return wait, err
+}
+
+// Fail counts a failed attempt against key and locks it out once it has failed too often
+func (l *Limiter) Fail(ctx context.Context, key string) error {
+	_, left, err := l.take(ctx, "fail:"+key, failureLimit, 1)
+	if err != nil {
+		return err
+	}
+
+	if left >= 1 {
+		return nil
+	}
+
+	_, _, err = l.take(ctx, "lock:"+key, Limit{Burst: 1, Every: lockoutDuration}, 1)
+
+	return err
+}
+
+// Succeed forgets the failures of key once an attempt works
+func (l *Limiter) Succeed(ctx context.Context, key string) error {
+	return l.store.Delete(ctx, "fail:"+key)
+}
+
+// EmailKey is what failures and lockouts of an email are counted under, whichever way it was used to sign in
+func EmailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// AccountKey is what failures and lockouts of a signed in step, like a two factor code, are counted under
+func AccountKey(accountID int64) string {
+	return "account:" + strconv.FormatInt(accountID, 10)
+}
+
+// ShareTokenKey is what failed attempts at the password of a public link are counted under
+func ShareTokenKey(shareToken string) string {
+	return "share:" + shareToken
+}
+
+// TooManyRequests answers a request that was rate limited or locked out
+func TooManyRequests(w http.ResponseWriter, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+
+	util.Response(w, fmt.Sprintf("too many attempts, try again in %d seconds", seconds), http.StatusTooManyRequests)
+}
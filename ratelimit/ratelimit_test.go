@@ -0,0 +1,242 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestLimiter is a limiter on a memory store whose time only moves when the test advances the clock
+func newTestLimiter() (*Limiter, *memoryStore, *testClock) {
+	clock := &testClock{now: time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)}
+
+	store := newMemoryStore()
+	store.now = clock.Now
+
+	limiter := New(store)
+	limiter.now = clock.Now
+
+	return limiter, store, clock
+}
+
+func TestAllow(t *testing.T) {
+	l, _, clock := newTestLimiter()
+
+	limit := Limit{Burst: 3, Every: 10 * time.Second}
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		want    time.Duration
+	}{
+		{"first of the burst", 0, 0},
+		{"second of the burst", 0, 0},
+		{"last of the burst", 0, 0},
+		{"bucket is empty", 0, 10 * time.Second},
+		{"bucket is partly refilled", 4 * time.Second, 6 * time.Second},
+		{"one request refilled", 6 * time.Second, 0},
+		{"empty again", 0, 10 * time.Second},
+		{"full again after the refill duration", 30 * time.Second, 0},
+		{"second of the new burst", 0, 0},
+		{"refill stops at the burst", 0, 0},
+		{"empty after the new burst", 0, 10 * time.Second},
+	}
+
+	for _, s := range steps {
+		clock.Advance(s.advance)
+
+		wait, err := l.Allow(context.Background(), "ip:192.0.2.1", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if wait != s.want {
+			t.Errorf("%s: Allow() = %v, want %v", s.name, wait, s.want)
+		}
+	}
+}
+
+func TestTooManyRequests(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	TooManyRequests(w, 6200*time.Millisecond)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	if got := w.Header().Get("Retry-After"); got != "7" {
+		t.Errorf("Retry-After = %q, want the wait rounded up to %q", got, "7")
+	}
+}
+
+func TestLockout(t *testing.T) {
+	ctx := context.Background()
+
+	fail := func(t *testing.T, l *Limiter, key string, times int) {
+		t.Helper()
+
+		for i := 0; i < times; i++ {
+			if err := l.Fail(ctx, key); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	locked := func(t *testing.T, l *Limiter, key string) time.Duration {
+		t.Helper()
+
+		wait, err := l.Locked(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return wait
+	}
+
+	t.Run("locked after the failure limit", func(t *testing.T) {
+		l, _, clock := newTestLimiter()
+
+		fail(t, l, "email:jane@example.com", failureLimit.Burst-1)
+
+		if wait := locked(t, l, "email:jane@example.com"); wait != 0 {
+			t.Fatalf("Locked() after %d failures = %v, want 0", failureLimit.Burst-1, wait)
+		}
+
+		fail(t, l, "email:jane@example.com", 1)
+
+		if wait := locked(t, l, "email:jane@example.com"); wait != lockoutDuration {
+			t.Fatalf("Locked() after %d failures = %v, want %v", failureLimit.Burst, wait, lockoutDuration)
+		}
+
+		clock.Advance(10 * time.Minute)
+
+		if wait := locked(t, l, "email:jane@example.com"); wait != lockoutDuration-10*time.Minute {
+			t.Errorf("Locked() 10 minutes in = %v, want %v", wait, lockoutDuration-10*time.Minute)
+		}
+
+		clock.Advance(lockoutDuration - 10*time.Minute)
+
+		if wait := locked(t, l, "email:jane@example.com"); wait != 0 {
+			t.Errorf("Locked() after the lockout = %v, want 0", wait)
+		}
+	})
+
+	t.Run("failures are forgotten over time", func(t *testing.T) {
+		l, _, clock := newTestLimiter()
+
+		fail(t, l, "email:jane@example.com", failureLimit.Burst-1)
+
+		clock.Advance(failureLimit.Every)
+
+		fail(t, l, "email:jane@example.com", 1)
+
+		if wait := locked(t, l, "email:jane@example.com"); wait != 0 {
+			t.Errorf("Locked() = %v, want 0 as one failure was forgotten", wait)
+		}
+
+		fail(t, l, "email:jane@example.com", 1)
+
+		if wait := locked(t, l, "email:jane@example.com"); wait == 0 {
+			t.Errorf("Locked() = 0, want a lockout")
+		}
+	})
+
+	t.Run("succeed resets the failures", func(t *testing.T) {
+		l, _, _ := newTestLimiter()
+
+		fail(t, l, "account:7", failureLimit.Burst-1)
+
+		if err := l.Succeed(ctx, "account:7"); err != nil {
+			t.Fatal(err)
+		}
+
+		fail(t, l, "account:7", failureLimit.Burst-1)
+
+		if wait := locked(t, l, "account:7"); wait != 0 {
+			t.Fatalf("Locked() = %v, want 0 as the earlier failures were reset", wait)
+		}
+
+		fail(t, l, "account:7", 1)
+
+		if wait := locked(t, l, "account:7"); wait == 0 {
+			t.Errorf("Locked() = 0, want a lockout")
+		}
+	})
+}
+
+func TestKeysAreIndependent(t *testing.T) {
+	ctx := context.Background()
+
+	l, _, _ := newTestLimiter()
+
+	limit := Limit{Burst: 1, Every: time.Minute}
+
+	if wait, _ := l.Allow(ctx, "ip:192.0.2.1", limit); wait != 0 {
+		t.Fatalf("Allow() = %v, want 0", wait)
+	}
+
+	if wait, _ := l.Allow(ctx, "ip:192.0.2.1", limit); wait == 0 {
+		t.Fatalf("Allow() = 0, want the bucket to be empty")
+	}
+
+	if wait, _ := l.Allow(ctx, "ip:192.0.2.2", limit); wait != 0 {
+		t.Errorf("Allow() of another key = %v, want 0", wait)
+	}
+
+	for i := 0; i < failureLimit.Burst; i++ {
+		if err := l.Fail(ctx, EmailKey("jane@example.com")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if wait, _ := l.Locked(ctx, EmailKey(" Jane@Example.com ")); wait == 0 {
+		t.Errorf("Locked() = 0, want the email to be locked whatever its case")
+	}
+
+	for _, key := range []string{EmailKey("john@example.com"), AccountKey(7), ShareTokenKey("jane@example.com")} {
+		if wait, _ := l.Locked(ctx, key); wait != 0 {
+			t.Errorf("Locked(%s) = %v, want 0", key, wait)
+		}
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	ctx := context.Background()
+
+	l, store, clock := newTestLimiter()
+
+	limit := Limit{Burst: 2, Every: time.Second}
+
+	if _, err := l.Allow(ctx, "ip:192.0.2.1", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(sweepInterval + limit.refillDuration() + time.Second)
+
+	if _, err := l.Allow(ctx, "ip:192.0.2.2", limit); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.buckets["ip:192.0.2.1"]; ok {
+		t.Errorf("bucket that was full again was kept")
+	}
+
+	if _, ok := store.buckets["ip:192.0.2.2"]; !ok {
+		t.Errorf("bucket in use was thrown away")
+	}
+}
@@ -1,12 +1,31 @@
 package router
 
 import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/kwandapchumba/go-bookmark-manager/api"
 	"github.com/kwandapchumba/go-bookmark-manager/db/connection"
 	cm "github.com/kwandapchumba/go-bookmark-manager/middleware"
+	"github.com/kwandapchumba/go-bookmark-manager/oauth"
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
+	"github.com/kwandapchumba/go-bookmark-manager/storage"
+	"github.com/kwandapchumba/go-bookmark-manager/tasks"
+	"github.com/kwandapchumba/go-bookmark-manager/webauthn"
+)
+
+// limits of the public authentication routes, per client IP and per email address
+var (
+	signInIPLimit    = ratelimit.Limit{Burst: 20, Every: 30 * time.Second}
+	signInEmailLimit = ratelimit.Limit{Burst: 10, Every: time.Minute}
+	mailIPLimit      = ratelimit.Limit{Burst: 10, Every: time.Minute}
+	mailEmailLimit   = ratelimit.Limit{Burst: 3, Every: 5 * time.Minute}
 )
 
 func Router() *chi.Mux {
@@ -24,12 +43,52 @@ func Router() *chi.Mux {
 
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.RealIP)
+	r.Use(cm.RealIP())
 	r.Use(middleware.AllowContentEncoding("application/json", "application/x-www-form-urlencoded"))
 	r.Use(middleware.CleanPath)
 	r.Use(middleware.RedirectSlashes)
 
-	h := api.NewBaseHandler(connection.ConnectDB())
+	db := connection.ConnectDB()
+
+	store := storage.NewBlobStore()
+
+	// blobs on local disk are served by the api itself
+	if local, ok := store.(*storage.LocalStore); ok {
+		r.Handle(local.MountPath()+"/*", local)
+	}
+
+	queue := tasks.NewQueue()
+
+	tasks.RegisterLinkMetadataHandler(queue, db, store)
+	tasks.RegisterLinkArchiveHandler(queue, db, store)
+	tasks.RegisterAccountDeletionHandler(queue, db, store)
+
+	if err := queue.Start(context.Background()); err != nil {
+		log.Panicf("could not start background workers: %v", err)
+	}
+
+	tasks.SweepAccountDeletions(context.Background(), queue, db)
+
+	limiter := ratelimit.New(ratelimit.NewStore(db))
+
+	h := api.NewBaseHandler(db, queue, store, oauth.NewRegistry(), webauthn.NewRelyingParty(), limiter)
+
+	routes(r, db, h, limiter)
+
+	return r
+}
+
+// routes registers the public, private and admin routes of the api
+func routes(r chi.Router, db *sql.DB, h *api.BaseHandler, limiter *ratelimit.Limiter) {
+	// sign ins are limited per client IP and per email, routes that send an email more strictly so our mail quota
+	// cannot be spammed away
+	signInLimit := cm.RateLimit(limiter, "signin", signInIPLimit, signInEmailLimit)
+	mailLimit := func(route string) func(http.Handler) http.Handler {
+		return cm.RateLimit(limiter, route, mailIPLimit, mailEmailLimit)
+	}
+	ipLimit := func(route string) func(http.Handler) http.Handler {
+		return cm.RateLimit(limiter, route, signInIPLimit, ratelimit.Limit{})
+	}
 
 	// public routes go here
 	r.Route("/public", func(r chi.Router) {
@@ -39,66 +98,145 @@ func Router() *chi.Mux {
 
 		r.Post("/refreshToken", h.RefreshToken)
 
-		r.Post("/sendOTP", h.SendOTP)
-
-		r.Post("/verifyOTP", h.VerifyOTP)
-
-		r.Get("/getUserMessages", h.GetAllUserMessages)
+		r.With(mailLimit("sendOTP")).Post("/sendOTP", h.SendOTP)
 
-		r.Get("/getLinksByAccountID/{accountID}", h.GetLinksByUserID)
+		r.With(signInLimit).Post("/verifyOTP", h.VerifyOTP)
 
-		r.Post("/requestResetPasswordLink", h.RequestResetPasswordLink)
+		r.With(mailLimit("requestResetPasswordLink")).Post("/requestResetPasswordLink", h.RequestResetPasswordLink)
 
-		r.Patch("/updatePassword", h.UpdatePassword)
+		r.With(ipLimit("updatePassword")).Patch("/updatePassword", h.UpdatePassword)
 
 		r.Post("/uploadHeroImage", h.UploadHeroImage)
 
 		r.Get("/getCollectionAndInviterNames/{inviteToken}", h.GetCollectionAndInviterNames)
 
-		r.Post("/acceptInvite", h.AcceptInvite)
+		r.With(ipLimit("acceptInvite")).Post("/acceptInvite", h.AcceptInvite)
+
+		r.Route("/oauth", func(r chi.Router) {
+			r.Get("/providers", h.GetOAuthProviders)
+			r.Get("/{provider}/login", h.OAuthLogin)
+			r.Get("/{provider}/callback", h.OAuthCallback)
+		})
+
+		r.Route("/shared/{shareToken}", func(r chi.Router) {
+			r.Get("/", h.GetSharedCollectionInfo)
+			r.With(ipLimit("unlockPublicLink")).Post("/unlock", h.UnlockPublicLink)
+			r.Get("/getLinksAndFolders/{folderID}", h.GetSharedLinksAndFolders)
+		})
 
 		r.Route("/account", func(r chi.Router) {
 			r.Post("/", h.ContinueWithGoogle)
-			r.Post("/create", h.NewAccount)
-			r.Get("/getAllAccounts", h.GetAllAccounts)
-			r.Post("/signin", h.SignIn)
+			r.With(ipLimit("createAccount")).Post("/create", h.NewAccount)
+			r.With(signInLimit).Post("/signin", h.SignIn)
+			r.With(ipLimit("twoFactor")).Post("/signin/twoFactor", h.VerifyTwoFactorSignIn)
+			r.With(ipLimit("passkey")).Post("/signin/passkey/begin", h.BeginPasskeySignIn)
+			r.With(ipLimit("passkey")).Post("/signin/passkey/finish", h.FinishPasskeySignIn)
 		})
 	})
 
 	// private routes ie they require authenticated calls
 	r.Route("/private", func(r chi.Router) {
-		r.Use(cm.AuthenticateRequest())
+		r.Use(cm.AuthenticateRequest(db))
 
-		r.Get("/checkIfFolderHasBeenSharedWithUser/{userID}/{folderID}", h.CheckIfFolderHasBeenSharedWithUser)
+		r.With(cm.RequireScope("folders")).Get("/checkIfFolderHasBeenSharedWithUser/{userID}/{folderID}", h.CheckIfFolderHasBeenSharedWithUser)
 
-		// r.Get("/getLinksAndFolders/{accountID}/{folderID}", h.GetLinksAndFolders)
-		r.Route("/getLinksAndFolders/{accountID}/{folderID}", func(r chi.Router) {
-			r.Use(cm.AuthorizeReadRequestOnCollection())
-			r.Get("/", h.GetLinksAndFolders)
-		})
+		// routes that touch links and folders together need the scopes of both
+		r.Group(func(r chi.Router) {
+			r.Use(cm.RequireScope("links", "folders"))
+
+			// importing creates folders for the folders of the file
+			r.Post("/link/import", h.ImportBookmarks)
+
+			// r.Get("/getLinksAndFolders/{accountID}/{folderID}", h.GetLinksAndFolders)
+			r.Route("/getLinksAndFolders/{accountID}/{folderID}", func(r chi.Router) {
+				r.Use(cm.AuthorizeReadRequestOnCollection(db))
+				r.Get("/", h.GetLinksAndFolders)
+			})
+
+			r.Route("/getCollectionsSharedWithMe/{accountID}/{folderID}", func(r chi.Router) {
+				r.Use(cm.AuthorizeReadRequestOnCollection(db))
+				r.Get("/", h.GetCollectionsSharedWithMe)
+			})
+
+			r.Get("/getFoldersAndLinksMovedToTrash/{accountID}", h.GetFoldersAndLinksMovedToTrash)
+
+			r.Get("/search", h.Search)
 
-		r.Route("/getCollectionsSharedWithMe/{accountID}/{folderID}", func(r chi.Router) {
-			r.Use(cm.AuthorizeReadRequestOnCollection())
-			r.Get("/", h.GetCollectionsSharedWithMe)
+			r.Get("/smartFolder/{smartFolderID}", h.GetSmartFolder)
+			r.Get("/smartFolder/{smartFolderID}/export", h.ExportSmartFolder)
+
+			r.Route("/export/{accountID}/{folderID}", func(r chi.Router) {
+				r.Use(cm.AuthorizeReadRequestOnCollection(db))
+				r.Get("/", h.ExportBookmarks)
+			})
 		})
 
-		r.Get("/getFoldersAndLinksMovedToTrash/{accountID}", h.GetFoldersAndLinksMovedToTrash)
+		// account settings, personal access tokens cannot be used for these
+		r.Group(func(r chi.Router) {
+			r.Use(cm.RequireSession())
+
+			r.Post("/logout", h.Logout)
+
+			r.Route("/account/tokens", func(r chi.Router) {
+				r.Get("/", h.GetPersonalAccessTokens)
+				r.Post("/", h.CreatePersonalAccessToken)
+				r.Delete("/{tokenID}", h.RevokePersonalAccessToken)
+			})
+
+			r.Route("/account/identities", func(r chi.Router) {
+				r.Get("/", h.GetAccountIdentities)
+				r.Post("/{provider}/link", h.LinkOAuthProvider)
+				r.Delete("/{provider}", h.UnlinkOAuthProvider)
+			})
+
+			r.Route("/account/2fa", func(r chi.Router) {
+				r.Get("/", h.GetTwoFactorStatus)
+				r.Post("/enroll", h.EnrollTwoFactor)
+				r.Post("/confirm", h.ConfirmTwoFactor)
+				r.Post("/disable", h.DisableTwoFactor)
+				r.Post("/recoveryCodes", h.RegenerateRecoveryCodes)
+			})
+
+			r.Route("/account/passkeys", func(r chi.Router) {
+				r.Get("/", h.GetPasskeys)
+				r.Post("/register/begin", h.BeginPasskeyRegistration)
+				r.Post("/register/finish", h.FinishPasskeyRegistration)
+				r.Patch("/{credentialID}", h.RenamePasskey)
+				r.Delete("/{credentialID}", h.DeletePasskey)
+			})
+
+			r.Route("/account/email", func(r chi.Router) {
+				r.With(mailLimit("changeEmail")).Post("/", h.ChangeEmail)
+				r.With(ipLimit("confirmEmailChange")).Post("/confirm", h.ConfirmEmailChange)
+			})
+
+			r.Get("/account/export", h.ExportAccountData)
+
+			r.Route("/account/deletion", func(r chi.Router) {
+				r.Get("/", h.GetAccountDeletion)
+				r.With(mailLimit("accountDeletion")).Post("/", h.RequestAccountDeletion)
+				r.With(ipLimit("confirmAccountDeletion")).Post("/confirm", h.ConfirmAccountDeletion)
+				r.Delete("/", h.CancelAccountDeletion)
+			})
+
+			r.Route("/account/sessions", func(r chi.Router) {
+				r.Get("/", h.GetAccountSessions)
+				r.Delete("/others", h.RevokeOtherAccountSessions)
+				r.Delete("/{sessionID}", h.RevokeAccountSession)
+			})
+		})
 
 		r.Route("/folder", func(r chi.Router) {
+			r.Use(cm.RequireScope("folders"))
+
 			r.Route("/create", func(r chi.Router) {
 				// user create folder authorization middleware
-				r.Use(cm.AuthorizeCreateFolderRequest())
+				r.Use(cm.AuthorizeCreateFolderRequest(db))
 				r.Post("/", h.CreateFolder)
 			})
 
-			r.Route("/share", func(r chi.Router) {
-				// use share collecton authorization middleware
-				r.Use(cm.AuthorizeShareCollectionRequest())
-				r.Post("/", h.ShareCollection)
-			})
-
 			r.Route("/getOne/{accountID}/{folderID}", func(r chi.Router) {
-				r.Use(cm.AuthorizeReadRequestOnCollection())
+				r.Use(cm.AuthorizeReadRequestOnCollection(db))
 				r.Get("/", h.GetFolder)
 			})
 
@@ -117,24 +255,98 @@ func Router() *chi.Mux {
 			r.Get("/getRootFoldersByUserID", h.GetRootFolders)
 			r.Get("/getFolderChildren/{folderID}/{accountID}", h.GetFolderChildren)
 			r.Get("/getFolderAncestors/{folderID}", h.GetFolderAncestors)
-			r.Get("/searchFolders/{query}", h.SearchFolders)
 			r.Get("/getCollection/{collectionID}", h.GetCollection)
+
+			// who a collection is shared with is up to the account itself, personal access tokens cannot be used here
+			r.Group(func(r chi.Router) {
+				r.Use(cm.RequireSession())
+
+				r.Route("/share", func(r chi.Router) {
+					// use share collecton authorization middleware
+					r.Use(cm.AuthorizeShareCollectionRequest(db))
+					r.Post("/", h.ShareCollection)
+				})
+
+				r.Route("/members", func(r chi.Router) {
+					r.Get("/{folderID}", h.GetCollectionMembers)
+					r.Patch("/changeAccessLevel", h.ChangeMemberAccessLevel)
+					r.Delete("/remove", h.RemoveCollectionMember)
+					r.Delete("/leave", h.LeaveCollection)
+				})
+
+				r.Route("/invites", func(r chi.Router) {
+					r.Patch("/resend", h.ResendInvite)
+					r.Delete("/revoke", h.RevokeInvite)
+				})
+
+				r.Route("/publicLink", func(r chi.Router) {
+					r.Post("/create", h.CreatePublicLink)
+					r.Get("/getAll/{folderID}", h.GetPublicLinks)
+					r.Delete("/revoke/{shareToken}", h.RevokePublicLink)
+				})
+			})
+		})
+
+		// smart folders are listed with folders, running one is in the group above as it also returns links
+		r.Group(func(r chi.Router) {
+			r.Use(cm.RequireScope("folders"))
+
+			r.Post("/smartFolder/create", h.CreateSmartFolder)
+			r.Patch("/smartFolder/{smartFolderID}", h.UpdateSmartFolder)
+			r.Delete("/smartFolder/{smartFolderID}", h.DeleteSmartFolder)
 		})
 
 		r.Route("/link", func(r chi.Router) {
+			r.Use(cm.RequireScope("links"))
+
 			r.Post("/add", h.AddLink)
 			r.Patch("/rename", h.RenameLink)
+			r.Patch("/{linkID}", h.UpdateLink)
+			r.Put("/{linkID}/thumbnail", h.SetLinkThumbnail)
+			r.Get("/{linkID}/archive", h.GetLinkArchive)
+			r.Post("/{linkID}/archive", h.ArchiveLink)
+			r.Get("/{linkID}/archive/snapshot", h.GetLinkArchiveSnapshot)
+			r.Get("/{linkID}/archive/article", h.GetLinkArchiveArticle)
 			r.Patch("/move", h.MoveLinks)
 			r.Patch("/moveLinksToTrash", h.MoveLinksToTrash)
 			r.Patch("/restoreLinksFromTrash", h.RestoreLinksFromTrash)
 			r.Delete("/deleteLinksForever", h.DeleteLinksForever)
 			r.Get("/getRootLinks/{accountID}", h.GetRootLinks)
 			r.Get("/get_folder_links/{accountID}/{folderID}", h.GetFolderLinks)
-			r.Get("/searchLinks/{query}", h.SearchLinks)
 		})
 
-		r.Post("/contactSupport", h.ContactSupport)
+		r.Route("/tag", func(r chi.Router) {
+			r.Use(cm.RequireScope("tags"))
+
+			r.Post("/create", h.CreateTag)
+			r.Get("/getAll", h.GetTags)
+			r.Patch("/rename", h.RenameTag)
+			r.Patch("/merge", h.MergeTags)
+			r.Patch("/tagItems", h.TagItems)
+			r.Patch("/untagItems", h.UntagItems)
+			r.Get("/getItems/{tagID}", h.GetTagItems)
+			r.Delete("/delete/{tagID}", h.DeleteTag)
+		})
+
+		r.With(cm.RequireSession()).Post("/contactSupport", h.ContactSupport)
 	})
 
-	return r
+	// admin routes, only signed in sessions of admin accounts get through
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(cm.AuthenticateRequest(db))
+		r.Use(cm.RequireAdmin(db))
+
+		r.Get("/stats", h.GetSystemStats)
+
+		r.Route("/accounts", func(r chi.Router) {
+			r.Get("/", h.ListAccounts)
+			r.Post("/{accountID}/suspend", h.SuspendAccount)
+			r.Post("/{accountID}/unsuspend", h.UnsuspendAccount)
+		})
+
+		r.Route("/support", func(r chi.Router) {
+			r.Get("/", h.GetSupportMessages)
+			r.Post("/{messageID}/resolve", h.ResolveSupportMessage)
+		})
+	})
 }
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kwandapchumba/go-bookmark-manager/api"
+	"github.com/kwandapchumba/go-bookmark-manager/auth"
+	"github.com/kwandapchumba/go-bookmark-manager/db/dbtest"
+	"github.com/kwandapchumba/go-bookmark-manager/ratelimit"
+)
+
+var patColumns = []string{"token_id", "account_id", "token_name", "token_hash", "token_prefix", "scopes", "expires_at", "last_used_at", "token_created_at"}
+
+// testRouter has the routes of the api on a database that knows one personal access token for every set of scopes
+func testRouter(tokens map[string]string) http.Handler {
+	db := dbtest.Open(dbtest.Queries{
+		"GetPersonalAccessTokenByHash": func(args []driver.Value) ([]string, [][]driver.Value, error) {
+			for token, scopes := range tokens {
+				if auth.HashPersonalAccessToken(token) == args[0] {
+					return patColumns, [][]driver.Value{{int64(1), int64(1), "test", args[0], token[:10], scopes, nil, nil, time.Now()}}, nil
+				}
+			}
+
+			return patColumns, nil, nil
+		},
+		"TouchPersonalAccessToken": func(args []driver.Value) ([]string, [][]driver.Value, error) {
+			return nil, nil, nil
+		},
+	})
+
+	limiter := ratelimit.New(nil)
+
+	r := chi.NewRouter()
+
+	routes(r, db, api.NewBaseHandler(db, nil, nil, nil, nil, limiter), limiter)
+
+	return r
+}
+
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	const (
+		foldersToken = "lsp_folders_token"
+		linksToken   = "lsp_links_token"
+	)
+
+	r := testRouter(map[string]string{
+		foldersToken: "folders:read,folders:write",
+		linksToken:   "links:read,links:write",
+	})
+
+	tests := []struct {
+		name         string
+		token        string
+		method, path string
+		wantBody     string
+	}{
+		{"import needs folders", linksToken, http.MethodPost, "/private/link/import", "token is missing the folders:write scope"},
+		{"share collection", foldersToken, http.MethodPost, "/private/folder/share", "personal access tokens cannot be used here"},
+		{"get members", foldersToken, http.MethodGet, "/private/folder/members/abc", "personal access tokens cannot be used here"},
+		{"change access level", foldersToken, http.MethodPatch, "/private/folder/members/changeAccessLevel", "personal access tokens cannot be used here"},
+		{"remove member", foldersToken, http.MethodDelete, "/private/folder/members/remove", "personal access tokens cannot be used here"},
+		{"leave collection", foldersToken, http.MethodDelete, "/private/folder/members/leave", "personal access tokens cannot be used here"},
+		{"resend invite", foldersToken, http.MethodPatch, "/private/folder/invites/resend", "personal access tokens cannot be used here"},
+		{"revoke invite", foldersToken, http.MethodDelete, "/private/folder/invites/revoke", "personal access tokens cannot be used here"},
+		{"create public link", foldersToken, http.MethodPost, "/private/folder/publicLink/create", "personal access tokens cannot be used here"},
+		{"get public links", foldersToken, http.MethodGet, "/private/folder/publicLink/getAll/abc", "personal access tokens cannot be used here"},
+		{"revoke public link", foldersToken, http.MethodDelete, "/private/folder/publicLink/revoke/abc", "personal access tokens cannot be used here"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("%s %s = %d %s, want %d", tt.method, tt.path, w.Code, w.Body, http.StatusForbidden)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("%s %s = %s, want %q", tt.method, tt.path, w.Body, tt.wantBody)
+			}
+		})
+	}
+}
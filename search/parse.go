@@ -0,0 +1,268 @@
+// Package search parses the search box syntax and runs it against links and folders.
+//
+// A query is made of words, "quoted phrases" and filters, any of which can be excluded with a leading -:
+//
+//	site:github.com tag:go in:"Reading List" is:starred before:2024-01-01 -draft
+//
+// Words and phrases are matched with full-text search, archived copies of links included, filters narrow the results
+// down.
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Field string
+
+const (
+	// Site matches links on the host or any subdomain of it
+	Site Field = "site"
+	// Tag matches links and folders tagged with the tag of that name
+	Tag Field = "tag"
+	// In matches what is in the folders of that name, at any depth, and the folders themselves
+	In Field = "in"
+	// Is takes starred, which only folders can be, or trashed
+	Is Field = "is"
+	// Before and After compare the day a link was added or a folder created
+	Before Field = "before"
+	After  Field = "after"
+)
+
+const (
+	Starred = "starred"
+	Trashed = "trashed"
+)
+
+const dateLayout = "2006-01-02"
+
+type Filter struct {
+	Field   Field
+	Value   string
+	Exclude bool
+	// Date is set for Before and After
+	Date time.Time
+}
+
+type Query struct {
+	// Text is the words and phrases to look for in web search syntax, or between words is kept
+	Text string
+	// Excluded are the words and phrases that must not be found
+	Excluded []string
+	Filters  []Filter
+}
+
+// Error is a query that cannot be parsed. Pos is the offset of the offending part in bytes.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at character %d", e.Msg, e.Pos+1)
+}
+
+// Parse reads a query typed in the search box
+func Parse(s string) (*Query, error) {
+	q := &Query{}
+
+	var text []string
+
+	p := parser{s: s}
+
+	for {
+		p.skipSpace()
+
+		if p.done() {
+			break
+		}
+
+		start := p.pos
+
+		exclude := false
+
+		if p.peek() == '-' {
+			p.pos++
+
+			if p.done() || unicode.IsSpace(p.peek()) {
+				return nil, &Error{Pos: start, Msg: "nothing to exclude after -"}
+			}
+
+			exclude = true
+		}
+
+		if p.peek() == '"' {
+			phrase, err := p.quoted()
+			if err != nil {
+				return nil, err
+			}
+
+			if exclude {
+				q.Excluded = append(q.Excluded, phrase)
+			} else {
+				text = append(text, `"`+phrase+`"`)
+			}
+
+			continue
+		}
+
+		key, ok := p.key()
+		if !ok {
+			word := p.word()
+
+			if exclude {
+				q.Excluded = append(q.Excluded, word)
+			} else {
+				text = append(text, word)
+			}
+
+			continue
+		}
+
+		f, err := p.filter(key, start)
+		if err != nil {
+			return nil, err
+		}
+
+		f.Exclude = exclude
+
+		q.Filters = append(q.Filters, f)
+	}
+
+	q.Text = strings.Join(text, " ")
+
+	return q, nil
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *parser) peek() rune {
+	for _, r := range p.s[p.pos:] {
+		return r
+	}
+
+	return 0
+}
+
+// next moves past the rune at pos, which is more than one byte for spaces such as U+3000
+func (p *parser) next() {
+	_, size := utf8.DecodeRuneInString(p.s[p.pos:])
+
+	p.pos += size
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() && unicode.IsSpace(p.peek()) {
+		p.next()
+	}
+}
+
+// word reads up to the next space
+func (p *parser) word() string {
+	start := p.pos
+
+	for !p.done() && !unicode.IsSpace(p.peek()) {
+		p.next()
+	}
+
+	return p.s[start:p.pos]
+}
+
+// quoted reads a quoted phrase, p is at the opening quote
+func (p *parser) quoted() (string, error) {
+	start := p.pos
+
+	end := strings.IndexByte(p.s[start+1:], '"')
+	if end < 0 {
+		return "", &Error{Pos: start, Msg: "missing closing quote"}
+	}
+
+	phrase := strings.TrimSpace(p.s[start+1 : start+1+end])
+
+	p.pos = start + end + 2
+
+	if phrase == "" {
+		return "", &Error{Pos: start, Msg: "empty quotes"}
+	}
+
+	if !p.done() && !unicode.IsSpace(p.peek()) {
+		return "", &Error{Pos: p.pos, Msg: "expected a space after the closing quote"}
+	}
+
+	return phrase, nil
+}
+
+// key reads the name of a filter, it leaves p where it was when what follows is not one
+func (p *parser) key() (string, bool) {
+	i := p.pos
+
+	for i < len(p.s) && (p.s[i] >= 'a' && p.s[i] <= 'z' || p.s[i] >= 'A' && p.s[i] <= 'Z') {
+		i++
+	}
+
+	if i == p.pos || i >= len(p.s) || p.s[i] != ':' {
+		return "", false
+	}
+
+	key := strings.ToLower(p.s[p.pos:i])
+
+	p.pos = i + 1
+
+	return key, true
+}
+
+// filter reads the value of key, start is where the filter begins
+func (p *parser) filter(key string, start int) (Filter, error) {
+	f := Filter{Field: Field(key)}
+
+	switch f.Field {
+	case Site, Tag, In, Is, Before, After:
+	default:
+		return f, &Error{Pos: start, Msg: fmt.Sprintf("unknown filter %q, filters are site, tag, in, is, before and after. Put text with a colon in quotes", key)}
+	}
+
+	valuePos := p.pos
+
+	var err error
+
+	if !p.done() && p.peek() == '"' {
+		f.Value, err = p.quoted()
+		if err != nil {
+			return f, err
+		}
+	} else {
+		f.Value = p.word()
+	}
+
+	if f.Value == "" {
+		return f, &Error{Pos: start, Msg: fmt.Sprintf("%s: needs a value", key)}
+	}
+
+	switch f.Field {
+	case Site:
+		f.Value = strings.TrimSuffix(strings.ToLower(f.Value), "/")
+	case Is:
+		f.Value = strings.ToLower(f.Value)
+
+		if f.Value != Starred && f.Value != Trashed {
+			return f, &Error{Pos: valuePos, Msg: fmt.Sprintf("is: takes starred or trashed, not %q", f.Value)}
+		}
+	case Before, After:
+		f.Date, err = time.Parse(dateLayout, f.Value)
+		if err != nil {
+			return f, &Error{Pos: valuePos, Msg: fmt.Sprintf("%s: takes a date like 2024-01-31, not %q", key, f.Value)}
+		}
+	}
+
+	return f, nil
+}
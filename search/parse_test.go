@@ -0,0 +1,123 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  Query
+	}{
+		{"empty", "", Query{}},
+		{"only spaces", " \t ", Query{}},
+		{"words", "hello  world", Query{Text: "hello world"}},
+		{"or is kept", "go or rust", Query{Text: "go or rust"}},
+		{"phrase", `"exact phrase" word`, Query{Text: `"exact phrase" word`}},
+		{"phrase is trimmed", `" padded "`, Query{Text: `"padded"`}},
+		{"colon in quotes is text", `"a:b"`, Query{Text: `"a:b"`}},
+		{"excluded word", "go -draft", Query{Text: "go", Excluded: []string{"draft"}}},
+		{"excluded phrase", `-"not this"`, Query{Excluded: []string{"not this"}}},
+		{"dash inside a word", "e-mail", Query{Text: "e-mail"}},
+		{"site", "site:GitHub.com/", Query{Filters: []Filter{{Field: Site, Value: "github.com"}}}},
+		{"tag", "tag:go", Query{Filters: []Filter{{Field: Tag, Value: "go"}}}},
+		{"excluded tag", "-tag:rust", Query{Filters: []Filter{{Field: Tag, Value: "rust", Exclude: true}}}},
+		{"in quoted", `in:"Reading List"`, Query{Filters: []Filter{{Field: In, Value: "Reading List"}}}},
+		{"excluded in quoted", `-in:"Old Stuff"`, Query{Filters: []Filter{{Field: In, Value: "Old Stuff", Exclude: true}}}},
+		{"is starred", "is:starred", Query{Filters: []Filter{{Field: Is, Value: Starred}}}},
+		{"is is case insensitive", "IS:Trashed", Query{Filters: []Filter{{Field: Is, Value: Trashed}}}},
+		{"before and after", "before:2024-01-01 after:2023-06-30", Query{Filters: []Filter{
+			{Field: Before, Value: "2024-01-01", Date: date("2024-01-01")},
+			{Field: After, Value: "2023-06-30", Date: date("2023-06-30")},
+		}}},
+		{"everything", `site:github.com tag:go in:"Reading List" is:starred before:2024-01-01 -draft parser`, Query{
+			Text:     "parser",
+			Excluded: []string{"draft"},
+			Filters: []Filter{
+				{Field: Site, Value: "github.com"},
+				{Field: Tag, Value: "go"},
+				{Field: In, Value: "Reading List"},
+				{Field: Is, Value: Starred},
+				{Field: Before, Value: "2024-01-01", Date: date("2024-01-01")},
+			},
+		}},
+		{"multi-byte spaces", "　go 　rust ", Query{Text: "go rust"}},
+		{"multi-byte words", "café naïve", Query{Text: "café naïve"}},
+		{"invalid utf-8", "\xffgo \xfe", Query{Text: "\xffgo \xfe"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.query, err)
+			}
+
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.query, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantPos int
+		wantMsg string
+	}{
+		{"dash alone", "-", 0, "nothing to exclude after -"},
+		{"dash before a space", "go - rust", 3, "nothing to exclude after -"},
+		{"missing closing quote", `go "open`, 3, "missing closing quote"},
+		{"empty quotes", `a "  " b`, 2, "empty quotes"},
+		{"text after a closing quote", `"a"b`, 3, "expected a space after the closing quote"},
+		{"unknown filter", "go foo:bar", 3, `unknown filter "foo", filters are site, tag, in, is, before and after. Put text with a colon in quotes`},
+		{"url without quotes", "https://example.com", 0, `unknown filter "https", filters are site, tag, in, is, before and after. Put text with a colon in quotes`},
+		{"excluded unknown filter", "-foo:bar", 0, `unknown filter "foo", filters are site, tag, in, is, before and after. Put text with a colon in quotes`},
+		{"filter without a value", "tag:", 0, "tag: needs a value"},
+		{"filter with a space for a value", "site: github.com", 0, "site: needs a value"},
+		{"filter with an unclosed quote", `in:"Reading`, 3, "missing closing quote"},
+		{"is takes starred or trashed", "is:open", 3, `is: takes starred or trashed, not "open"`},
+		{"before takes a date", "before:yesterday", 7, `before: takes a date like 2024-01-31, not "yesterday"`},
+		{"excluded after takes a date", "-after:2024-13-01", 7, `after: takes a date like 2024-01-31, not "2024-13-01"`},
+		{"position after multi-byte spaces", "　　tag:", 6, "tag: needs a value"},
+		{"position after multi-byte letters", "café is:open", 9, `is: takes starred or trashed, not "open"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+
+			var perr *Error
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse(%q) = %v, want a *Error", tt.query, err)
+			}
+
+			if perr.Pos != tt.wantPos || perr.Msg != tt.wantMsg {
+				t.Errorf("Parse(%q) = {Pos: %d, Msg: %q}, want {Pos: %d, Msg: %q}", tt.query, perr.Pos, perr.Msg, tt.wantPos, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	err := &Error{Pos: 3, Msg: "missing closing quote"}
+
+	if got, want := err.Error(), "missing closing quote at character 4"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,360 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
+// ts_headline marks matches with characters from the private use area rather than tags, the text around them is
+// escaped before they are turned into <b></b>
+const (
+	startSel = "\ue000"
+	stopSel  = "\ue001"
+
+	headlineOptions = "MaxFragments=2, MinWords=5, MaxWords=20, StartSel=" + startSel + ", StopSel=" + stopSel
+)
+
+var highlighter = strings.NewReplacer(startSel, "<b>", stopSel, "</b>")
+
+// highlight escapes a headline for html and wraps the matches in <b></b>
+func highlight(headline string) string {
+	return highlighter.Replace(html.EscapeString(headline))
+}
+
+// readableFolder is the condition of the folders an account can read, resolved the way the authorization middleware
+// does: every folder below a root folder it owns and every folder below a collection it is a member of. Its argument
+// is the placeholder of the account.
+const readableFolder = "(EXISTS (SELECT 1 FROM folder AS root WHERE root.path = subpath(folder.path, 0, 1) AND root.account_id = %[1]s)" +
+	" OR EXISTS (SELECT 1 FROM collection_member JOIN folder AS shared ON shared.folder_id = collection_member.collection_id WHERE collection_member.member_id = %[1]s AND shared.path @> folder.path))"
+
+// table is what a query needs to know about link and folder. Filters that do not apply to a table match none of its
+// rows, or all of them when excluded.
+type table struct {
+	name string
+	// from is name joined with what else is searched
+	from    string
+	columns string
+	// document is the search index of a row
+	document string
+	title    string
+	headline string
+	created  string
+	deleted  string
+	// tags joins the tags of a row
+	tags string
+	// folderPath is the path of the folder a row is in, or is
+	folderPath string
+	// inFolder is the condition of rows below the folder whose path is its argument
+	inFolder string
+	// readable is the condition of rows the account whose placeholder is its argument can read, links outside folders
+	// are only their adder's
+	readable string
+	hostname string
+	starred  string
+}
+
+var links = table{
+	name:       "link",
+	from:       "link LEFT JOIN link_archive ON link_archive.link_id = link.link_id",
+	document:   "(link.textsearchable_index_col || COALESCE(link_archive.textsearchable_index_col, ''::tsvector))",
+	columns:    "link.link_id, link.link_title, link.link_thumbnail, link.link_favicon, link.link_hostname, link.link_url, link.link_notes, link.account_id, link.folder_id, link.added_at, link.updated_at, link.deleted_at, link.metadata_status",
+	title:      "link.link_title",
+	headline:   "link.link_title || ' ' || link.link_notes || ' ' || COALESCE(link_archive.article_text, '')",
+	created:    "link.added_at",
+	deleted:    "link.deleted_at",
+	tags:       "link_tag JOIN tag ON tag.tag_id = link_tag.tag_id WHERE link_tag.link_id = link.link_id",
+	folderPath: "(SELECT path FROM folder WHERE folder.folder_id = link.folder_id)",
+	inFolder:   "COALESCE((SELECT path FROM folder WHERE folder.folder_id = link.folder_id) <@ %[1]s::ltree, FALSE)",
+	readable:   "((link.folder_id IS NULL AND link.account_id = %[1]s) OR EXISTS (SELECT 1 FROM folder WHERE folder.folder_id = link.folder_id AND " + readableFolder + "))",
+	hostname:   "link.link_hostname",
+}
+
+var folders = table{
+	name:       "folder",
+	from:       "folder",
+	document:   "folder.textsearchable_index_col",
+	columns:    "folder.folder_id, folder.account_id, folder.folder_name, folder.path, folder.label, folder.starred, folder.folder_created_at, folder.folder_updated_at, folder.subfolder_of, folder.folder_deleted_at",
+	title:      "folder.folder_name",
+	headline:   "folder.folder_name",
+	created:    "folder.folder_created_at",
+	deleted:    "folder.folder_deleted_at",
+	tags:       "folder_tag JOIN tag ON tag.tag_id = folder_tag.tag_id WHERE folder_tag.folder_id = folder.folder_id",
+	folderPath: "folder.path",
+	inFolder:   "folder.path <@ %[1]s::ltree AND folder.path <> %[1]s::ltree",
+	readable:   readableFolder,
+	starred:    "folder.starred",
+}
+
+// Scope is what a query searches, everything an account can read or everything in a folder whoever added it
+type Scope struct {
+	// AccountID is the account searching, tag: only matches its tags
+	AccountID int64
+	// FolderPath limits the search to what is below the folder with the path instead of what the account can read
+	FolderPath string
+}
+
+func AccountScope(accountID int64) Scope {
+	return Scope{AccountID: accountID}
+}
+
+func FolderScope(accountID int64, path string) Scope {
+	return Scope{AccountID: accountID, FolderPath: path}
+}
+
+// statement collects the arguments of a query as its sql is written
+type statement struct {
+	args []interface{}
+}
+
+func (s *statement) arg(v interface{}) string {
+	s.args = append(s.args, v)
+
+	return fmt.Sprintf("$%d", len(s.args))
+}
+
+// where compiles q into the conditions the rows of t have to meet. text is the placeholder of the query text, empty
+// when there is none.
+func (q *Query) where(t table, s *statement, scope Scope) (where string, text string) {
+	var conditions []string
+
+	account := s.arg(scope.AccountID)
+
+	// folders named by in: are looked for in the scope too
+	var lookup string
+
+	if scope.FolderPath != "" {
+		path := s.arg(scope.FolderPath)
+
+		conditions = append(conditions, fmt.Sprintf(t.inFolder, path))
+
+		lookup = fmt.Sprintf("folder.path <@ %s::ltree", path)
+	} else {
+		conditions = append(conditions, fmt.Sprintf(t.readable, account))
+
+		lookup = fmt.Sprintf(readableFolder, account)
+	}
+
+	if q.Text != "" {
+		text = s.arg(q.Text)
+
+		// titles close to the text match too so typos still find them
+		conditions = append(conditions, fmt.Sprintf("(%s @@ websearch_to_tsquery('english', %s::text) OR %s::text <%% %s)", t.document, text, text, t.title))
+	}
+
+	for _, excluded := range q.Excluded {
+		conditions = append(conditions, fmt.Sprintf("NOT (%s @@ phraseto_tsquery('english', %s::text))", t.document, s.arg(excluded)))
+	}
+
+	trashed := false
+
+	for _, f := range q.Filters {
+		condition := f.condition(t, s, lookup, account)
+
+		if f.Exclude {
+			condition = fmt.Sprintf("NOT (%s)", condition)
+		}
+
+		conditions = append(conditions, condition)
+
+		if f.Field == Is && f.Value == Trashed && !f.Exclude {
+			trashed = true
+		}
+	}
+
+	// the trash is left out unless it is asked for
+	if !trashed {
+		conditions = append(conditions, t.deleted+" IS NULL")
+	}
+
+	return strings.Join(conditions, " AND "), text
+}
+
+// condition is what the rows of t matching f meet, lookup limits the folders in: looks for to the scope. account is the
+// placeholder of the account searching, whose tags are the only ones tag: looks at.
+func (f Filter) condition(t table, s *statement, lookup, account string) string {
+	switch f.Field {
+	case Site:
+		if t.hostname == "" {
+			return "FALSE"
+		}
+
+		site := s.arg(f.Value)
+
+		return fmt.Sprintf("(lower(%s) = %s::text OR lower(%s) LIKE '%%.' || %s::text)", t.hostname, site, t.hostname, site)
+	case Tag:
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM %s AND tag.account_id = %s AND tag.tag_name = %s::text)", t.tags, account, s.arg(f.Value))
+	case In:
+		// folder names are not unique, the subtrees of every folder with the name are searched
+		return fmt.Sprintf("COALESCE(%s <@ ARRAY(SELECT path FROM folder WHERE %s AND folder.folder_name = %s::text), FALSE)", t.folderPath, lookup, s.arg(f.Value))
+	case Is:
+		if f.Value == Trashed {
+			return t.deleted + " IS NOT NULL"
+		}
+
+		if t.starred == "" {
+			return "FALSE"
+		}
+
+		return t.starred
+	case Before:
+		return fmt.Sprintf("%s < %s", t.created, s.arg(f.Date))
+	case After:
+		return fmt.Sprintf("%s >= %s", t.created, s.arg(f.Date.AddDate(0, 0, 1)))
+	}
+
+	return "FALSE"
+}
+
+// selectSQL is the page of rows of t matching q, best matches first
+func (q *Query) selectSQL(t table, scope Scope, limit, offset int32) (string, []interface{}) {
+	s := &statement{}
+
+	where, text := q.where(t, s, scope)
+
+	rank, headline, order := "0::real", "''", t.created+" DESC"
+
+	if text != "" {
+		query := fmt.Sprintf("websearch_to_tsquery('english', %s::text)", text)
+
+		rank = fmt.Sprintf("ts_rank(%s, %s)", t.document, query)
+		headline = fmt.Sprintf("ts_headline('english', %s, %s, '%s')", t.headline, query, headlineOptions)
+		order = fmt.Sprintf("rank DESC, word_similarity(%s::text, %s) DESC, %s", text, t.title, order)
+	}
+
+	sql := fmt.Sprintf("SELECT %s, %s AS rank, %s AS headline FROM %s WHERE %s ORDER BY %s LIMIT %s OFFSET %s",
+		t.columns, rank, headline, t.from, where, order, s.arg(limit), s.arg(offset))
+
+	return sql, s.args
+}
+
+// countSQL counts the rows of t matching q
+func (q *Query) countSQL(t table, scope Scope) (string, []interface{}) {
+	s := &statement{}
+
+	where, _ := q.where(t, s, scope)
+
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", t.from, where), s.args
+}
+
+// LinkResult is a link matching a query. Headline is the matching part of its title, notes and archived article,
+// escaped for html with the matched words wrapped in <b></b>. It is empty when the query has no text.
+type LinkResult struct {
+	sqlc.Link
+	Rank     float32
+	Headline string
+}
+
+type FolderResult struct {
+	sqlc.Folder
+	Rank     float32
+	Headline string
+}
+
+// Links returns a page of the links in scope matching q
+func (q *Query) Links(ctx context.Context, db sqlc.DBTX, scope Scope, limit, offset int32) ([]LinkResult, error) {
+	query, args := q.selectSQL(links, scope, limit, offset)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var items []LinkResult
+
+	for rows.Next() {
+		var i LinkResult
+
+		if err := rows.Scan(
+			&i.LinkID,
+			&i.LinkTitle,
+			&i.LinkThumbnail,
+			&i.LinkFavicon,
+			&i.LinkHostname,
+			&i.LinkUrl,
+			&i.LinkNotes,
+			&i.AccountID,
+			&i.FolderID,
+			&i.AddedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.MetadataStatus,
+			&i.Rank,
+			&i.Headline,
+		); err != nil {
+			return nil, err
+		}
+
+		i.Headline = highlight(i.Headline)
+
+		items = append(items, i)
+	}
+
+	return items, rows.Err()
+}
+
+// Folders returns a page of the folders in scope matching q
+func (q *Query) Folders(ctx context.Context, db sqlc.DBTX, scope Scope, limit, offset int32) ([]FolderResult, error) {
+	query, args := q.selectSQL(folders, scope, limit, offset)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var items []FolderResult
+
+	for rows.Next() {
+		var i FolderResult
+
+		if err := rows.Scan(
+			&i.FolderID,
+			&i.AccountID,
+			&i.FolderName,
+			&i.Path,
+			&i.Label,
+			&i.Starred,
+			&i.FolderCreatedAt,
+			&i.FolderUpdatedAt,
+			&i.SubfolderOf,
+			&i.FolderDeletedAt,
+			&i.Rank,
+			&i.Headline,
+		); err != nil {
+			return nil, err
+		}
+
+		i.Headline = highlight(i.Headline)
+
+		items = append(items, i)
+	}
+
+	return items, rows.Err()
+}
+
+func (q *Query) CountLinks(ctx context.Context, db sqlc.DBTX, scope Scope) (int64, error) {
+	query, args := q.countSQL(links, scope)
+
+	var count int64
+
+	err := db.QueryRowContext(ctx, query, args...).Scan(&count)
+
+	return count, err
+}
+
+func (q *Query) CountFolders(ctx context.Context, db sqlc.DBTX, scope Scope) (int64, error) {
+	query, args := q.countSQL(folders, scope)
+
+	var count int64
+
+	err := db.QueryRowContext(ctx, query, args...).Scan(&count)
+
+	return count, err
+}
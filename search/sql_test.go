@@ -0,0 +1,161 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/dbtest"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		headline string
+		want     string
+	}{
+		{"", ""},
+		{"no matches", "no matches"},
+		{"a " + startSel + "match" + stopSel + " here", "a <b>match</b> here"},
+		{startSel + "<script>" + stopSel + "alert(1)</script>", "<b>&lt;script&gt;</b>alert(1)&lt;/script&gt;"},
+		{`<img src=x onerror="alert(1)"> & <b>bold</b>`, "&lt;img src=x onerror=&#34;alert(1)&#34;&gt; &amp; &lt;b&gt;bold&lt;/b&gt;"},
+	}
+
+	for _, tt := range tests {
+		if got := highlight(tt.headline); got != tt.want {
+			t.Errorf("highlight(%q) = %q, want %q", tt.headline, got, tt.want)
+		}
+	}
+}
+
+func TestTagFilterOnlyMatchesTheSearchersTags(t *testing.T) {
+	query, err := Parse("tag:go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, scope := range []Scope{AccountScope(7), FolderScope(7, "abc.def")} {
+		for _, tb := range []table{links, folders} {
+			sql, args := query.countSQL(tb, scope)
+
+			if !strings.Contains(sql, "tag.account_id = $1 AND tag.tag_name") {
+				t.Errorf("countSQL(%s, %+v) = %q, want the tag filter limited to the account searching", tb.name, scope, sql)
+			}
+
+			if len(args) == 0 || args[0] != int64(7) {
+				t.Errorf("countSQL(%s, %+v) args = %v, want the account searching first", tb.name, scope, args)
+			}
+		}
+	}
+}
+
+// TestAccountScopePostgres checks an account searches what it can read, whoever added it, and nothing of the
+// collections it was removed from
+func TestAccountScopePostgres(t *testing.T) {
+	const (
+		ownerID = iota + 1
+		editorID
+		viewerID
+		strangerID
+	)
+
+	in := func(folderID string) sql.NullString {
+		return sql.NullString{String: folderID, Valid: true}
+	}
+
+	tx := dbtest.Collections{
+		Folders: []sqlc.Folder{
+			{FolderID: "root", AccountID: ownerID, FolderName: "Root", Path: "root", Label: "root"},
+			{FolderID: "child", AccountID: ownerID, FolderName: "Child", Path: "root.child", Label: "child", SubfolderOf: in("root")},
+			{FolderID: "other", AccountID: strangerID, FolderName: "Child", Path: "other", Label: "other"},
+		},
+		Links: []sqlc.Link{
+			{LinkID: "owner-link", AccountID: ownerID},
+			{LinkID: "editor-link", AccountID: editorID, FolderID: in("child")},
+			{LinkID: "stranger-link", AccountID: strangerID, FolderID: in("other")},
+			{LinkID: "stranger-unsorted-link", AccountID: strangerID},
+		},
+		Members: []sqlc.CollectionMember{
+			{CollectionID: "root", MemberID: editorID, CollectionAccessLevel: sqlc.CollectionAccessLevelEdit},
+			{CollectionID: "child", MemberID: viewerID, CollectionAccessLevel: sqlc.CollectionAccessLevelView},
+		},
+	}.Postgres(t)
+
+	ctx := context.Background()
+
+	search := func(t *testing.T, query string, accountID int64) (links, folders []string) {
+		t.Helper()
+
+		q, err := Parse(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		linkResults, err := q.Links(ctx, tx, AccountScope(accountID), 50, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, l := range linkResults {
+			links = append(links, l.LinkID)
+		}
+
+		folderResults, err := q.Folders(ctx, tx, AccountScope(accountID), 50, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, f := range folderResults {
+			folders = append(folders, f.FolderID)
+		}
+
+		sort.Strings(links)
+		sort.Strings(folders)
+
+		return links, folders
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		accountID   int64
+		wantLinks   []string
+		wantFolders []string
+	}{
+		{"owner finds what editors added", "", ownerID, []string{"editor-link", "owner-link"}, []string{"child", "root"}},
+		{"editor finds the collection but not the owner's unsorted links", "", editorID, []string{"editor-link"}, []string{"child", "root"}},
+		{"viewer of a subfolder", "", viewerID, []string{"editor-link"}, []string{"child"}},
+		{"stranger", "", strangerID, []string{"stranger-link", "stranger-unsorted-link"}, []string{"other"}},
+		{"in: looks at shared folders", "in:Child", viewerID, []string{"editor-link"}, []string{"child"}},
+		{"in: leaves out folders of the same name that are not readable", "in:Child", strangerID, []string{"stranger-link"}, []string{"other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, folders := search(t, tt.query, tt.accountID)
+
+			if !reflect.DeepEqual(links, tt.wantLinks) {
+				t.Errorf("links = %v, want %v", links, tt.wantLinks)
+			}
+
+			if !reflect.DeepEqual(folders, tt.wantFolders) {
+				t.Errorf("folders = %v, want %v", folders, tt.wantFolders)
+			}
+		})
+	}
+
+	t.Run("removed member", func(t *testing.T) {
+		if _, err := sqlc.New(tx).DeleteCollectionMember(ctx, sqlc.DeleteCollectionMemberParams{CollectionID: "child", MemberID: viewerID}); err != nil {
+			t.Fatal(err)
+		}
+
+		links, folders := search(t, "", viewerID)
+
+		if len(links) != 0 || len(folders) != 0 {
+			t.Errorf("got links %v and folders %v, want none", links, folders)
+		}
+	})
+}
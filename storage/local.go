@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultLocalStorageDir = "./uploads"
+	defaultLocalStorageURL = "/blobs"
+)
+
+// LocalStore keeps blobs on disk and serves them itself, localStorageUrl should be the public address it is mounted at
+type LocalStore struct {
+	dir       string
+	baseURL   string
+	mountPath string
+}
+
+func newLocalStore(dir, baseURL string) *LocalStore {
+	if dir == "" {
+		dir = defaultLocalStorageDir
+	}
+
+	if baseURL == "" {
+		baseURL = defaultLocalStorageURL
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		log.Panicf("invalid localStorageUrl %q: %v", baseURL, err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Panicf("could not create local storage directory: %v", err)
+	}
+
+	return &LocalStore{
+		dir:       dir,
+		baseURL:   baseURL,
+		mountPath: u.Path,
+	}
+}
+
+// MountPath is the router path the blobs have to be served under for URL to resolve
+func (s *LocalStore) MountPath() string {
+	return s.mountPath
+}
+
+// file maps a key to a path inside dir, keys that would escape it are rejected
+func (s *LocalStore) file(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+
+	if key == "" || cleaned == "/" || cleaned[1:] != key {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put ignores contentType, ServeHTTP sniffs it from the content when the blob is fetched
+func (s *LocalStore) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	name, err := s.file(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	// write next to the destination and rename so readers never see half written files
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.file(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	name, err := s.file(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *LocalStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, s.mountPath+"/")
+
+	name, err := s.file(key)
+	if err != nil || IsPrivate(key) {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+const (
+	defaultS3Endpoint = "https://ewr1.vultrobjects.com"
+	defaultS3Region   = "ewr"
+)
+
+type s3Config struct {
+	endpoint  string
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+}
+
+// s3Store works with any s3 compatible service (vultr, digital ocean spaces, minio, aws).
+// Without a bucket the first segment of every key is used as the bucket, which is how the vultr buckets are laid out.
+type s3Store struct {
+	client    *s3.S3
+	uploader  *s3manager.Uploader
+	bucket    string
+	publicURL string
+}
+
+func newS3Store(c s3Config) *s3Store {
+	if c.endpoint == "" {
+		c.endpoint = defaultS3Endpoint
+	}
+
+	if c.region == "" {
+		c.region = defaultS3Region
+	}
+
+	c.endpoint = strings.TrimSuffix(c.endpoint, "/")
+
+	if c.publicURL == "" {
+		c.publicURL = c.endpoint
+
+		if c.bucket != "" {
+			c.publicURL = c.endpoint + "/" + c.bucket
+		}
+	}
+
+	newSession, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(c.accessKey, c.secretKey, ""),
+		Endpoint:         aws.String(c.endpoint),
+		S3ForcePathStyle: aws.Bool(true),
+		Region:           aws.String(c.region),
+	})
+	if err != nil {
+		log.Panicf("could not create s3 session: %v", err)
+	}
+
+	client := s3.New(newSession)
+
+	return &s3Store{
+		client:    client,
+		uploader:  s3manager.NewUploaderWithClient(client),
+		bucket:    c.bucket,
+		publicURL: strings.TrimSuffix(c.publicURL, "/"),
+	}
+}
+
+func (s *s3Store) location(key string) (bucket, objectKey string, err error) {
+	if s.bucket != "" {
+		return s.bucket, key, nil
+	}
+
+	bucket, objectKey, found := strings.Cut(key, "/")
+	if !found || bucket == "" || objectKey == "" {
+		return "", "", fmt.Errorf("key %q does not start with a bucket", key)
+	}
+
+	return bucket, objectKey, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	bucket, objectKey, err := s.location(key)
+	if err != nil {
+		return err
+	}
+
+	input := &s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(objectKey),
+		Body:   body,
+		ACL:    aws.String("public-read"),
+	}
+
+	if IsPrivate(key) {
+		input.ACL = aws.String("private")
+	}
+
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+
+	_, err = s.uploader.UploadWithContext(ctx, input)
+
+	return err
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	bucket, objectKey, err := s.location(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(objectKey),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return out.Body, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	bucket, objectKey, err := s.location(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(objectKey),
+	})
+
+	return err
+}
+
+func (s *s3Store) URL(key string) string {
+	return s.publicURL + "/" + key
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// key prefixes, with the s3 backend and no s3Bucket configured they are the bucket names
+const (
+	LinkThumbnails = "link-thumbnails"
+	LinkFavicons   = "link-favicons"
+	AppAssets      = "app-assets"
+	// LinkArchives holds page snapshots, they are private
+	LinkArchives = "link-archives"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps the files linkspace serves to browsers: link thumbnails, favicons and hero images, and the
+// archived copies of links. Keys are slash separated, e.g. "link-favicons/<uuid>".
+// Private blobs have no public address, they can only be read with Get.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	// Get returns ErrNotFound when there is nothing stored under key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL is the public address of the blob stored under key, it does not resolve for private blobs
+	URL(key string) string
+}
+
+// IsPrivate reports whether the blob under key is only handed out by the api, after it checked who is asking
+func IsPrivate(key string) bool {
+	return strings.HasPrefix(key, LinkArchives+"/")
+}
+
+// NewBlobStore picks the backend set by blobStore ("s3" or "local"). When it is not set s3 is used if access keys are
+// configured and the local disk otherwise.
+func NewBlobStore() BlobStore {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Panicf("could not load config file: %v", err)
+	}
+
+	s3Config := s3Config{
+		endpoint:  config.S3Endpoint,
+		region:    config.S3Region,
+		bucket:    config.S3Bucket,
+		accessKey: config.S3AccessKey,
+		secretKey: config.S3SecretKey,
+		publicURL: config.S3PublicURL,
+	}
+
+	// deployments from before s3* settings existed only have the vultr keys
+	if s3Config.accessKey == "" {
+		s3Config.accessKey = config.VultrAccessKey
+		s3Config.secretKey = config.VultrSecretKey
+	}
+
+	backend := config.BlobStore
+	if backend == "" {
+		backend = "local"
+
+		if s3Config.accessKey != "" {
+			backend = "s3"
+		}
+	}
+
+	switch backend {
+	case "s3":
+		return newS3Store(s3Config)
+	case "local":
+		return newLocalStore(config.LocalStorageDir, config.LocalStorageURL)
+	default:
+		log.Panicf("unknown blob store %q, use s3 or local", backend)
+		return nil
+	}
+}
+
+// NewKey returns a unique key under prefix
+func NewKey(prefix string) string {
+	return prefix + "/" + uuid.NewString()
+}
+
+// KeyFromURL turns a url handed out by store.URL back into its key.
+// ok is false for urls the store did not hand out, e.g. google's favicon links.
+func KeyFromURL(store BlobStore, url string) (key string, ok bool) {
+	base := store.URL("")
+
+	if !strings.HasPrefix(url, base) {
+		return "", false
+	}
+
+	key = strings.TrimPrefix(url, base)
+
+	return key, key != ""
+}
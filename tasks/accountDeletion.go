@@ -0,0 +1,234 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/storage"
+)
+
+const AccountDeletionJob = "account:delete"
+
+// SharedCollectionsTransfer hands collections other accounts are members of to the member with the most access,
+// SharedCollectionsDelete deletes them with the account
+const (
+	SharedCollectionsTransfer = "transfer"
+	SharedCollectionsDelete   = "delete"
+)
+
+// how often accounts whose grace period is over are looked for
+const accountDeletionSweepInterval = time.Hour
+
+type accountDeletionPayload struct {
+	AccountID int64 `json:"account_id"`
+}
+
+// RegisterAccountDeletionHandler deletes accounts once the grace period after a confirmed deletion is over
+func RegisterAccountDeletionHandler(q Queue, db *sql.DB, store storage.BlobStore) {
+	q.Handle(AccountDeletionJob, func(ctx context.Context, job *Job) error {
+		var payload accountDeletionPayload
+
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: could not decode payload: %v", ErrPermanent, err)
+		}
+
+		return deleteAccount(ctx, db, store, payload.AccountID)
+	})
+}
+
+// SweepAccountDeletions queues the deletion of every account that is due, straight away and then every hour until ctx
+// is cancelled. Deleting an account twice does nothing, so several instances sweeping at once is fine.
+func SweepAccountDeletions(ctx context.Context, q Queue, db *sql.DB) {
+	sweep := func() {
+		due, err := sqlc.New(db).GetDueAccountDeletions(ctx)
+		if err != nil {
+			log.Printf("could not get due account deletions at accountDeletion.go: %v", err)
+			return
+		}
+
+		for _, deletion := range due {
+			if err := q.Enqueue(ctx, AccountDeletionJob, accountDeletionPayload{AccountID: deletion.AccountID}); err != nil {
+				log.Printf("could not queue deletion of account %d at accountDeletion.go: %v", deletion.AccountID, err)
+			}
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(accountDeletionSweepInterval)
+		defer ticker.Stop()
+
+		sweep()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sweep()
+			}
+		}
+	}()
+}
+
+// deleteAccount deletes an account with everything it owns. Collections other accounts are members of are handed
+// over or deleted as the account asked, folders and links it added to other people's collections stay there and
+// belong to the collection owner from then on.
+func deleteAccount(ctx context.Context, db *sql.DB, store storage.BlobStore, accountID int64) error {
+	q := sqlc.New(db)
+
+	deletion, err := q.GetAccountDeletion(ctx, accountID)
+	if err != nil {
+		// cancelled, or deleted by an earlier run
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	}
+
+	if !deletion.DeleteAfter.Valid || time.Now().Before(deletion.DeleteAfter.Time) {
+		return nil
+	}
+
+	account, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	qtx := q.WithTx(tx)
+
+	if deletion.SharedCollections == SharedCollectionsTransfer {
+		roots, err := qtx.GetSharedRootFolders(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, root := range roots {
+			if err := transferCollection(ctx, qtx, root, account.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := qtx.ReassignContributedFolders(ctx, account.ID); err != nil {
+		return err
+	}
+
+	if err := qtx.ReassignContributedLinks(ctx, account.ID); err != nil {
+		return err
+	}
+
+	// everything the account still owns goes with it, the blobs of its links are deleted once the rows are
+	links, err := qtx.GetLinksDeletedWithAccount(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+
+	archives, err := qtx.GetLinkArchivesDeletedWithAccount(ctx, account.ID)
+	if err != nil {
+		return err
+	}
+
+	// account_session and contact do not cascade
+	if err := qtx.DeleteAccountSessions(ctx, account.ID); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteAccountSupportMessages(ctx, account.ID); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteAccountInvites(ctx, account.Email); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteEmailVerificationCode(ctx, account.Email); err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteAccount(ctx, account.ID); err != nil {
+		return err
+	}
+
+	// invites are not tied to their collection, the ones to collections deleted with the account are left over
+	if err := qtx.DeleteOrphanedInvites(ctx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		// favicons google could not give us are linked to rather than stored, those urls are not ours to delete
+		for _, blobURL := range []string{link.LinkThumbnail, link.LinkFavicon} {
+			key, ok := storage.KeyFromURL(store, blobURL)
+			if !ok {
+				continue
+			}
+
+			if err := store.Delete(ctx, key); err != nil {
+				log.Printf("could not delete blob %s at accountDeletion.go: %v", key, err)
+			}
+		}
+	}
+
+	for _, linkArchive := range archives {
+		deleteBlobs(ctx, store, linkArchive.SnapshotKey, linkArchive.ArticleKey)
+	}
+
+	log.Printf("deleted account %d with %d links", account.ID, len(links))
+
+	return nil
+}
+
+// transferCollection hands a collection to the member with the most access, the longest standing one when several
+// have the same. A collection with no member left to take it is deleted with the account.
+func transferCollection(ctx context.Context, q *sqlc.Queries, root sqlc.Folder, accountID int64) error {
+	owner, err := q.GetNewCollectionOwner(ctx, sqlc.GetNewCollectionOwnerParams{
+		FolderID:  root.FolderID,
+		AccountID: accountID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	}
+
+	if err := q.TransferPublicLinks(ctx, sqlc.TransferPublicLinksParams{
+		NewOwnerID: owner,
+		OldOwnerID: accountID,
+		FolderID:   root.FolderID,
+	}); err != nil {
+		return err
+	}
+
+	// the new owner does not need a membership of its own collection
+	if err := q.DeleteCollectionTreeMember(ctx, sqlc.DeleteCollectionTreeMemberParams{
+		MemberID: owner,
+		FolderID: root.FolderID,
+	}); err != nil {
+		return err
+	}
+
+	return q.TransferCollection(ctx, sqlc.TransferCollectionParams{
+		NewOwnerID: owner,
+		OldOwnerID: accountID,
+		FolderID:   root.FolderID,
+	})
+}
@@ -0,0 +1,193 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/kwandapchumba/go-bookmark-manager/archive"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/storage"
+)
+
+const LinkArchiveJob = "link:archive"
+
+const (
+	// snapshotTimeout bounds downloading the assets of a page, the ones left when it runs out stay linked to
+	snapshotTimeout = 3 * time.Minute
+	// maxArticleIndexLength is how much of an article is searchable, the stored copy has all of it
+	maxArticleIndexLength = 100000
+)
+
+type linkArchivePayload struct {
+	LinkID string `json:"link_id"`
+}
+
+// RequestLinkArchive marks the archive of a link pending and queues archiving it on its own, the snapshot it has is
+// kept until the new one is taken. Links that were just added or pointed at another page are archived along with
+// their metadata by EnqueueLinkPage instead.
+func RequestLinkArchive(ctx context.Context, q Queue, queries *sqlc.Queries, linkID string) (sqlc.LinkArchive, error) {
+	linkArchive, err := queries.RequestLinkArchive(ctx, linkID)
+	if err != nil {
+		return sqlc.LinkArchive{}, err
+	}
+
+	return linkArchive, q.Enqueue(ctx, LinkArchiveJob, linkArchivePayload{LinkID: linkID})
+}
+
+// RegisterLinkArchiveHandler snapshots the page of links whose archive was requested. The archive is marked "failed"
+// once the last attempt fails.
+func RegisterLinkArchiveHandler(q Queue, db *sql.DB, store storage.BlobStore) {
+	client := archive.NewClient()
+
+	q.Handle(LinkArchiveJob, func(ctx context.Context, job *Job) error {
+		var payload linkArchivePayload
+
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: could not decode payload: %v", ErrPermanent, err)
+		}
+
+		queries := sqlc.New(db)
+
+		err := archiveLink(ctx, queries, store, client, payload.LinkID)
+		if err != nil && (job.LastAttempt() || errors.Is(err, ErrPermanent)) {
+			if err := queries.SetLinkArchiveStatus(ctx, sqlc.SetLinkArchiveStatusParams{
+				ArchiveStatus: sqlc.LinkArchiveStatusFailed,
+				LinkID:        payload.LinkID,
+			}); err != nil {
+				log.Printf("could not mark link archive as failed at linkArchive.go: %v", err)
+			}
+		}
+
+		return err
+	})
+}
+
+func archiveLink(ctx context.Context, q *sqlc.Queries, store storage.BlobStore, client *http.Client, linkID string) error {
+	link, err := q.GetLink(ctx, linkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: link %s no longer exists", ErrPermanent, linkID)
+		}
+		return err
+	}
+
+	var renderedURL, page string
+
+	err = openPage(ctx, pageURL(link.LinkUrl), func(p *rod.Page) error {
+		renderedURL, page, err = pageHTML(p)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return storeLinkArchive(ctx, q, store, client, link.LinkID, renderedURL, page)
+}
+
+// pageHTML is the html of a loaded page and the url it was rendered at, which relative links in it are relative to
+func pageHTML(p *rod.Page) (string, string, error) {
+	info, err := p.Info()
+	if err != nil {
+		return "", "", err
+	}
+
+	page, err := p.HTML()
+	if err != nil {
+		return "", "", err
+	}
+
+	return info.URL, page, nil
+}
+
+// storeLinkArchive snapshots a loaded page and extracts its article, and replaces the archive of the link with them
+func storeLinkArchive(ctx context.Context, q *sqlc.Queries, store storage.BlobStore, client *http.Client, linkID, renderedURL, page string) error {
+	previous, err := q.GetLinkArchive(ctx, linkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: archiving link %s was not requested", ErrPermanent, linkID)
+		}
+		return err
+	}
+
+	snapshotCtx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+
+	defer cancel()
+
+	snapshot, err := archive.Snapshot(snapshotCtx, client, renderedURL, page)
+	if err != nil {
+		return fmt.Errorf("could not snapshot page: %w", err)
+	}
+
+	article, err := archive.Article(page)
+	if err != nil {
+		return fmt.Errorf("could not extract article: %w", err)
+	}
+
+	snapshotKey := storage.NewKey(storage.LinkArchives)
+	articleKey := storage.NewKey(storage.LinkArchives)
+
+	if err := store.Put(ctx, snapshotKey, bytes.NewReader(snapshot), "text/html; charset=utf-8"); err != nil {
+		return fmt.Errorf("could not store snapshot: %w", err)
+	}
+
+	if err := store.Put(ctx, articleKey, strings.NewReader(article), "text/plain; charset=utf-8"); err != nil {
+		deleteBlobs(ctx, store, snapshotKey)
+		return fmt.Errorf("could not store article: %w", err)
+	}
+
+	_, err = q.SetLinkArchive(ctx, sqlc.SetLinkArchiveParams{
+		SnapshotKey: snapshotKey,
+		ArticleKey:  articleKey,
+		ArticleText: truncateRunes(article, maxArticleIndexLength),
+		LinkID:      linkID,
+	})
+	if err != nil {
+		deleteBlobs(ctx, store, snapshotKey, articleKey)
+
+		// the link was deleted while we were archiving it
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return err
+	}
+
+	deleteBlobs(ctx, store, previous.SnapshotKey, previous.ArticleKey)
+
+	return nil
+}
+
+// deleteBlobs deletes the blobs under keys, empty keys are skipped
+func deleteBlobs(ctx context.Context, store storage.BlobStore, keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("could not delete blob %s at linkArchive.go: %v", key, err)
+		}
+	}
+}
+
+func truncateRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+
+		n--
+	}
+
+	return s
+}
@@ -0,0 +1,335 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/kwandapchumba/go-bookmark-manager/archive"
+	"github.com/kwandapchumba/go-bookmark-manager/db/sqlc"
+	"github.com/kwandapchumba/go-bookmark-manager/storage"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+const LinkMetadataJob = "link:metadata"
+
+const (
+	pageLoadTimeout = time.Minute
+	maxFaviconSize  = 1 << 20
+)
+
+type linkMetadataPayload struct {
+	LinkID string `json:"link_id"`
+	// Archive is set when archiving the link was requested along with its metadata
+	Archive bool `json:"archive,omitempty"`
+}
+
+// EnqueueLinkPage queues fetching the metadata of a link that was just added or pointed at another page and archiving
+// it, both from one load of the page. The archive is marked pending straight away, the snapshot it has is kept until
+// the new one is taken. Failing to request the archive only loses the archive so it is logged rather than returned.
+func EnqueueLinkPage(ctx context.Context, q Queue, queries *sqlc.Queries, linkID string) error {
+	_, err := queries.RequestLinkArchive(ctx, linkID)
+	if err != nil {
+		log.Printf("could not request link archive at linkMetadata.go: %v", err)
+	}
+
+	return q.Enqueue(ctx, LinkMetadataJob, linkMetadataPayload{LinkID: linkID, Archive: err == nil})
+}
+
+// RegisterLinkMetadataHandler fetches the title, favicon and thumbnail of links added with a "pending" metadata status,
+// and archives the page from the same load when that was requested. The link is marked "failed" once the last attempt
+// fails. Archiving from the load that got the metadata failing leaves it to a job of its own.
+func RegisterLinkMetadataHandler(q Queue, db *sql.DB, store storage.BlobStore) {
+	client := archive.NewClient()
+
+	q.Handle(LinkMetadataJob, func(ctx context.Context, job *Job) error {
+		var payload linkMetadataPayload
+
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: could not decode payload: %v", ErrPermanent, err)
+		}
+
+		queries := sqlc.New(db)
+
+		capture, err := fetchLinkMetadata(ctx, queries, store, payload.LinkID, payload.Archive)
+		if err != nil {
+			if job.LastAttempt() || errors.Is(err, ErrPermanent) {
+				if err := queries.SetLinkMetadataStatus(ctx, sqlc.SetLinkMetadataStatusParams{
+					MetadataStatus: sqlc.LinkMetadataStatusFailed,
+					LinkID:         payload.LinkID,
+				}); err != nil {
+					log.Printf("could not mark link metadata as failed at linkMetadata.go: %v", err)
+				}
+
+				if payload.Archive {
+					if err := queries.SetLinkArchiveStatus(ctx, sqlc.SetLinkArchiveStatusParams{
+						ArchiveStatus: sqlc.LinkArchiveStatusFailed,
+						LinkID:        payload.LinkID,
+					}); err != nil {
+						log.Printf("could not mark link archive as failed at linkMetadata.go: %v", err)
+					}
+				}
+			}
+
+			return err
+		}
+
+		// nothing was captured for a link that changed while its page was loading, its next job archives it
+		if capture == nil || !payload.Archive {
+			return nil
+		}
+
+		if err := storeLinkArchive(ctx, queries, store, client, payload.LinkID, capture.renderedURL, capture.html); err != nil {
+			log.Printf("could not archive link %s with its metadata at linkMetadata.go, archiving it on its own: %v", payload.LinkID, err)
+
+			if err := q.Enqueue(ctx, LinkArchiveJob, linkArchivePayload{LinkID: payload.LinkID}); err != nil {
+				log.Printf("could not enqueue link archive job at linkMetadata.go: %v", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// pageCapture is what one load of the page of a link gives
+type pageCapture struct {
+	title      string
+	screenshot []byte
+	// only captured when the page is archived as well
+	renderedURL string
+	html        string
+}
+
+// fetchLinkMetadata saves the metadata of a link and returns what was captured of its page, nil when the link changed
+// while its page was loading and the metadata was thrown away
+func fetchLinkMetadata(ctx context.Context, q *sqlc.Queries, store storage.BlobStore, linkID string, withHTML bool) (*pageCapture, error) {
+	link, err := q.GetLink(ctx, linkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: link %s no longer exists", ErrPermanent, linkID)
+		}
+		return nil, err
+	}
+
+	favicon, err := fetchFavicon(ctx, store, link.LinkUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	capture, err := capturePage(ctx, pageURL(link.LinkUrl), withHTML)
+	if err != nil {
+		deleteStoredBlobs(ctx, store, favicon)
+		return nil, err
+	}
+
+	thumbnailKey := storage.NewKey(storage.LinkThumbnails)
+
+	if err := store.Put(ctx, thumbnailKey, bytes.NewReader(capture.screenshot), "image/png"); err != nil {
+		deleteStoredBlobs(ctx, store, favicon)
+		return nil, fmt.Errorf("could not store thumbnail: %w", err)
+	}
+
+	title := capture.title
+	if title == "" {
+		title = link.LinkUrl
+	}
+
+	// only if nothing changed the link while we were fetching, otherwise what we fetched is stale and the blobs we read
+	// are someone else's to delete
+	_, err = q.UpdateLinkMetadata(ctx, sqlc.UpdateLinkMetadataParams{
+		LinkTitle:     title,
+		LinkFavicon:   favicon,
+		LinkThumbnail: store.URL(thumbnailKey),
+		LinkID:        link.LinkID,
+		FetchedUrl:    link.LinkUrl,
+		OldThumbnail:  link.LinkThumbnail,
+		OldFavicon:    link.LinkFavicon,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// the link was deleted, pointed at another page or given metadata by another job
+		deleteStoredBlobs(ctx, store, store.URL(thumbnailKey), favicon)
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a link whose url was changed still has the thumbnail and favicon of the old page
+	deleteStoredBlobs(ctx, store, link.LinkThumbnail, link.LinkFavicon)
+
+	return &capture, nil
+}
+
+// deleteStoredBlobs deletes the blobs behind urls, urls of anything not in the store such as google's favicons are
+// skipped
+func deleteStoredBlobs(ctx context.Context, store storage.BlobStore, blobURLs ...string) {
+	for _, blobURL := range blobURLs {
+		key, ok := storage.KeyFromURL(store, blobURL)
+		if !ok {
+			continue
+		}
+
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("could not delete blob %s at linkMetadata.go: %v", key, err)
+		}
+	}
+}
+
+// pageURL mirrors what AddLink used to open: urls without a scheme are opened over https
+func pageURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err == nil && (u.Scheme == "https" || u.Scheme == "http") {
+		return rawURL
+	}
+
+	return fmt.Sprintf(`https://%s`, rawURL)
+}
+
+func fetchFavicon(ctx context.Context, store storage.BlobStore, rawURL string) (string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	resp, err := client.Get(fmt.Sprintf("https://www.google.com/s2/favicons?domain=%v&sz=64", rawURL))
+	if err != nil {
+		return "", err
+	}
+
+	resp.Body.Close()
+
+	location := resp.Header.Get("content-location")
+
+	// fall back to linking google's copy when we cannot download it
+	icon, contentType, err := download(client, location)
+	if err != nil {
+		return location, nil
+	}
+
+	key := storage.NewKey(storage.LinkFavicons)
+
+	if err := store.Put(ctx, key, bytes.NewReader(icon), contentType); err != nil {
+		return "", fmt.Errorf("could not store favicon: %w", err)
+	}
+
+	return store.URL(key), nil
+}
+
+// download reads a small file such as a favicon into memory
+func download(client *http.Client, rawURL string) ([]byte, string, error) {
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("received %d response code", resp.StatusCode)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxFaviconSize))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return b, resp.Header.Get("Content-Type"), nil
+}
+
+// openPage loads pageURL in a browser of its own and hands the loaded page to fn, the browser is closed once fn
+// returns
+func openPage(ctx context.Context, pageURL string, fn func(page *rod.Page) error) error {
+	// every job gets its own browser profile so concurrent workers do not share one
+	dir, err := os.MkdirTemp("", "link-page-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(dir)
+
+	l := launcher.New().Context(ctx).UserDataDir(filepath.Join(dir, "chrome")).Leakless(true).NoSandbox(true).Headless(true)
+
+	controlURL, err := l.Launch()
+	if err != nil {
+		return err
+	}
+
+	defer l.Kill()
+
+	browser := rod.New().Context(ctx).ControlURL(controlURL)
+
+	if err := browser.Connect(); err != nil {
+		return err
+	}
+
+	defer browser.Close()
+
+	page, err := browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return err
+	}
+
+	page = page.Timeout(pageLoadTimeout)
+
+	if err := page.Navigate(pageURL); err != nil {
+		return err
+	}
+
+	if err := page.WaitLoad(); err != nil {
+		return err
+	}
+
+	return fn(page)
+}
+
+// capturePage loads a page once for its title and screenshot, and its html when withHTML is set
+func capturePage(ctx context.Context, pageURL string, withHTML bool) (pageCapture, error) {
+	var capture pageCapture
+
+	err := openPage(ctx, pageURL, func(page *rod.Page) error {
+		urlTitleChan := make(chan string, 1)
+
+		urlHeadingChan := make(chan string, 1)
+
+		util.GetUrlTitle(page, urlTitleChan)
+
+		util.GetUrlHeading(page, urlHeadingChan)
+
+		screenshot, err := page.Screenshot(false, nil)
+		if err != nil {
+			return err
+		}
+
+		capture.screenshot = screenshot
+		capture.title = pickTitle(strings.TrimSpace(<-urlTitleChan), strings.TrimSpace(<-urlHeadingChan))
+
+		if withHTML {
+			capture.renderedURL, capture.html, err = pageHTML(page)
+		}
+
+		return err
+	})
+
+	return capture, err
+}
+
+// pickTitle prefers the longer of the page title and its first heading
+func pickTitle(title, heading string) string {
+	if len(heading) > len(title) {
+		return heading
+	}
+
+	return title
+}
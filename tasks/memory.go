@@ -0,0 +1,149 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// memoryQueue keeps jobs in process, they are lost on restart and links stay "pending" until re-enqueued
+type memoryQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	jobs     []*Job
+	handlers map[string]HandlerFunc
+	workers  int
+	stopped  bool
+}
+
+func newMemoryQueue(workers int) *memoryQueue {
+	q := &memoryQueue{
+		handlers: make(map[string]HandlerFunc),
+		workers:  workers,
+	}
+
+	q.cond = sync.NewCond(&q.mu)
+
+	return q
+}
+
+func (q *memoryQueue) Handle(kind string, handler HandlerFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.handlers[kind] = handler
+}
+
+func (q *memoryQueue) Enqueue(ctx context.Context, kind string, payload interface{}) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	q.push(&Job{Kind: kind, Payload: b, Attempt: 1, MaxAttempts: maxAttempts})
+
+	return nil
+}
+
+func (q *memoryQueue) push(job *Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stopped {
+		return
+	}
+
+	q.jobs = append(q.jobs, job)
+
+	q.cond.Signal()
+}
+
+func (q *memoryQueue) pop() (*Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.jobs) == 0 && !q.stopped {
+		q.cond.Wait()
+	}
+
+	if q.stopped {
+		return nil, false
+	}
+
+	job := q.jobs[0]
+
+	q.jobs = q.jobs[1:]
+
+	return job, true
+}
+
+func (q *memoryQueue) Start(ctx context.Context) error {
+	for i := 0; i < q.workers; i++ {
+		go func() {
+			for {
+				job, ok := q.pop()
+				if !ok {
+					return
+				}
+
+				q.run(ctx, job)
+			}
+		}()
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		q.mu.Lock()
+		q.stopped = true
+		q.mu.Unlock()
+
+		q.cond.Broadcast()
+	}()
+
+	return nil
+}
+
+func (q *memoryQueue) run(ctx context.Context, job *Job) {
+	q.mu.Lock()
+	handler, ok := q.handlers[job.Kind]
+	q.mu.Unlock()
+
+	if !ok {
+		log.Printf("no handler registered for job kind %s", job.Kind)
+		return
+	}
+
+	err := safeRun(ctx, handler, job)
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, ErrPermanent) || job.LastAttempt() {
+		log.Printf("giving up on %s job after %d attempts: %v", job.Kind, job.Attempt, err)
+		return
+	}
+
+	delay := backoff(job.Attempt)
+
+	log.Printf("%s job failed on attempt %d, retrying in %v: %v", job.Kind, job.Attempt, delay, err)
+
+	job.Attempt++
+
+	time.AfterFunc(delay, func() { q.push(job) })
+}
+
+// safeRun turns panics from the rod Must* helpers into errors so a bad page cannot kill a worker
+func safeRun(ctx context.Context, handler HandlerFunc, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, job)
+}
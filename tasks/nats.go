@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/choria-io/asyncjobs"
+	"github.com/nats-io/nats.go"
+)
+
+const natsQueueName = "LINKSPACE"
+
+// natsQueue hands jobs to choria asyncjobs so they survive restarts and are shared between instances
+type natsQueue struct {
+	client   *asyncjobs.Client
+	handlers map[string]HandlerFunc
+}
+
+func newNatsQueue(url string, workers int) *natsQueue {
+	nc, err := nats.Connect(url, nats.UseOldRequestStyle())
+	if err != nil {
+		log.Panicf("could not connect to nats: %v", err)
+	}
+
+	client, err := asyncjobs.NewClient(
+		asyncjobs.NatsConn(nc),
+		asyncjobs.WorkQueue(&asyncjobs.Queue{Name: natsQueueName, MaxTries: maxAttempts, MaxRunTime: 5 * time.Minute}),
+		asyncjobs.ClientConcurrency(workers),
+		asyncjobs.RetryBackoffPolicyName("1m"),
+	)
+	if err != nil {
+		log.Panicf("could not create asyncjobs client: %v", err)
+	}
+
+	return &natsQueue{
+		client:   client,
+		handlers: make(map[string]HandlerFunc),
+	}
+}
+
+func (q *natsQueue) Handle(kind string, handler HandlerFunc) {
+	q.handlers[kind] = handler
+}
+
+func (q *natsQueue) Enqueue(ctx context.Context, kind string, payload interface{}) error {
+	task, err := asyncjobs.NewTask(kind, payload, asyncjobs.TaskMaxTries(maxAttempts))
+	if err != nil {
+		return err
+	}
+
+	return q.client.EnqueueTask(ctx, task)
+}
+
+func (q *natsQueue) Start(ctx context.Context) error {
+	router := asyncjobs.NewTaskRouter()
+
+	for kind, handler := range q.handlers {
+		handler := handler
+
+		err := router.HandleFunc(kind, func(ctx context.Context, _ asyncjobs.Logger, t *asyncjobs.Task) (any, error) {
+			job := &Job{Kind: t.Type, Payload: t.Payload, Attempt: t.Tries, MaxAttempts: maxAttempts}
+
+			if err := safeRun(ctx, handler, job); err != nil {
+				if errors.Is(err, ErrPermanent) {
+					return nil, fmt.Errorf("%w: %v", asyncjobs.ErrTerminateTask, err)
+				}
+
+				return nil, err
+			}
+
+			return nil, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		if err := q.client.Run(ctx, router); err != nil {
+			log.Printf("asyncjobs client stopped: %v", err)
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+const (
+	defaultWorkerCount = 4
+	maxAttempts        = 5
+)
+
+// ErrPermanent tells the queue not to retry a job, e.g. because the link it was for has been deleted
+var ErrPermanent = errors.New("permanent job failure")
+
+type Job struct {
+	Kind        string
+	Payload     []byte
+	Attempt     int
+	MaxAttempts int
+}
+
+// LastAttempt is true when a failure of this run will not be retried
+func (j *Job) LastAttempt() bool {
+	return j.Attempt >= j.MaxAttempts
+}
+
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+type Queue interface {
+	Enqueue(ctx context.Context, kind string, payload interface{}) error
+	Handle(kind string, handler HandlerFunc)
+	// Start runs the workers until ctx is cancelled, it does not block
+	Start(ctx context.Context) error
+}
+
+// NewQueue returns a NATS JetStream backed queue when natsUrl is configured and an in-process one otherwise
+func NewQueue() Queue {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Panicf("could not load config file: %v", err)
+	}
+
+	workers := config.WorkerCount
+	if workers <= 0 {
+		workers = defaultWorkerCount
+	}
+
+	if config.NatsURL != "" {
+		return newNatsQueue(config.NatsURL, workers)
+	}
+
+	return newMemoryQueue(workers)
+}
+
+// backoff doubles from 5 seconds up to 5 minutes with some jitter so retries of failing sites do not line up
+func backoff(attempt int) time.Duration {
+	d := 5 * time.Second << uint(attempt-1)
+
+	if d > 5*time.Minute || d <= 0 {
+		d = 5 * time.Minute
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
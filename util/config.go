@@ -22,6 +22,30 @@ type Config struct {
 	VultrAccessKey         string        `mapstructure:"vultrAccessKey"`
 	VultrSecretKey         string        `mapstructure:"vultrSecretKey"`
 	VultrHostname          string        `mapstructure:"vultrHostname"`
+	BlobStore              string        `mapstructure:"blobStore"`
+	S3Endpoint             string        `mapstructure:"s3Endpoint"`
+	S3Region               string        `mapstructure:"s3Region"`
+	S3Bucket               string        `mapstructure:"s3Bucket"`
+	S3AccessKey            string        `mapstructure:"s3AccessKey"`
+	S3SecretKey            string        `mapstructure:"s3SecretKey"`
+	S3PublicURL            string        `mapstructure:"s3PublicUrl"`
+	LocalStorageDir        string        `mapstructure:"localStorageDir"`
+	LocalStorageURL        string        `mapstructure:"localStorageUrl"`
+	NatsURL                string        `mapstructure:"natsUrl"`
+	WorkerCount            int           `mapstructure:"workerCount"`
+	GoogleClientID         string        `mapstructure:"googleClientId"`
+	GoogleJWKSURL          string        `mapstructure:"googleJwksUrl"`
+	GoogleClientSecret     string        `mapstructure:"googleClientSecret"`
+	GitHubClientID         string        `mapstructure:"githubClientId"`
+	GitHubClientSecret     string        `mapstructure:"githubClientSecret"`
+	KeycloakIssuerURL      string        `mapstructure:"keycloakIssuerUrl"`
+	KeycloakClientID       string        `mapstructure:"keycloakClientId"`
+	KeycloakClientSecret   string        `mapstructure:"keycloakClientSecret"`
+	APIURL                 string        `mapstructure:"apiUrl"`
+	FrontendURL            string        `mapstructure:"frontendUrl"`
+	WebAuthnRPID           string        `mapstructure:"webauthnRpId"`
+	RateLimitStore         string        `mapstructure:"rateLimitStore"`
+	TrustedProxies         string        `mapstructure:"trustedProxies"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
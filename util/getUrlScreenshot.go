@@ -1,9 +0,0 @@
-package util
-
-import (
-	"github.com/go-rod/rod"
-)
-
-func RodGetUrlScreenshot(page *rod.Page) {
-	page.MustScreenshot("a.png")
-}
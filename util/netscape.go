@@ -0,0 +1,237 @@
+package util
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+type NetscapeBookmark struct {
+	Title   string
+	URL     string
+	Notes   string
+	AddDate time.Time
+}
+
+type NetscapeFolder struct {
+	Name         string
+	AddDate      time.Time
+	LastModified time.Time
+	Folders      []*NetscapeFolder
+	Bookmarks    []*NetscapeBookmark
+}
+
+var ErrNoBookmarksFound = errors.New("no bookmarks or folders found in file")
+
+// ParseNetscapeBookmarks reads a bookmarks.html file as exported by Chrome, Firefox, Safari etc.
+// The returned folder is a nameless root holding the top level folders and bookmarks.
+//
+// The format is not valid html (<DT> and <p> are never closed) so it is read token by token:
+// an <H3> names a folder, the <DL> that follows holds its children and </DL> closes it.
+func ParseNetscapeBookmarks(r io.Reader) (*NetscapeFolder, error) {
+	root := &NetscapeFolder{}
+
+	stack := []*NetscapeFolder{}
+
+	current := func() *NetscapeFolder {
+		if len(stack) == 0 {
+			return root
+		}
+		return stack[len(stack)-1]
+	}
+
+	var (
+		pendingFolder *NetscapeFolder
+		folder        *NetscapeFolder
+		bookmark      *NetscapeBookmark
+		lastBookmark  *NetscapeBookmark
+		inDescription bool
+		text          strings.Builder
+	)
+
+	z := html.NewTokenizer(r)
+
+	for {
+		tt := z.Next()
+
+		switch tt {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				if len(root.Folders) == 0 && len(root.Bookmarks) == 0 {
+					return nil, ErrNoBookmarksFound
+				}
+				return root, nil
+			}
+			return nil, z.Err()
+
+		case html.TextToken:
+			if folder != nil || bookmark != nil || inDescription {
+				text.Write(z.Text())
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+
+			attrs := map[string]string{}
+
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				attrs[strings.ToLower(string(key))] = string(val)
+			}
+
+			switch string(name) {
+			case "h3":
+				inDescription = false
+				text.Reset()
+				folder = &NetscapeFolder{
+					AddDate:      unixAttr(attrs["add_date"]),
+					LastModified: unixAttr(attrs["last_modified"]),
+				}
+			case "a":
+				inDescription = false
+				text.Reset()
+				bookmark = &NetscapeBookmark{
+					URL:     strings.TrimSpace(attrs["href"]),
+					AddDate: unixAttr(attrs["add_date"]),
+				}
+			case "dd":
+				text.Reset()
+				inDescription = lastBookmark != nil
+			case "dt":
+				if inDescription {
+					lastBookmark.Notes = strings.TrimSpace(text.String())
+					inDescription = false
+				}
+			case "dl":
+				if inDescription {
+					lastBookmark.Notes = strings.TrimSpace(text.String())
+					inDescription = false
+				}
+
+				if pendingFolder != nil {
+					stack = append(stack, pendingFolder)
+					pendingFolder = nil
+				} else {
+					// the outermost <DL> (or a stray one) belongs to whatever folder we are already in
+					stack = append(stack, current())
+				}
+
+				lastBookmark = nil
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+
+			switch string(name) {
+			case "h3":
+				if folder == nil {
+					continue
+				}
+
+				folder.Name = strings.TrimSpace(text.String())
+
+				current().Folders = append(current().Folders, folder)
+
+				pendingFolder = folder
+				folder = nil
+				lastBookmark = nil
+			case "a":
+				if bookmark == nil {
+					continue
+				}
+
+				bookmark.Title = strings.TrimSpace(text.String())
+
+				current().Bookmarks = append(current().Bookmarks, bookmark)
+
+				lastBookmark = bookmark
+				bookmark = nil
+			case "dl":
+				if inDescription {
+					lastBookmark.Notes = strings.TrimSpace(text.String())
+					inDescription = false
+				}
+
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+
+				pendingFolder = nil
+				lastBookmark = nil
+			}
+		}
+	}
+}
+
+func unixAttr(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+
+	// firefox writes microseconds in some versions, chrome and edge exporters milliseconds
+	switch {
+	case seconds > 1e15:
+		seconds = seconds / 1e6
+	case seconds > 1e12:
+		seconds = seconds / 1e3
+	}
+
+	return time.Unix(seconds, 0).UTC()
+}
+
+// WriteNetscapeBookmarks writes root in the same format ParseNetscapeBookmarks reads, so exports can be imported
+// back into browsers and into this app
+func WriteNetscapeBookmarks(w io.Writer, root *NetscapeFolder) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprint(bw, "<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
+	fmt.Fprint(bw, "<!-- This is an automatically generated file.\n     It will be read and overwritten.\n     DO NOT EDIT! -->\n")
+	fmt.Fprint(bw, `<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">`+"\n")
+	fmt.Fprint(bw, "<TITLE>Bookmarks</TITLE>\n<H1>Bookmarks</H1>\n")
+
+	writeNetscapeFolder(bw, root, 0)
+
+	return bw.Flush()
+}
+
+func writeNetscapeFolder(w *bufio.Writer, f *NetscapeFolder, depth int) {
+	indent := strings.Repeat("    ", depth)
+
+	fmt.Fprintf(w, "%s<DL><p>\n", indent)
+
+	for _, child := range f.Folders {
+		fmt.Fprintf(w, "%s    <DT><H3%s%s>%s</H3>\n", indent, unixAttrString("ADD_DATE", child.AddDate), unixAttrString("LAST_MODIFIED", child.LastModified), html.EscapeString(child.Name))
+
+		writeNetscapeFolder(w, child, depth+1)
+	}
+
+	for _, bookmark := range f.Bookmarks {
+		fmt.Fprintf(w, "%s    <DT><A HREF=\"%s\"%s>%s</A>\n", indent, html.EscapeString(bookmark.URL), unixAttrString("ADD_DATE", bookmark.AddDate), html.EscapeString(bookmark.Title))
+
+		if bookmark.Notes != "" {
+			fmt.Fprintf(w, "%s    <DD>%s\n", indent, html.EscapeString(bookmark.Notes))
+		}
+	}
+
+	fmt.Fprintf(w, "%s</DL><p>\n", indent)
+}
+
+func unixAttrString(name string, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprintf(` %s="%d"`, name, t.Unix())
+}
@@ -0,0 +1,187 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chromeExport is laid out the way browsers export bookmarks, <DT> and <p> are never closed
+const chromeExport = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1680000000" LAST_MODIFIED="1680000100" PERSONAL_TOOLBAR_FOLDER="true">Bookmarks bar</H3>
+    <DL><p>
+        <DT><A HREF="https://go.dev/" ADD_DATE="1680000000123" ICON="data:image/png;base64,iVBORw0KGgo=">The Go Programming Language</A>
+        <DT><H3 ADD_DATE="1680000000">Reading &amp; Writing</H3>
+        <DL><p>
+            <DT><A HREF="https://example.com/?a=1&amp;b=2" ADD_DATE="1680000000123456">Tom &amp; Jerry&#39;s &lt;guide&gt;</A>
+            <DD>Read this
+later, twice
+            <DT><A HREF="https://example.org/">Example</A>
+        </DL><p>
+    </DL><p>
+    <DT><A HREF=" https://top.example/ " ADD_DATE="1680000000">Top level</A>
+    <DD>Top &amp; notes
+</DL><p>
+`
+
+func TestParseNetscapeBookmarks(t *testing.T) {
+	added := time.Date(2023, 3, 28, 10, 40, 0, 0, time.UTC)
+
+	want := &NetscapeFolder{
+		Folders: []*NetscapeFolder{
+			{
+				Name:         "Bookmarks bar",
+				AddDate:      added,
+				LastModified: added.Add(100 * time.Second),
+				Folders: []*NetscapeFolder{
+					{
+						Name:    "Reading & Writing",
+						AddDate: added,
+						Bookmarks: []*NetscapeBookmark{
+							{Title: "Tom & Jerry's <guide>", URL: "https://example.com/?a=1&b=2", Notes: "Read this\nlater, twice", AddDate: added},
+							{Title: "Example", URL: "https://example.org/"},
+						},
+					},
+				},
+				Bookmarks: []*NetscapeBookmark{
+					{Title: "The Go Programming Language", URL: "https://go.dev/", AddDate: added},
+				},
+			},
+		},
+		Bookmarks: []*NetscapeBookmark{
+			{Title: "Top level", URL: "https://top.example/", Notes: "Top & notes", AddDate: added},
+		},
+	}
+
+	got, err := ParseNetscapeBookmarks(strings.NewReader(chromeExport))
+	if err != nil {
+		t.Fatalf("ParseNetscapeBookmarks() = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseNetscapeBookmarks() =\n%s\nwant\n%s", dumpFolder(got), dumpFolder(want))
+	}
+}
+
+func TestParseNetscapeBookmarksNotes(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{"note ends at the next bookmark", `<DL><p><DT><A HREF="https://a.example/">A</A><DD>note a<DT><A HREF="https://b.example/">B</A></DL>`, []string{"note a", ""}},
+		{"note ends at the closing list", `<DL><p><DT><A HREF="https://a.example/">A</A><DD>note a</DL><p>`, []string{"note a"}},
+		{"note before a subfolder", `<DL><p><DT><A HREF="https://a.example/">A</A><DD>note a<DT><H3>F</H3><DL><p></DL><p></DL>`, []string{"note a"}},
+		{"dd without a bookmark is ignored", `<DL><p><DD>stray<DT><A HREF="https://a.example/">A</A></DL>`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ParseNetscapeBookmarks(strings.NewReader(tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var notes []string
+
+			for _, b := range root.Bookmarks {
+				notes = append(notes, b.Notes)
+			}
+
+			if !reflect.DeepEqual(notes, tt.want) {
+				t.Errorf("notes = %q, want %q", notes, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNetscapeBookmarksEmpty(t *testing.T) {
+	for _, file := range []string{"", "<!DOCTYPE NETSCAPE-Bookmark-file-1>\n<H1>Bookmarks</H1>\n<DL><p>\n</DL><p>\n", "not bookmarks at all"} {
+		if _, err := ParseNetscapeBookmarks(strings.NewReader(file)); !errors.Is(err, ErrNoBookmarksFound) {
+			t.Errorf("ParseNetscapeBookmarks(%q) = %v, want %v", file, err, ErrNoBookmarksFound)
+		}
+	}
+}
+
+func TestUnixAttr(t *testing.T) {
+	added := time.Date(2023, 3, 28, 10, 40, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{"seconds", "1680000000", added},
+		{"milliseconds", "1680000000123", added},
+		{"microseconds", "1680000000123456", added},
+		{"seconds far in the future", "4102444800", time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"milliseconds of 2010", "1262304000000", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"empty", "", time.Time{}},
+		{"zero", "0", time.Time{}},
+		{"negative", "-1", time.Time{}},
+		{"not a number", "yesterday", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unixAttr(tt.value); !got.Equal(tt.want) {
+				t.Errorf("unixAttr(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteNetscapeBookmarksRoundTrip(t *testing.T) {
+	parsed, err := ParseNetscapeBookmarks(strings.NewReader(chromeExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+
+	if err := WriteNetscapeBookmarks(&b, parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseNetscapeBookmarks(&b)
+	if err != nil {
+		t.Fatalf("ParseNetscapeBookmarks() of the export = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, parsed) {
+		t.Errorf("round trip =\n%s\nwant\n%s", dumpFolder(got), dumpFolder(parsed))
+	}
+}
+
+// dumpFolder prints a folder tree readably for test failures
+func dumpFolder(f *NetscapeFolder) string {
+	var b strings.Builder
+
+	var dump func(f *NetscapeFolder, indent string)
+
+	dump = func(f *NetscapeFolder, indent string) {
+		b.WriteString(indent + "folder " + strings.TrimSpace(f.Name) + " " + f.AddDate.String() + " " + f.LastModified.String() + "\n")
+
+		for _, child := range f.Folders {
+			dump(child, indent+"  ")
+		}
+
+		for _, bookmark := range f.Bookmarks {
+			b.WriteString(indent + "  bookmark " + bookmark.Title + " " + bookmark.URL + " " + bookmark.AddDate.String() + " notes " + bookmark.Notes + "\n")
+		}
+	}
+
+	dump(f, "")
+
+	return b.String()
+}
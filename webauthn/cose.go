@@ -0,0 +1,123 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// COSE key parameters (RFC 8152) of the algorithms we ask authenticators for
+const (
+	coseKeyType   = 1
+	coseAlgorithm = 3
+	// the meaning of the negative labels depends on the key type
+	coseCurveOrModulus  = -1
+	coseXOrExponent     = -2
+	coseY               = -3
+	coseKeyTypeOKP      = 1
+	coseKeyTypeEC2      = 2
+	coseKeyTypeRSA      = 3
+	coseCurveP256       = 1
+	coseCurveEd25519    = 6
+	algorithmES256      = -7
+	algorithmEdDSA      = -8
+	algorithmRS256      = -257
+	minRSAModulusLength = 2048
+)
+
+var errUnsupportedKey = errors.New("unsupported credential public key")
+
+// publicKey is a credential public key decoded from its COSE encoding
+type publicKey struct {
+	algorithm int64
+	key       crypto.PublicKey
+}
+
+func parsePublicKey(cose []byte) (*publicKey, error) {
+	var params map[int64]cbor.RawMessage
+
+	if err := cbor.Unmarshal(cose, &params); err != nil {
+		return nil, errUnsupportedKey
+	}
+
+	var kty, alg int64
+
+	if err := cbor.Unmarshal(params[coseKeyType], &kty); err != nil {
+		return nil, errUnsupportedKey
+	}
+
+	if err := cbor.Unmarshal(params[coseAlgorithm], &alg); err != nil {
+		return nil, errUnsupportedKey
+	}
+
+	switch {
+	case kty == coseKeyTypeEC2 && alg == algorithmES256:
+		var crv int64
+		var x, y []byte
+
+		if cbor.Unmarshal(params[coseCurveOrModulus], &crv) != nil || crv != coseCurveP256 {
+			return nil, errUnsupportedKey
+		}
+
+		if cbor.Unmarshal(params[coseXOrExponent], &x) != nil || cbor.Unmarshal(params[coseY], &y) != nil {
+			return nil, errUnsupportedKey
+		}
+
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errUnsupportedKey
+		}
+
+		return &publicKey{algorithm: alg, key: key}, nil
+	case kty == coseKeyTypeRSA && alg == algorithmRS256:
+		var n, e []byte
+
+		if cbor.Unmarshal(params[coseCurveOrModulus], &n) != nil || cbor.Unmarshal(params[coseXOrExponent], &e) != nil {
+			return nil, errUnsupportedKey
+		}
+
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if key.N.BitLen() < minRSAModulusLength || key.E < 3 {
+			return nil, errUnsupportedKey
+		}
+
+		return &publicKey{algorithm: alg, key: key}, nil
+	case kty == coseKeyTypeOKP && alg == algorithmEdDSA:
+		var crv int64
+		var x []byte
+
+		if cbor.Unmarshal(params[coseCurveOrModulus], &crv) != nil || crv != coseCurveEd25519 {
+			return nil, errUnsupportedKey
+		}
+
+		if cbor.Unmarshal(params[coseXOrExponent], &x) != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errUnsupportedKey
+		}
+
+		return &publicKey{algorithm: alg, key: ed25519.PublicKey(x)}, nil
+	}
+
+	return nil, errUnsupportedKey
+}
+
+func (p *publicKey) verify(data, signature []byte) bool {
+	switch key := p.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, signature)
+	}
+
+	return false
+}
@@ -0,0 +1,135 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+func coseKey(t *testing.T, params map[int64]interface{}) []byte {
+	t.Helper()
+
+	b, err := cbor.Marshal(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestParsePublicKey(t *testing.T) {
+	data := []byte("authenticator data and client data hash")
+	digest := sha256.Sum256(data)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecSignature, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	smallRSAKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := big.NewInt(int64(rsaKey.E)).Bytes()
+
+	valid := []struct {
+		name      string
+		key       []byte
+		signature []byte
+	}{
+		{"ES256", coseKey(t, map[int64]interface{}{
+			coseKeyType: coseKeyTypeEC2, coseAlgorithm: algorithmES256, coseCurveOrModulus: coseCurveP256,
+			coseXOrExponent: ecKey.X.FillBytes(make([]byte, 32)), coseY: ecKey.Y.FillBytes(make([]byte, 32)),
+		}), ecSignature},
+		{"EdDSA", coseKey(t, map[int64]interface{}{
+			coseKeyType: coseKeyTypeOKP, coseAlgorithm: algorithmEdDSA, coseCurveOrModulus: coseCurveEd25519,
+			coseXOrExponent: []byte(edPublic),
+		}), ed25519.Sign(edPrivate, data)},
+		{"RS256", coseKey(t, map[int64]interface{}{
+			coseKeyType: coseKeyTypeRSA, coseAlgorithm: algorithmRS256, coseCurveOrModulus: rsaKey.N.Bytes(), coseXOrExponent: e,
+		}), rsaSignature},
+	}
+
+	for _, tt := range valid {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := parsePublicKey(tt.key)
+			if err != nil {
+				t.Fatalf("parsePublicKey() = %v, want nil", err)
+			}
+
+			if !key.verify(data, tt.signature) {
+				t.Errorf("verify() = false, want true")
+			}
+
+			if key.verify([]byte("something else"), tt.signature) {
+				t.Errorf("verify() of other data = true, want false")
+			}
+		})
+	}
+
+	invalid := []struct {
+		name string
+		key  []byte
+	}{
+		{"not cbor", []byte("not cbor")},
+		{"ES256 point not on the curve", coseKey(t, map[int64]interface{}{
+			coseKeyType: coseKeyTypeEC2, coseAlgorithm: algorithmES256, coseCurveOrModulus: coseCurveP256,
+			coseXOrExponent: make([]byte, 32), coseY: []byte{1},
+		})},
+		{"ES256 on another curve", coseKey(t, map[int64]interface{}{
+			coseKeyType: coseKeyTypeEC2, coseAlgorithm: algorithmES256, coseCurveOrModulus: 2,
+			coseXOrExponent: ecKey.X.Bytes(), coseY: ecKey.Y.Bytes(),
+		})},
+		{"EdDSA key too short", coseKey(t, map[int64]interface{}{
+			coseKeyType: coseKeyTypeOKP, coseAlgorithm: algorithmEdDSA, coseCurveOrModulus: coseCurveEd25519,
+			coseXOrExponent: []byte(edPublic)[:16],
+		})},
+		{"RS256 modulus too short", coseKey(t, map[int64]interface{}{
+			coseKeyType: coseKeyTypeRSA, coseAlgorithm: algorithmRS256, coseCurveOrModulus: smallRSAKey.N.Bytes(), coseXOrExponent: e,
+		})},
+		{"algorithm not asked for", coseKey(t, map[int64]interface{}{
+			coseKeyType: coseKeyTypeEC2, coseAlgorithm: -35, coseCurveOrModulus: coseCurveP256,
+			coseXOrExponent: ecKey.X.Bytes(), coseY: ecKey.Y.Bytes(),
+		})},
+		{"algorithm of another key type", coseKey(t, map[int64]interface{}{
+			coseKeyType: coseKeyTypeRSA, coseAlgorithm: algorithmES256, coseCurveOrModulus: rsaKey.N.Bytes(), coseXOrExponent: e,
+		})},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parsePublicKey(tt.key); err != errUnsupportedKey {
+				t.Errorf("parsePublicKey() = %v, want %v", err, errUnsupportedKey)
+			}
+		})
+	}
+}
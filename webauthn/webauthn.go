@@ -0,0 +1,417 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/kwandapchumba/go-bookmark-manager/util"
+)
+
+// CeremonyTimeout is how long the browser and the stored challenge stay valid for one ceremony
+const CeremonyTimeout = 5 * time.Minute
+
+const (
+	flagUserPresent       = 0x01
+	flagUserVerified      = 0x04
+	flagAttestedData      = 0x40
+	authenticatorDataSize = 37
+)
+
+var (
+	ErrInvalidResponse = errors.New("invalid passkey response")
+	// ErrSignCount means the authenticator's counter went backwards, which is a sign the credential was cloned
+	ErrSignCount = errors.New("passkey signature counter went backwards")
+)
+
+var encoding = base64.RawURLEncoding
+
+// RelyingParty is this app as WebAuthn sees it. Passkeys are scoped to the ID, a domain the frontend origin is on.
+type RelyingParty struct {
+	ID     string
+	Name   string
+	Origin string
+}
+
+func NewRelyingParty() *RelyingParty {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Panicf("could not load config file: %v", err)
+	}
+
+	origin := "http://localhost:5173"
+	if config.FrontendURL != "" {
+		origin = strings.TrimSuffix(config.FrontendURL, "/")
+	}
+
+	id := config.WebAuthnRPID
+	if id == "" {
+		u, err := url.Parse(origin)
+		if err != nil {
+			log.Panicf("could not parse frontend url: %v", err)
+		}
+
+		id = u.Hostname()
+	}
+
+	return &RelyingParty{ID: id, Name: "Linkspace", Origin: origin}
+}
+
+// NewChallenge returns the random challenge for one ceremony, base64url encoded like every other binary value the
+// frontend sends or receives
+func NewChallenge() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(b), nil
+}
+
+// UserHandle is the user id passkeys are registered under, assertions hand it back
+func UserHandle(accountID int64) string {
+	return encoding.EncodeToString([]byte(strconv.FormatInt(accountID, 10)))
+}
+
+// AccountID reverses UserHandle
+func AccountID(userHandle string) (int64, error) {
+	b, err := encoding.DecodeString(userHandle)
+	if err != nil {
+		return 0, ErrInvalidResponse
+	}
+
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidResponse
+	}
+
+	return id, nil
+}
+
+type entity struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+type credentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type authenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions is the publicKey argument of navigator.credentials.create in its JSON form
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     entity                 `json:"rp"`
+	User                   entity                 `json:"user"`
+	PubKeyCredParams       []credentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection authenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions is the publicKey argument of navigator.credentials.get in its JSON form. Allowed credentials are
+// left out so the browser offers every passkey it has for us and the user does not have to type an email first.
+type RequestOptions struct {
+	Challenge        string `json:"challenge"`
+	RPID             string `json:"rpId"`
+	Timeout          int64  `json:"timeout"`
+	UserVerification string `json:"userVerification"`
+}
+
+func (rp *RelyingParty) CreationOptions(challenge string, accountID int64, email, name string, exclude []string) CreationOptions {
+	descriptors := []CredentialDescriptor{}
+
+	for _, id := range exclude {
+		descriptors = append(descriptors, CredentialDescriptor{Type: "public-key", ID: id})
+	}
+
+	return CreationOptions{
+		Challenge: challenge,
+		RP:        entity{ID: rp.ID, Name: rp.Name},
+		User:      entity{ID: UserHandle(accountID), Name: email, DisplayName: name},
+		PubKeyCredParams: []credentialParameter{
+			{Type: "public-key", Alg: algorithmES256},
+			{Type: "public-key", Alg: algorithmEdDSA},
+			{Type: "public-key", Alg: algorithmRS256},
+		},
+		Timeout:            CeremonyTimeout.Milliseconds(),
+		ExcludeCredentials: descriptors,
+		AuthenticatorSelection: authenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "required",
+		},
+		Attestation: "none",
+	}
+}
+
+func (rp *RelyingParty) RequestOptions(challenge string) RequestOptions {
+	return RequestOptions{
+		Challenge:        challenge,
+		RPID:             rp.ID,
+		Timeout:          CeremonyTimeout.Milliseconds(),
+		UserVerification: "required",
+	}
+}
+
+// Credential is a registered passkey
+type Credential struct {
+	ID        string
+	PublicKey []byte
+	SignCount uint32
+}
+
+// RegistrationResponse is the PublicKeyCredential navigator.credentials.create resolves with, as produced by its
+// toJSON method
+type RegistrationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AttestationObject string `json:"attestationObject"`
+	} `json:"response"`
+}
+
+// AssertionResponse is the PublicKeyCredential navigator.credentials.get resolves with, as produced by its toJSON
+// method
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	raw       []byte
+	flags     byte
+	signCount uint32
+	// only set when registering
+	credentialID []byte
+	publicKey    []byte
+}
+
+type attestationObject struct {
+	Fmt      string          `cbor:"fmt"`
+	AttStmt  cbor.RawMessage `cbor:"attStmt"`
+	AuthData []byte          `cbor:"authData"`
+}
+
+// Challenge reads the challenge a response was signed for so the stored ceremony can be looked up. It is checked again
+// when the response is verified.
+func Challenge(clientDataJSON string) (string, error) {
+	raw, err := encoding.DecodeString(clientDataJSON)
+	if err != nil {
+		return "", ErrInvalidResponse
+	}
+
+	var data clientData
+
+	if err := json.Unmarshal(raw, &data); err != nil || data.Challenge == "" {
+		return "", ErrInvalidResponse
+	}
+
+	return data.Challenge, nil
+}
+
+// verifyClientData checks the browser signed off on this ceremony, for our origin and with our challenge
+func (rp *RelyingParty) verifyClientData(encoded, ceremony, challenge string) ([]byte, error) {
+	raw, err := encoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	var data clientData
+
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	if data.Type != ceremony || data.Origin != rp.Origin {
+		return nil, ErrInvalidResponse
+	}
+
+	if subtle.ConstantTimeCompare([]byte(data.Challenge), []byte(challenge)) != 1 {
+		return nil, ErrInvalidResponse
+	}
+
+	return raw, nil
+}
+
+func (rp *RelyingParty) parseAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < authenticatorDataSize {
+		return nil, ErrInvalidResponse
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(raw[:32], rpIDHash[:]) != 1 {
+		return nil, ErrInvalidResponse
+	}
+
+	data := &authenticatorData{
+		raw:       raw,
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+
+	// passkeys stand in for a password and a second factor, so the user has to have been verified as well as present
+	if data.flags&flagUserPresent == 0 || data.flags&flagUserVerified == 0 {
+		return nil, ErrInvalidResponse
+	}
+
+	if data.flags&flagAttestedData == 0 {
+		return data, nil
+	}
+
+	// 16 byte authenticator model id, then the credential id length, the credential id and its COSE public key
+	rest := raw[authenticatorDataSize:]
+	if len(rest) < 18 {
+		return nil, ErrInvalidResponse
+	}
+
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+
+	if len(rest) < idLength {
+		return nil, ErrInvalidResponse
+	}
+
+	data.credentialID = rest[:idLength]
+
+	decoder := cbor.NewDecoder(bytes.NewReader(rest[idLength:]))
+
+	var key cbor.RawMessage
+
+	if err := decoder.Decode(&key); err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	data.publicKey = rest[idLength : idLength+decoder.NumBytesRead()]
+
+	return data, nil
+}
+
+// VerifyRegistration checks a new passkey and returns it to be stored. We ask for no attestation, so the attestation
+// statement is not checked and the key is trusted because the signed in user registered it.
+func (rp *RelyingParty) VerifyRegistration(res RegistrationResponse, challenge string) (*Credential, error) {
+	if res.Type != "public-key" {
+		return nil, ErrInvalidResponse
+	}
+
+	if _, err := rp.verifyClientData(res.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	raw, err := encoding.DecodeString(res.Response.AttestationObject)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	var attestation attestationObject
+
+	if err := cbor.Unmarshal(raw, &attestation); err != nil {
+		return nil, ErrInvalidResponse
+	}
+
+	data, err := rp.parseAuthenticatorData(attestation.AuthData)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.credentialID == nil || encoding.EncodeToString(data.credentialID) != res.RawID {
+		return nil, ErrInvalidResponse
+	}
+
+	if _, err := parsePublicKey(data.publicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:        res.RawID,
+		PublicKey: data.publicKey,
+		SignCount: data.signCount,
+	}, nil
+}
+
+// VerifyAssertion checks a sign in with a stored passkey and returns the authenticator's new signature counter
+func (rp *RelyingParty) VerifyAssertion(res AssertionResponse, challenge string, credential Credential) (uint32, error) {
+	if res.Type != "public-key" || res.RawID != credential.ID {
+		return 0, ErrInvalidResponse
+	}
+
+	clientDataJSON, err := rp.verifyClientData(res.Response.ClientDataJSON, "webauthn.get", challenge)
+	if err != nil {
+		return 0, err
+	}
+
+	raw, err := encoding.DecodeString(res.Response.AuthenticatorData)
+	if err != nil {
+		return 0, ErrInvalidResponse
+	}
+
+	data, err := rp.parseAuthenticatorData(raw)
+	if err != nil {
+		return 0, err
+	}
+
+	signature, err := encoding.DecodeString(res.Response.Signature)
+	if err != nil {
+		return 0, ErrInvalidResponse
+	}
+
+	key, err := parsePublicKey(credential.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+
+	signed := append(append([]byte{}, data.raw...), clientDataHash[:]...)
+
+	if !key.verify(signed, signature) {
+		return 0, ErrInvalidResponse
+	}
+
+	// synced passkeys always report 0, only a counter that is in use has to keep going up
+	if (data.signCount != 0 || credential.SignCount != 0) && data.signCount <= credential.SignCount {
+		return 0, ErrSignCount
+	}
+
+	return data.signCount, nil
+}
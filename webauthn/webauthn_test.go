@@ -0,0 +1,344 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+var testRP = &RelyingParty{ID: "example.com", Name: "Linkspace", Origin: "https://app.example.com"}
+
+const testChallenge = "dGhlIGNoYWxsZW5nZSBvZiB0aGlzIGNlcmVtb255"
+
+// ceremony is what a test authenticator puts in a response, valid for testRP unless changed
+type ceremony struct {
+	typ       string
+	challenge string
+	origin    string
+	rpID      string
+	flags     byte
+	signCount uint32
+}
+
+func validCeremony(typ string) ceremony {
+	return ceremony{
+		typ:       typ,
+		challenge: testChallenge,
+		origin:    testRP.Origin,
+		rpID:      testRP.ID,
+		flags:     flagUserPresent | flagUserVerified,
+	}
+}
+
+// testAuthenticator stands in for a platform authenticator with one ES256 passkey
+type testAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := make([]byte, 16)
+
+	if _, err := rand.Read(id); err != nil {
+		t.Fatal(err)
+	}
+
+	return &testAuthenticator{key: key, credentialID: id}
+}
+
+func (a *testAuthenticator) cosePublicKey(t *testing.T) []byte {
+	t.Helper()
+
+	b, err := cbor.Marshal(map[int64]interface{}{
+		coseKeyType:        coseKeyTypeEC2,
+		coseAlgorithm:      algorithmES256,
+		coseCurveOrModulus: coseCurveP256,
+		coseXOrExponent:    a.key.X.FillBytes(make([]byte, 32)),
+		coseY:              a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func (a *testAuthenticator) credential(t *testing.T, signCount uint32) Credential {
+	return Credential{ID: encoding.EncodeToString(a.credentialID), PublicKey: a.cosePublicKey(t), SignCount: signCount}
+}
+
+func clientDataJSON(t *testing.T, c ceremony) []byte {
+	t.Helper()
+
+	b, err := json.Marshal(map[string]interface{}{
+		"type":        c.typ,
+		"challenge":   c.challenge,
+		"origin":      c.origin,
+		"crossOrigin": false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+// authenticatorData is the rp id hash, flags and counter, followed by the credential when attested is set
+func (a *testAuthenticator) authenticatorData(t *testing.T, c ceremony, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(c.rpID))
+
+	data := append([]byte{}, rpIDHash[:]...)
+
+	flags := c.flags
+	if attested {
+		flags |= flagAttestedData
+	}
+
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, c.signCount)
+
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.cosePublicKey(t)...)
+	}
+
+	return data
+}
+
+func (a *testAuthenticator) register(t *testing.T, c ceremony) RegistrationResponse {
+	t.Helper()
+
+	attestation, err := cbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authenticatorData(t, c, true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res RegistrationResponse
+
+	res.ID = encoding.EncodeToString(a.credentialID)
+	res.RawID = res.ID
+	res.Type = "public-key"
+	res.Response.ClientDataJSON = encoding.EncodeToString(clientDataJSON(t, c))
+	res.Response.AttestationObject = encoding.EncodeToString(attestation)
+
+	return res
+}
+
+// assert signs the authenticator data and the hash of the client data with signer, the passkey's own key when nil
+func (a *testAuthenticator) assert(t *testing.T, c ceremony, signer *ecdsa.PrivateKey) AssertionResponse {
+	t.Helper()
+
+	if signer == nil {
+		signer = a.key
+	}
+
+	clientData := clientDataJSON(t, c)
+	authData := a.authenticatorData(t, c, false)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, signer, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res AssertionResponse
+
+	res.ID = encoding.EncodeToString(a.credentialID)
+	res.RawID = res.ID
+	res.Type = "public-key"
+	res.Response.ClientDataJSON = encoding.EncodeToString(clientData)
+	res.Response.AuthenticatorData = encoding.EncodeToString(authData)
+	res.Response.Signature = encoding.EncodeToString(signature)
+	res.Response.UserHandle = UserHandle(42)
+
+	return res
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	a := newTestAuthenticator(t)
+
+	t.Run("valid", func(t *testing.T) {
+		c := validCeremony("webauthn.create")
+		c.signCount = 3
+
+		credential, err := testRP.VerifyRegistration(a.register(t, c), testChallenge)
+		if err != nil {
+			t.Fatalf("VerifyRegistration() = %v, want nil", err)
+		}
+
+		want := a.credential(t, 3)
+
+		if credential.ID != want.ID || string(credential.PublicKey) != string(want.PublicKey) || credential.SignCount != want.SignCount {
+			t.Errorf("VerifyRegistration() = %+v, want %+v", credential, want)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		change func(c *ceremony)
+		res    func(res *RegistrationResponse)
+	}{
+		{name: "wrong origin", change: func(c *ceremony) { c.origin = "https://evil.example.net" }},
+		{name: "wrong challenge", change: func(c *ceremony) { c.challenge = "YW5vdGhlciBjaGFsbGVuZ2U" }},
+		{name: "wrong ceremony type", change: func(c *ceremony) { c.typ = "webauthn.get" }},
+		{name: "rp id hash mismatch", change: func(c *ceremony) { c.rpID = "evil.example.net" }},
+		{name: "user not present", change: func(c *ceremony) { c.flags = flagUserVerified }},
+		{name: "user not verified", change: func(c *ceremony) { c.flags = flagUserPresent }},
+		{name: "not a public key credential", res: func(res *RegistrationResponse) { res.Type = "password" }},
+		{name: "raw id is not the credential id", res: func(res *RegistrationResponse) { res.RawID = encoding.EncodeToString([]byte("another credential")) }},
+		{name: "attestation object is not cbor", res: func(res *RegistrationResponse) {
+			res.Response.AttestationObject = encoding.EncodeToString([]byte("{}"))
+		}},
+		{name: "client data is not json", res: func(res *RegistrationResponse) {
+			res.Response.ClientDataJSON = encoding.EncodeToString([]byte("<xml/>"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validCeremony("webauthn.create")
+
+			if tt.change != nil {
+				tt.change(&c)
+			}
+
+			res := a.register(t, c)
+
+			if tt.res != nil {
+				tt.res(&res)
+			}
+
+			if _, err := testRP.VerifyRegistration(res, testChallenge); !errors.Is(err, ErrInvalidResponse) {
+				t.Errorf("VerifyRegistration() = %v, want %v", err, ErrInvalidResponse)
+			}
+		})
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	a := newTestAuthenticator(t)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := []struct {
+		name          string
+		signCount     uint32
+		storedCount   uint32
+		wantSignCount uint32
+	}{
+		{"counter goes up", 5, 4, 5},
+		{"first use of a counter", 1, 0, 1},
+		{"synced passkey without a counter", 0, 0, 0},
+	}
+
+	for _, tt := range valid {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validCeremony("webauthn.get")
+			c.signCount = tt.signCount
+
+			got, err := testRP.VerifyAssertion(a.assert(t, c, nil), testChallenge, a.credential(t, tt.storedCount))
+			if err != nil {
+				t.Fatalf("VerifyAssertion() = %v, want nil", err)
+			}
+
+			if got != tt.wantSignCount {
+				t.Errorf("VerifyAssertion() = %d, want %d", got, tt.wantSignCount)
+			}
+		})
+	}
+
+	tests := []struct {
+		name    string
+		change  func(c *ceremony)
+		signer  *ecdsa.PrivateKey
+		res     func(res *AssertionResponse)
+		wantErr error
+	}{
+		{name: "wrong origin", change: func(c *ceremony) { c.origin = "https://evil.example.net" }, wantErr: ErrInvalidResponse},
+		{name: "wrong challenge", change: func(c *ceremony) { c.challenge = "YW5vdGhlciBjaGFsbGVuZ2U" }, wantErr: ErrInvalidResponse},
+		{name: "wrong ceremony type", change: func(c *ceremony) { c.typ = "webauthn.create" }, wantErr: ErrInvalidResponse},
+		{name: "rp id hash mismatch", change: func(c *ceremony) { c.rpID = "evil.example.net" }, wantErr: ErrInvalidResponse},
+		{name: "user not present", change: func(c *ceremony) { c.flags = flagUserVerified }, wantErr: ErrInvalidResponse},
+		{name: "user not verified", change: func(c *ceremony) { c.flags = flagUserPresent }, wantErr: ErrInvalidResponse},
+		{name: "signed with another key", signer: otherKey, wantErr: ErrInvalidResponse},
+		{name: "client data changed after signing", res: func(res *AssertionResponse) {
+			b, _ := json.Marshal(map[string]string{"type": "webauthn.get", "challenge": testChallenge, "origin": testRP.Origin, "extra": "x"})
+			res.Response.ClientDataJSON = encoding.EncodeToString(b)
+		}, wantErr: ErrInvalidResponse},
+		{name: "signature is not base64", res: func(res *AssertionResponse) { res.Response.Signature = "!!!" }, wantErr: ErrInvalidResponse},
+		{name: "another credential", res: func(res *AssertionResponse) { res.RawID = encoding.EncodeToString([]byte("another credential")) }, wantErr: ErrInvalidResponse},
+		{name: "sign count goes backwards", change: func(c *ceremony) { c.signCount = 3 }, wantErr: ErrSignCount},
+		{name: "sign count stays the same", change: func(c *ceremony) { c.signCount = 4 }, wantErr: ErrSignCount},
+		{name: "sign count drops to zero", change: func(c *ceremony) { c.signCount = 0 }, wantErr: ErrSignCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validCeremony("webauthn.get")
+			c.signCount = 5
+
+			if tt.change != nil {
+				tt.change(&c)
+			}
+
+			res := a.assert(t, c, tt.signer)
+
+			if tt.res != nil {
+				tt.res(&res)
+			}
+
+			if _, err := testRP.VerifyAssertion(res, testChallenge, a.credential(t, 4)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyAssertion() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	got, err := Challenge(encoding.EncodeToString(clientDataJSON(t, validCeremony("webauthn.get"))))
+	if err != nil || got != testChallenge {
+		t.Errorf("Challenge() = %q, %v, want %q", got, err, testChallenge)
+	}
+
+	for _, bad := range []string{"!!!", encoding.EncodeToString([]byte("{}")), encoding.EncodeToString([]byte("null"))} {
+		if _, err := Challenge(bad); !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("Challenge(%q) = %v, want %v", bad, err, ErrInvalidResponse)
+		}
+	}
+}
+
+func TestUserHandle(t *testing.T) {
+	id, err := AccountID(UserHandle(1234))
+	if err != nil || id != 1234 {
+		t.Errorf("AccountID(UserHandle(1234)) = %d, %v", id, err)
+	}
+
+	if _, err := AccountID(encoding.EncodeToString([]byte("not a number"))); !errors.Is(err, ErrInvalidResponse) {
+		t.Errorf("AccountID() = %v, want %v", err, ErrInvalidResponse)
+	}
+}